@@ -0,0 +1,62 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog [sourceRepo] [targetRepo]",
+	Short: "show the changelog between two repositories",
+	Long:  "Collect the package history updates that are new in the source repository when compared to the target, rendered as Markdown",
+	Run:   getChangelog,
+	Args:  cobra.ExactArgs(2),
+}
+
+var changelogJSON bool
+
+func init() {
+	changelogCmd.Flags().BoolVarP(&changelogJSON, "json", "j", false, "Emit the changelog as JSON")
+	RootCmd.AddCommand(changelogCmd)
+}
+
+func getChangelog(_ *cobra.Command, args []string) {
+	client := newClient()
+	defer client.Close()
+
+	changelog, err := client.GetChangelog(args[0], args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while getting changelog: %v\n", err)
+		return
+	}
+
+	if !changelogJSON {
+		fmt.Print(changelog.Markdown)
+		return
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(changelog.Entries); err != nil {
+		fmt.Fprintf(os.Stderr, "Error while encoding changelog: %v\n", err)
+	}
+}
@@ -0,0 +1,173 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// CheckCmd will request an integrity check of the pool and repositories
+var CheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "check integrity of the pool and repositories",
+	Long:  "Schedule a verification of every pool entry and repository",
+	Run:   checkIntegrity,
+}
+
+var checkReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "show the last integrity report",
+	Long:  "Display the problems found by the most recent integrity check",
+	Run:   checkReport,
+}
+
+var checkRepairCmd = &cobra.Command{
+	Use:   "repair",
+	Short: "repair the pool and repositories",
+	Long:  "Schedule a repair of refcounts, missing hardlinks and orphaned pool files",
+	Run:   checkRepair,
+}
+
+var checkInstallableCmd = &cobra.Command{
+	Use:   "installable [repo]",
+	Short: "check a repository for unresolved dependencies",
+	Long:  "Schedule a check that every published package in the repository can be installed",
+	Run:   checkInstallable,
+	Args:  cobra.ExactArgs(1),
+}
+
+var checkInstallableReport bool
+
+func init() {
+	checkInstallableCmd.Flags().BoolVarP(&checkInstallableReport, "report", "r", false, "Show the last installability report instead")
+	CheckCmd.AddCommand(checkReportCmd)
+	CheckCmd.AddCommand(checkRepairCmd)
+	CheckCmd.AddCommand(checkInstallableCmd)
+	RootCmd.AddCommand(CheckCmd)
+	addWaitFlag(CheckCmd, checkRepairCmd, checkInstallableCmd)
+}
+
+func checkIntegrity(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		fmt.Fprintf(os.Stderr, "check takes no arguments\n")
+		return
+	}
+
+	client := newClient()
+	defer client.Close()
+
+	id, err := client.CheckIntegrity()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while checking integrity: %v\n", err)
+		os.Exit(1)
+	}
+	finishJob(client, id)
+}
+
+func checkRepair(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		fmt.Fprintf(os.Stderr, "check repair takes no arguments\n")
+		return
+	}
+
+	client := newClient()
+	defer client.Close()
+
+	id, err := client.RepairIntegrity()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while repairing integrity: %v\n", err)
+		os.Exit(1)
+	}
+	finishJob(client, id)
+}
+
+func checkReport(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		fmt.Fprintf(os.Stderr, "check report takes no arguments\n")
+		return
+	}
+
+	client := newClient()
+	defer client.Close()
+
+	report, err := client.GetIntegrityReport()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while getting integrity report: %v\n", err)
+		return
+	}
+
+	fmt.Printf(" - Checked: %v (took %v)\n", report.Finished.Format("2006-01-02 15:04:05"), report.Finished.Sub(report.Started))
+	fmt.Printf(" - Pool entries: %d\n", report.PoolEntries)
+	fmt.Printf(" - Repository entries: %d\n", report.RepoEntries)
+
+	if len(report.Issues) == 0 {
+		fmt.Printf("No problems found.\n")
+		return
+	}
+
+	fmt.Printf("Problems found: (%d)\n\n", len(report.Issues))
+	for _, issue := range report.Issues {
+		if issue.Repo != "" {
+			fmt.Printf(" - %s | %s | %s: %s\n", issue.Kind, issue.Repo, issue.ID, issue.Detail)
+		} else {
+			fmt.Printf(" - %s | %s: %s\n", issue.Kind, issue.ID, issue.Detail)
+		}
+	}
+
+	// Allow alerting on a corrupt instance
+	os.Exit(1)
+}
+
+func checkInstallable(_ *cobra.Command, args []string) {
+	client := newClient()
+	defer client.Close()
+
+	if !checkInstallableReport {
+		id, err := client.CheckInstallable(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error while checking installability: %v\n", err)
+			os.Exit(1)
+		}
+		finishJob(client, id)
+		return
+	}
+
+	report, err := client.GetInstallReport(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while getting installability report: %v\n", err)
+		return
+	}
+
+	fmt.Printf(" - Checked: %v (took %v)\n", report.Finished.Format("2006-01-02 15:04:05"), report.Finished.Sub(report.Started))
+	fmt.Printf(" - Packages: %d\n", report.Packages)
+
+	if len(report.Issues) == 0 {
+		fmt.Printf("No problems found.\n")
+		return
+	}
+
+	fmt.Printf("Problems found: (%d)\n\n", len(report.Issues))
+	for _, issue := range report.Issues {
+		fmt.Printf(" - %s | %s -> %s: %s\n", issue.Kind, issue.Package, issue.Dependency, issue.Detail)
+	}
+
+	// Allow alerting on a broken repository
+	os.Exit(1)
+}
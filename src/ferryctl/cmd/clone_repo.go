@@ -21,8 +21,6 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
-
-	"github.com/getsolus/ferryd/src/libferry"
 )
 
 var (
@@ -39,6 +37,7 @@ var cloneRepoCmd = &cobra.Command{
 func init() {
 	cloneRepoCmd.PersistentFlags().BoolVarP(&fullClone, "full", "f", false, "Perform a deep clone")
 	RootCmd.AddCommand(cloneRepoCmd)
+	addWaitFlag(cloneRepoCmd)
 }
 
 func cloneRepo(cmd *cobra.Command, args []string) {
@@ -47,11 +46,13 @@ func cloneRepo(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	client := libferry.NewClient(socketPath)
+	client := newClient()
 	defer client.Close()
 
-	if err := client.CloneRepo(args[0], args[1], fullClone); err != nil {
+	id, err := client.CloneRepo(args[0], args[1], fullClone)
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error while cloning repo: %v\n", err)
-		return
+		os.Exit(1)
 	}
+	finishJob(client, id)
 }
@@ -22,8 +22,6 @@ import (
 	"strconv"
 
 	"github.com/spf13/cobra"
-
-	"github.com/getsolus/ferryd/src/libferry"
 )
 
 var (
@@ -39,6 +37,8 @@ var copySourceCmd = &cobra.Command{
 
 func init() {
 	CopyCmd.AddCommand(copySourceCmd)
+	addWaitFlag(copySourceCmd)
+	copySourceCmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Show what would change without changing anything")
 	CopyCmd.PersistentFlags().BoolVarP(&skipIndex, "skip-index", "x", false, "Skip updating the index of the target")
 }
 
@@ -76,11 +76,23 @@ func copySource(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	client := libferry.NewClient(socketPath)
+	client := newClient()
 	defer client.Close()
 
-	if err := client.CopySource(repoID, targetID, sourceID, sourceRelease, skipIndex); err != nil {
-		fmt.Fprintf(os.Stderr, "Error while copying source: %v\n", err)
+	if dryRun {
+		plan, err := client.PlanCopySource(repoID, targetID, sourceID, sourceRelease)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error while planning source copy: %v\n", err)
+			return
+		}
+		printPlan(plan)
 		return
 	}
+
+	id, err := client.CopySource(repoID, targetID, sourceID, sourceRelease, skipIndex)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while copying source: %v\n", err)
+		os.Exit(1)
+	}
+	finishJob(client, id)
 }
@@ -21,8 +21,6 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
-
-	"github.com/getsolus/ferryd/src/libferry"
 )
 
 var createRepoCmd = &cobra.Command{
@@ -34,6 +32,7 @@ var createRepoCmd = &cobra.Command{
 
 func init() {
 	RootCmd.AddCommand(createRepoCmd)
+	addWaitFlag(createRepoCmd)
 }
 
 func createRepo(cmd *cobra.Command, args []string) {
@@ -42,11 +41,13 @@ func createRepo(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	client := libferry.NewClient(socketPath)
+	client := newClient()
 	defer client.Close()
 
-	if err := client.CreateRepo(args[0]); err != nil {
+	id, err := client.CreateRepo(args[0])
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error while creating repo: %v\n", err)
-		return
+		os.Exit(1)
 	}
+	finishJob(client, id)
 }
@@ -21,8 +21,6 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
-
-	"github.com/getsolus/ferryd/src/libferry"
 )
 
 var deltaCmd = &cobra.Command{
@@ -34,6 +32,7 @@ var deltaCmd = &cobra.Command{
 
 func init() {
 	RootCmd.AddCommand(deltaCmd)
+	addWaitFlag(deltaCmd)
 }
 
 func delta(cmd *cobra.Command, args []string) {
@@ -42,11 +41,13 @@ func delta(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	client := libferry.NewClient(socketPath)
+	client := newClient()
 	defer client.Close()
 
-	if err := client.DeltaRepo(args[0]); err != nil {
+	id, err := client.DeltaRepo(args[0])
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error while creating deltas: %v\n", err)
-		return
+		os.Exit(1)
 	}
+	finishJob(client, id)
 }
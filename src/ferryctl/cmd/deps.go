@@ -0,0 +1,106 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/getsolus/ferryd/src/libferry"
+)
+
+var rdepsCmd = &cobra.Command{
+	Use:   "rdeps [repoName] [packageName]",
+	Short: "show reverse dependencies",
+	Long:  "List the published packages in a repository that depend on the named package",
+	Run:   getReverseDeps,
+	Args:  cobra.ExactArgs(2),
+}
+
+var graphCmd = &cobra.Command{
+	Use:   "graph [repoName]",
+	Short: "export the dependency graph",
+	Long:  "Export the runtime dependency graph of the published packages in a repository",
+	Run:   getDependencyGraph,
+	Args:  cobra.ExactArgs(1),
+}
+
+var (
+	rdepsTransitive bool
+	graphDOT        bool
+)
+
+func init() {
+	rdepsCmd.Flags().BoolVarP(&rdepsTransitive, "all", "a", false, "Include packages that depend on it indirectly")
+	graphCmd.Flags().BoolVarP(&graphDOT, "dot", "d", false, "Emit the graph in Graphviz DOT format instead of JSON")
+	RootCmd.AddCommand(rdepsCmd)
+	RootCmd.AddCommand(graphCmd)
+}
+
+func getReverseDeps(_ *cobra.Command, args []string) {
+	client := newClient()
+	defer client.Close()
+
+	rdeps, err := client.GetReverseDeps(args[0], args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while getting reverse dependencies: %v\n", err)
+		return
+	}
+
+	names := rdeps.Direct
+	if rdepsTransitive {
+		names = rdeps.Transitive
+	}
+
+	if len(names) == 0 {
+		fmt.Printf("Nothing in '%s' depends on '%s'.\n", rdeps.Repo, rdeps.Name)
+		return
+	}
+
+	fmt.Printf("Packages in '%s' depending on '%s': (%d)\n\n", rdeps.Repo, rdeps.Name, len(names))
+	for _, name := range names {
+		fmt.Printf(" * %s\n", name)
+	}
+}
+
+func getDependencyGraph(_ *cobra.Command, args []string) {
+	client := newClient()
+	defer client.Close()
+
+	graph, err := client.GetDependencyGraph(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while getting dependency graph: %v\n", err)
+		return
+	}
+
+	if graphDOT {
+		fmt.Print(graph.DOT)
+		return
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(struct {
+		Nodes   []libferry.DependencyNode `json:"nodes"`
+		Missing []string                  `json:"missing"`
+	}{graph.Nodes, graph.Missing}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error while encoding graph: %v\n", err)
+	}
+}
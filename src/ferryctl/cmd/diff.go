@@ -0,0 +1,83 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/getsolus/ferryd/src/libferry"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [sourceRepo] [targetRepo]",
+	Short: "compare two repositories",
+	Long:  "Show which packages differ between the published sets of two repositories",
+	Run:   diffRepos,
+	Args:  cobra.ExactArgs(2),
+}
+
+func init() {
+	RootCmd.AddCommand(diffCmd)
+}
+
+// formatDiffPackage renders the version information for a diffed package
+func formatDiffPackage(pkg libferry.DiffPackage) string {
+	return fmt.Sprintf("%s-%d", pkg.Version, pkg.Release)
+}
+
+func diffRepos(_ *cobra.Command, args []string) {
+	client := newClient()
+	defer client.Close()
+
+	diff, err := client.DiffRepos(args[0], args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while comparing repos: %v\n", err)
+		return
+	}
+
+	if len(diff.OnlySource)+len(diff.OnlyTarget)+len(diff.Changed) == 0 {
+		fmt.Printf("No differences between '%s' and '%s'.\n", diff.Source, diff.Target)
+		return
+	}
+
+	if len(diff.Changed) > 0 {
+		fmt.Printf("Changed: (%d)\n\n", len(diff.Changed))
+		for _, change := range diff.Changed {
+			fmt.Printf(" * %s: %s -> %s\n", change.Name, formatDiffPackage(change.Target), formatDiffPackage(change.Source))
+		}
+		fmt.Println()
+	}
+
+	if len(diff.OnlySource) > 0 {
+		fmt.Printf("Only in '%s': (%d)\n\n", diff.Source, len(diff.OnlySource))
+		for _, pkg := range diff.OnlySource {
+			fmt.Printf(" + %s (%s)\n", pkg.Name, formatDiffPackage(pkg))
+		}
+		fmt.Println()
+	}
+
+	if len(diff.OnlyTarget) > 0 {
+		fmt.Printf("Only in '%s': (%d)\n\n", diff.Target, len(diff.OnlyTarget))
+		for _, pkg := range diff.OnlyTarget {
+			fmt.Printf(" - %s (%s)\n", pkg.Name, formatDiffPackage(pkg))
+		}
+		fmt.Println()
+	}
+}
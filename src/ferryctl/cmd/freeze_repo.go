@@ -21,8 +21,6 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
-
-	"github.com/getsolus/ferryd/src/libferry"
 )
 
 var (
@@ -44,24 +42,29 @@ var (
 
 func init() {
 	RootCmd.AddCommand(freezeRepoCmd, unfreezeRepoCmd)
+	addWaitFlag(freezeRepoCmd, unfreezeRepoCmd)
 }
 
 func freezeRepo(_ *cobra.Command, args []string) {
-	client := libferry.NewClient(socketPath)
+	client := newClient()
 	defer client.Close()
 
-	if err := client.FreezeRepo(args[0]); err != nil {
+	id, err := client.FreezeRepo(args[0])
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error while freezing repo: %v\n", err)
-		return
+		os.Exit(1)
 	}
+	finishJob(client, id)
 }
 
 func unfreezeRepo(_ *cobra.Command, args []string) {
-	client := libferry.NewClient(socketPath)
+	client := newClient()
 	defer client.Close()
 
-	if err := client.UnfreezeRepo(args[0]); err != nil {
+	id, err := client.UnfreezeRepo(args[0])
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error while unfreezing repo: %v\n", err)
-		return
+		os.Exit(1)
 	}
+	finishJob(client, id)
 }
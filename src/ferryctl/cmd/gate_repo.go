@@ -0,0 +1,70 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	gateRepoCmd = &cobra.Command{
+		Use:   "gate [repo]",
+		Short: "only index a repository when it is installable",
+		Long:  "Prevent a repository index being published while it has unresolved dependencies",
+		Run:   gateRepo,
+		Args:  cobra.ExactArgs(1),
+	}
+	ungateRepoCmd = &cobra.Command{
+		Use:   "ungate [repo]",
+		Short: "disable the installability gate of a repository",
+		Long:  "Allow a repository index to be published regardless of unresolved dependencies",
+		Run:   ungateRepo,
+		Args:  cobra.ExactArgs(1),
+	}
+)
+
+func init() {
+	RootCmd.AddCommand(gateRepoCmd, ungateRepoCmd)
+	addWaitFlag(gateRepoCmd, ungateRepoCmd)
+}
+
+func gateRepo(_ *cobra.Command, args []string) {
+	client := newClient()
+	defer client.Close()
+
+	id, err := client.EnableInstallGate(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while gating repo: %v\n", err)
+		os.Exit(1)
+	}
+	finishJob(client, id)
+}
+
+func ungateRepo(_ *cobra.Command, args []string) {
+	client := newClient()
+	defer client.Close()
+
+	id, err := client.DisableInstallGate(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while ungating repo: %v\n", err)
+		os.Exit(1)
+	}
+	finishJob(client, id)
+}
@@ -0,0 +1,109 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/getsolus/ferryd/src/libferry"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "query finished jobs",
+	Long:  "Find finished jobs by type, repository, state or when they finished, newest first",
+	Run:   getHistory,
+	Args:  cobra.NoArgs,
+}
+
+var (
+	historyQuery libferry.HistoryRequest
+	historySince string
+	historyUntil string
+)
+
+func init() {
+	historyCmd.Flags().StringVarP(&historyQuery.Type, "type", "t", "", "Match the job type")
+	historyCmd.Flags().StringVarP(&historyQuery.Repo, "repo", "r", "", "Match jobs working on the repository")
+	historyCmd.Flags().StringVarP(&historyQuery.State, "state", "s", "", "Match the state (completed, failed or cancelled)")
+	historyCmd.Flags().StringVar(&historySince, "since", "", "Match jobs finished at or after this date or time")
+	historyCmd.Flags().StringVar(&historyUntil, "until", "", "Match jobs finished before this date or time")
+	historyCmd.Flags().IntVarP(&historyQuery.Limit, "limit", "n", 20, "Show at most this many jobs (0 shows all)")
+	RootCmd.AddCommand(historyCmd)
+}
+
+// parseHistoryTime accepts either a local date or an RFC3339 timestamp
+func parseHistoryTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func getHistory(_ *cobra.Command, _ []string) {
+	var err error
+	if historyQuery.Since, err = parseHistoryTime(historySince); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid --since: %v\n", err)
+		return
+	}
+	if historyQuery.Until, err = parseHistoryTime(historyUntil); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid --until: %v\n", err)
+		return
+	}
+
+	client := newClient()
+	defer client.Close()
+
+	js, err := client.History(&historyQuery)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while querying history: %v\n", err)
+		return
+	}
+
+	if len(js) == 0 {
+		fmt.Println("No matching jobs.")
+		return
+	}
+
+	table := newTable([]string{
+		"ID",
+		"Type",
+		"State",
+		"Finished",
+		"Duration",
+		"Description",
+	})
+	for _, j := range js {
+		table.Append([]string{
+			strconv.FormatUint(j.ID, 10),
+			j.Type,
+			j.State,
+			j.Timing.End.Local().Format("2006-01-02 15:04:05"),
+			j.ExecutionTime().String(),
+			j.Description,
+		})
+	}
+	table.Render()
+}
@@ -23,8 +23,6 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
-
-	"github.com/getsolus/ferryd/src/libferry"
 )
 
 var importCmd = &cobra.Command{
@@ -36,6 +34,7 @@ var importCmd = &cobra.Command{
 
 func init() {
 	RootCmd.AddCommand(importCmd)
+	addWaitFlag(importCmd)
 }
 
 // GetEopkgs will utilize the provided path to get any eopkgs.
@@ -80,7 +79,7 @@ func importEx(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	client := libferry.NewClient(socketPath)
+	client := newClient()
 	defer client.Close()
 
 	repoID := args[0]
@@ -101,8 +100,10 @@ func importEx(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	if err := client.ImportPackages(repoID, packages); err != nil {
+	id, err := client.ImportPackages(repoID, packages)
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error while importing packages: %v\n", err)
-		return
+		os.Exit(1)
 	}
+	finishJob(client, id)
 }
@@ -21,8 +21,6 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
-
-	"github.com/getsolus/ferryd/src/libferry"
 )
 
 var indexCmd = &cobra.Command{
@@ -34,6 +32,7 @@ var indexCmd = &cobra.Command{
 
 func init() {
 	RootCmd.AddCommand(indexCmd)
+	addWaitFlag(indexCmd)
 }
 
 func index(cmd *cobra.Command, args []string) {
@@ -42,11 +41,13 @@ func index(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	client := libferry.NewClient(socketPath)
+	client := newClient()
 	defer client.Close()
 
-	if err := client.IndexRepo(args[0]); err != nil {
+	id, err := client.IndexRepo(args[0])
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error while indexing: %v\n", err)
-		return
+		os.Exit(1)
 	}
+	finishJob(client, id)
 }
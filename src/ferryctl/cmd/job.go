@@ -0,0 +1,246 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/getsolus/ferryd/src/libferry"
+)
+
+var jobCmd = &cobra.Command{
+	Use:   "job [id]",
+	Short: "show a job",
+	Long:  "Show the state of a queued, running or finished job",
+	Run:   showJob,
+}
+
+var jobCancelCmd = &cobra.Command{
+	Use:   "cancel [id]",
+	Short: "cancel a queued job",
+	Long:  "Remove a job from the queue, so long as it hasn't started yet",
+	Run:   cancelJob,
+}
+
+var jobRequeueCmd = &cobra.Command{
+	Use:   "requeue [id]",
+	Short: "requeue a failed job",
+	Long:  "Push a failed or cancelled job again with the same parameters",
+	Run:   requeueJob,
+}
+
+var jobLogCmd = &cobra.Command{
+	Use:   "log [id]",
+	Short: "show the log of a job",
+	Long:  "Show the messages logged by a running or finished job",
+	Run:   showJobLog,
+}
+
+// waitJob is set by --wait, making job commands block until completion
+var waitJob bool
+
+// addWaitFlag will register --wait on commands that queue a job
+func addWaitFlag(cmds ...*cobra.Command) {
+	for _, cmd := range cmds {
+		cmd.Flags().BoolVarP(&waitJob, "wait", "w", false, "Wait for the scheduled job to finish, failing if the job fails")
+	}
+}
+
+// waitInterval is how often we poll the daemon while waiting on a job
+const waitInterval = time.Second
+
+func init() {
+	jobCmd.AddCommand(jobCancelCmd)
+	jobCmd.AddCommand(jobRequeueCmd)
+	jobCmd.AddCommand(jobLogCmd)
+	addWaitFlag(jobCmd, jobRequeueCmd)
+	RootCmd.AddCommand(jobCmd)
+}
+
+// parseJobID will parse the single job ID argument for a job command
+func parseJobID(usage string, args []string) (uint64, bool) {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usage)
+		return 0, false
+	}
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid job ID: %v\n", err)
+		return 0, false
+	}
+	return id, true
+}
+
+// finishJob reports the job scheduled by a command. With --wait we block
+// until it finishes, exiting non-zero if it failed. Commands exit non-zero
+// themselves if the job could not be queued at all.
+func finishJob(client *libferry.Client, id uint64) {
+	if !waitJob {
+		fmt.Printf("Queued job %d\n", id)
+		return
+	}
+
+	job, err := client.WaitJob(id, waitInterval)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while waiting on job %d: %v\n", id, err)
+		os.Exit(1)
+	}
+	printJob(job)
+	if job.Failed {
+		os.Exit(1)
+	}
+}
+
+// printJob will display the state of a job, along with any result
+func printJob(job *libferry.Job) {
+	fmt.Printf("Job %d: %s\n", job.ID, job.Description)
+	fmt.Printf(" State: %s\n", job.State)
+
+	switch job.State {
+	case libferry.JobQueued:
+		fmt.Printf(" Queued: %s ago\n", job.QueuedSince())
+		if job.Attempt > 0 {
+			fmt.Printf(" Retry: attempt %d in %s\n", job.Attempt+1, time.Until(job.NotBefore).Round(time.Second))
+			fmt.Printf(" Last error: %s\n", job.Error)
+		} else if !job.NotBefore.IsZero() {
+			fmt.Printf(" Waiting: starts in %s\n", time.Until(job.NotBefore).Round(time.Second))
+		}
+	case libferry.JobRunning:
+		fmt.Printf(" Running: %s\n", time.Now().UTC().Sub(job.Timing.Begin))
+		if job.Progress != nil {
+			fmt.Printf(" Progress: %s\n", job.Progress)
+		}
+	default:
+		fmt.Printf(" Queued: %s\n", job.QueuedTime())
+		fmt.Printf(" Execution: %s\n", job.ExecutionTime())
+	}
+
+	if job.Failed {
+		if job.Attempt > 0 {
+			fmt.Printf(" Retries: %d\n", job.Attempt)
+		}
+		fmt.Fprintf(os.Stderr, " Error: %s\n", job.Error)
+	}
+
+	if len(job.Result) > 0 && string(job.Result) != "null" {
+		out, err := json.MarshalIndent(job.Result, " ", "  ")
+		if err != nil {
+			out = job.Result
+		}
+		fmt.Printf(" Result: %s\n", out)
+	}
+}
+
+func showJob(cmd *cobra.Command, args []string) {
+	id, ok := parseJobID("job [id]", args)
+	if !ok {
+		return
+	}
+
+	client := newClient()
+	defer client.Close()
+
+	if waitJob {
+		finishJob(client, id)
+		return
+	}
+
+	job, err := client.GetJob(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while getting job: %v\n", err)
+		return
+	}
+	printJob(job)
+}
+
+func cancelJob(cmd *cobra.Command, args []string) {
+	id, ok := parseJobID("job cancel [id]", args)
+	if !ok {
+		return
+	}
+
+	client := newClient()
+	defer client.Close()
+
+	if err := client.CancelJob(id); err != nil {
+		fmt.Fprintf(os.Stderr, "Error while cancelling job: %v\n", err)
+		return
+	}
+}
+
+func requeueJob(cmd *cobra.Command, args []string) {
+	id, ok := parseJobID("job requeue [id]", args)
+	if !ok {
+		return
+	}
+
+	client := newClient()
+	defer client.Close()
+
+	newID, err := client.RequeueJob(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while requeueing job: %v\n", err)
+		os.Exit(1)
+	}
+	finishJob(client, newID)
+}
+
+// printJobLog will display log messages in the style of the daemon log
+func printJobLog(entries []libferry.JobLogEntry) {
+	for _, e := range entries {
+		keys := make([]string, 0, len(e.Fields))
+		for k := range e.Fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		line := fmt.Sprintf("%s %-7s %s", e.Time.Local().Format("2006-01-02 15:04:05"), strings.ToUpper(e.Level), e.Message)
+		for _, k := range keys {
+			v := e.Fields[k]
+			if strings.ContainsAny(v, " \"=") {
+				v = strconv.Quote(v)
+			}
+			line += fmt.Sprintf(" %s=%s", k, v)
+		}
+		fmt.Println(line)
+	}
+}
+
+func showJobLog(cmd *cobra.Command, args []string) {
+	id, ok := parseJobID("job log [id]", args)
+	if !ok {
+		return
+	}
+
+	client := newClient()
+	defer client.Close()
+
+	entries, err := client.GetJobLog(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while getting job log: %v\n", err)
+		return
+	}
+	printJobLog(entries)
+}
@@ -22,8 +22,6 @@ import (
 	"sort"
 
 	"github.com/spf13/cobra"
-
-	"github.com/getsolus/ferryd/src/libferry"
 )
 
 var listReposCmd = &cobra.Command{
@@ -43,7 +41,7 @@ func listRepos(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	client := libferry.NewClient(socketPath)
+	client := newClient()
 	defer client.Close()
 
 	repos, err := client.GetRepos()
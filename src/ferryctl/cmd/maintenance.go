@@ -0,0 +1,49 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var maintenanceCmd = &cobra.Command{
+	Use:       "maintenance [on|off]",
+	Short:     "enter or leave maintenance",
+	Long:      "While in maintenance both job queues are paused and changes are refused, unless --override-maintenance is given. Incoming uploads are still queued. Queues paused before entering maintenance stay paused after leaving it.",
+	Run:       setMaintenance,
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: []string{"on", "off"},
+}
+
+var maintenanceReason string
+
+func init() {
+	maintenanceCmd.Flags().StringVarP(&maintenanceReason, "reason", "m", "", "Explain why ferryd is in maintenance")
+	RootCmd.AddCommand(maintenanceCmd)
+}
+
+func setMaintenance(_ *cobra.Command, args []string) {
+	client := newClient()
+	defer client.Close()
+
+	if err := client.SetMaintenance(args[0] == "on", maintenanceReason); err != nil {
+		fmt.Fprintf(os.Stderr, "Error while setting maintenance: %v\n", err)
+	}
+}
@@ -0,0 +1,75 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/getsolus/ferryd/src/libferry"
+)
+
+var (
+	pauseCmd = &cobra.Command{
+		Use:       "pause [sequential|async]",
+		Short:     "stop starting new jobs",
+		Long:      "Pause one or both job queues. Running jobs finish, and new jobs are queued until resumed.",
+		Run:       pauseQueue,
+		Args:      cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: []string{libferry.QueueSequential, libferry.QueueAsync},
+	}
+	resumeCmd = &cobra.Command{
+		Use:       "resume [sequential|async]",
+		Short:     "start jobs again",
+		Long:      "Resume one or both paused job queues",
+		Run:       resumeQueue,
+		Args:      cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: []string{libferry.QueueSequential, libferry.QueueAsync},
+	}
+)
+
+func init() {
+	RootCmd.AddCommand(pauseCmd, resumeCmd)
+}
+
+// queueArg returns the queue named by the arguments, or both when empty
+func queueArg(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
+}
+
+func pauseQueue(_ *cobra.Command, args []string) {
+	client := newClient()
+	defer client.Close()
+
+	if err := client.Pause(queueArg(args)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error while pausing: %v\n", err)
+	}
+}
+
+func resumeQueue(_ *cobra.Command, args []string) {
+	client := newClient()
+	defer client.Close()
+
+	if err := client.Resume(queueArg(args)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error while resuming: %v\n", err)
+	}
+}
@@ -0,0 +1,70 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/getsolus/ferryd/src/libferry"
+)
+
+// dryRun is shared by every command supporting --dry-run
+var dryRun bool
+
+// printPlan will display the changes an operation would make
+func printPlan(plan *libferry.ChangePlanRequest) {
+	if len(plan.Ref) == 0 && len(plan.Unref) == 0 && len(plan.Skipped) == 0 {
+		fmt.Printf("No changes would be made to '%s'.\n", plan.Repo)
+		return
+	}
+
+	fmt.Printf("Changes to '%s':\n\n", plan.Repo)
+	for _, id := range plan.Ref {
+		fmt.Printf(" + %s\n", id)
+	}
+	for _, id := range plan.Unref {
+		fmt.Printf(" - %s\n", id)
+	}
+
+	if len(plan.Published) > 0 {
+		fmt.Printf("\nPublished changes: (%d)\n\n", len(plan.Published))
+		for _, change := range plan.Published {
+			switch {
+			case change.From == "":
+				fmt.Printf(" * %s: (new) -> %s\n", change.Name, change.To)
+			case change.To == "":
+				fmt.Printf(" * %s: %s -> (removed)\n", change.Name, change.From)
+			default:
+				fmt.Printf(" * %s: %s -> %s\n", change.Name, change.From, change.To)
+			}
+		}
+	}
+
+	if len(plan.Skipped) > 0 {
+		fmt.Printf("\nSkipped, newer in '%s': (%d)\n\n", plan.Repo, len(plan.Skipped))
+		for _, name := range plan.Skipped {
+			fmt.Printf(" ! %s\n", name)
+		}
+	}
+
+	if len(plan.Deltas) > 0 {
+		fmt.Printf("\nDeltas dropped: (%d)\n\n", len(plan.Deltas))
+		for _, id := range plan.Deltas {
+			fmt.Printf(" - %s\n", id)
+		}
+	}
+}
@@ -32,8 +32,22 @@ var pullRepoCmd = &cobra.Command{
 	Run:   pullRepo,
 }
 
+var (
+	pullFilter   libferry.PullFilter
+	pullConflict string
+)
+
 func init() {
 	RootCmd.AddCommand(pullRepoCmd)
+	addWaitFlag(pullRepoCmd)
+	pullRepoCmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Show what would change without changing anything")
+	pullRepoCmd.Flags().StringSliceVar(&pullFilter.AllowSources, "source", nil, "Only pull packages with these source names")
+	pullRepoCmd.Flags().StringSliceVar(&pullFilter.AllowNames, "name", nil, "Only pull packages with these names")
+	pullRepoCmd.Flags().StringSliceVar(&pullFilter.AllowComponents, "component", nil, "Only pull packages in these components")
+	pullRepoCmd.Flags().StringSliceVar(&pullFilter.DenySources, "exclude-source", nil, "Never pull packages with these source names")
+	pullRepoCmd.Flags().StringSliceVar(&pullFilter.DenyNames, "exclude-name", nil, "Never pull packages with these names")
+	pullRepoCmd.Flags().StringSliceVar(&pullFilter.DenyComponents, "exclude-component", nil, "Never pull packages in these components")
+	pullRepoCmd.Flags().StringVar(&pullConflict, "on-conflict", "", "When the target is newer: fail (default), skip or downgrade")
 }
 
 func pullRepo(cmd *cobra.Command, args []string) {
@@ -42,11 +56,30 @@ func pullRepo(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	client := libferry.NewClient(socketPath)
+	client := newClient()
 	defer client.Close()
 
-	if err := client.PullRepo(args[0], args[1]); err != nil {
-		fmt.Fprintf(os.Stderr, "Error while pulling repo: %v\n", err)
+	// Only send a filter when one was asked for
+	var filter *libferry.PullFilter
+	if len(pullFilter.AllowSources)+len(pullFilter.AllowNames)+len(pullFilter.AllowComponents)+
+		len(pullFilter.DenySources)+len(pullFilter.DenyNames)+len(pullFilter.DenyComponents) > 0 {
+		filter = &pullFilter
+	}
+
+	if dryRun {
+		plan, err := client.PlanPullRepo(args[0], args[1], filter, pullConflict)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error while planning pull: %v\n", err)
+			return
+		}
+		printPlan(plan)
 		return
 	}
+
+	id, err := client.PullRepoWith(args[0], args[1], filter, pullConflict)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while pulling repo: %v\n", err)
+		os.Exit(1)
+	}
+	finishJob(client, id)
 }
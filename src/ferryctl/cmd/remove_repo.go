@@ -21,8 +21,6 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
-
-	"github.com/getsolus/ferryd/src/libferry"
 )
 
 var removeRepoCmd = &cobra.Command{
@@ -34,6 +32,7 @@ var removeRepoCmd = &cobra.Command{
 
 func init() {
 	RemoveCmd.AddCommand(removeRepoCmd)
+	addWaitFlag(removeRepoCmd)
 }
 
 func removeRepo(cmd *cobra.Command, args []string) {
@@ -42,11 +41,13 @@ func removeRepo(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	client := libferry.NewClient(socketPath)
+	client := newClient()
 	defer client.Close()
 
-	if err := client.DeleteRepo(args[0]); err != nil {
+	id, err := client.DeleteRepo(args[0])
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error while deleting repo: %v\n", err)
-		return
+		os.Exit(1)
 	}
+	finishJob(client, id)
 }
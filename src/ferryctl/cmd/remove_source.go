@@ -22,8 +22,6 @@ import (
 	"strconv"
 
 	"github.com/spf13/cobra"
-
-	"github.com/getsolus/ferryd/src/libferry"
 )
 
 var removeSourceCmd = &cobra.Command{
@@ -35,6 +33,8 @@ var removeSourceCmd = &cobra.Command{
 
 func init() {
 	RemoveCmd.AddCommand(removeSourceCmd)
+	addWaitFlag(removeSourceCmd)
+	removeSourceCmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Show what would change without changing anything")
 }
 
 func removeSource(cmd *cobra.Command, args []string) {
@@ -67,11 +67,23 @@ func removeSource(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	client := libferry.NewClient(socketPath)
+	client := newClient()
 	defer client.Close()
 
-	if err := client.RemoveSource(repoID, sourceID, sourceRelease); err != nil {
-		fmt.Fprintf(os.Stderr, "Error while removing source: %v\n", err)
+	if dryRun {
+		plan, err := client.PlanRemoveSource(repoID, sourceID, sourceRelease)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error while planning source removal: %v\n", err)
+			return
+		}
+		printPlan(plan)
 		return
 	}
+
+	id, err := client.RemoveSource(repoID, sourceID, sourceRelease)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while removing source: %v\n", err)
+		os.Exit(1)
+	}
+	finishJob(client, id)
 }
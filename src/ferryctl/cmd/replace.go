@@ -0,0 +1,64 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+var replaceCmd = &cobra.Command{
+	Use:   "replace [packages]",
+	Short: "replace known-bad packages in the pool",
+	Long:  "Replace the content of existing pool entries, relinking them in every repository",
+	Run:   replacePackages,
+}
+
+func init() {
+	RootCmd.AddCommand(replaceCmd)
+	addWaitFlag(replaceCmd)
+}
+
+func replacePackages(cmd *cobra.Command, args []string) {
+	if len(args) < 1 {
+		fmt.Fprintf(os.Stderr, "Usage: replace [packages]\n")
+		return
+	}
+
+	client := newClient()
+	defer client.Close()
+
+	var packages []string
+	for _, arg := range args {
+		f, err := filepath.Abs(arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to probe: %s: %v\n", arg, err)
+			return
+		}
+		packages = append(packages, f)
+	}
+
+	id, err := client.ReplacePackages(packages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while replacing packages: %v\n", err)
+		os.Exit(1)
+	}
+	finishJob(client, id)
+}
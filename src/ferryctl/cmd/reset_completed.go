@@ -21,8 +21,6 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
-
-	"github.com/getsolus/ferryd/src/libferry"
 )
 
 var resetCompletedCmd = &cobra.Command{
@@ -42,7 +40,7 @@ func resetCompleted(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	client := libferry.NewClient(socketPath)
+	client := newClient()
 	defer client.Close()
 
 	if err := client.ResetCompleted(); err != nil {
@@ -21,6 +21,8 @@ import (
 	"path"
 
 	"github.com/spf13/cobra"
+
+	"github.com/getsolus/ferryd/src/libferry"
 )
 
 // RootCmd is the main entry point into ferry
@@ -62,10 +64,21 @@ var TrimCmd = &cobra.Command{
 var (
 	// Default location for the unix socket
 	socketPath = "/run/ferryd.sock"
+
+	// Make changes even while ferryd is in maintenance
+	overrideMaintenance = false
 )
 
+// newClient will connect to ferryd with the global options
+func newClient() *libferry.Client {
+	client := libferry.NewClient(socketPath)
+	client.SetOverride(overrideMaintenance)
+	return client
+}
+
 func init() {
 	RootCmd.PersistentFlags().StringVarP(&socketPath, "socket", "s", "/run/ferryd.sock", "Set the socket path to talk to ferryd")
+	RootCmd.PersistentFlags().BoolVar(&overrideMaintenance, "override-maintenance", false, "Make changes even while ferryd is in maintenance")
 
 	RootCmd.AddCommand(CopyCmd)
 	RootCmd.AddCommand(ListCmd)
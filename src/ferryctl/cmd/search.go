@@ -0,0 +1,84 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/getsolus/ferryd/src/libferry"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search [namePattern]",
+	Short: "search for packages",
+	Long:  "Find packages across repositories by name pattern, source, packager, license, component or pkgconfig name",
+	Run:   searchPackages,
+	Args:  cobra.MaximumNArgs(1),
+}
+
+var searchQuery libferry.SearchRequest
+
+func init() {
+	searchCmd.Flags().StringSliceVarP(&searchQuery.Repos, "repo", "r", nil, "Limit the search to the given repositories")
+	searchCmd.Flags().StringVar(&searchQuery.Source, "source", "", "Match the source name")
+	searchCmd.Flags().IntVar(&searchQuery.Release, "release", 0, "Match the release number")
+	searchCmd.Flags().StringVar(&searchQuery.Packager, "packager", "", "Match part of the packager name or email")
+	searchCmd.Flags().StringVar(&searchQuery.License, "license", "", "Match a license")
+	searchCmd.Flags().StringVar(&searchQuery.Component, "component", "", "Match the component (PartOf)")
+	searchCmd.Flags().StringVar(&searchQuery.PkgConfig, "pkgconfig", "", "Match a provided pkgconfig name")
+	searchCmd.Flags().BoolVarP(&searchQuery.Published, "published", "p", false, "Only match published packages")
+	RootCmd.AddCommand(searchCmd)
+}
+
+func searchPackages(_ *cobra.Command, args []string) {
+	if len(args) == 1 {
+		searchQuery.Name = args[0]
+	}
+
+	client := newClient()
+	defer client.Close()
+
+	results, err := client.Search(&searchQuery)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while searching: %v\n", err)
+		return
+	}
+
+	if len(results) == 0 {
+		fmt.Println("No matching packages.")
+		return
+	}
+
+	repo := ""
+	for _, result := range results {
+		if result.Repo != repo {
+			if repo != "" {
+				fmt.Println()
+			}
+			repo = result.Repo
+			fmt.Printf("%s:\n\n", repo)
+		}
+		marker := " "
+		if result.Published {
+			marker = "*"
+		}
+		fmt.Printf(" %s %s %s-%d (%s)\n", marker, result.Name, result.Version, result.Release, result.ID)
+	}
+}
@@ -0,0 +1,126 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+// SnapshotCmd is the parent for snapshot type commands
+var SnapshotCmd = &cobra.Command{
+	Use:   "snapshot [create] [list] [delete] [restore]",
+	Short: "manage repository snapshots",
+}
+
+var (
+	snapshotCreateCmd = &cobra.Command{
+		Use:   "create [repo] [name]",
+		Short: "take a named snapshot of a repository",
+		Long:  "Record the full state of a repository so that it can be restored later",
+		Run:   snapshotCreate,
+		Args:  cobra.ExactArgs(2),
+	}
+	snapshotListCmd = &cobra.Command{
+		Use:   "list [repo]",
+		Short: "list snapshots of a repository",
+		Long:  "List the snapshots currently held for a repository",
+		Run:   snapshotList,
+		Args:  cobra.ExactArgs(1),
+	}
+	snapshotDeleteCmd = &cobra.Command{
+		Use:   "delete [repo] [name]",
+		Short: "delete a repository snapshot",
+		Long:  "Delete a repository snapshot, releasing the packages it holds",
+		Run:   snapshotDelete,
+		Args:  cobra.ExactArgs(2),
+	}
+	snapshotRestoreCmd = &cobra.Command{
+		Use:   "restore [repo] [name]",
+		Short: "restore a repository to a snapshot",
+		Long:  "Roll a repository back to the exact state recorded in a snapshot",
+		Run:   snapshotRestore,
+		Args:  cobra.ExactArgs(2),
+	}
+)
+
+func init() {
+	SnapshotCmd.AddCommand(snapshotCreateCmd, snapshotListCmd, snapshotDeleteCmd, snapshotRestoreCmd)
+	RootCmd.AddCommand(SnapshotCmd)
+	addWaitFlag(snapshotCreateCmd, snapshotDeleteCmd, snapshotRestoreCmd)
+}
+
+func snapshotCreate(_ *cobra.Command, args []string) {
+	client := newClient()
+	defer client.Close()
+
+	id, err := client.CreateSnapshot(args[0], args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while creating snapshot: %v\n", err)
+		os.Exit(1)
+	}
+	finishJob(client, id)
+}
+
+func snapshotList(_ *cobra.Command, args []string) {
+	client := newClient()
+	defer client.Close()
+
+	snapshots, err := client.GetSnapshots(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while getting snapshots: %v\n", err)
+		return
+	}
+	if len(snapshots) == 0 {
+		fmt.Printf("No snapshots exist for '%s'.\n", args[0])
+		return
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Created.Before(snapshots[j].Created)
+	})
+	fmt.Printf("Snapshots of '%s': \n\n", args[0])
+	for _, snapshot := range snapshots {
+		fmt.Printf(" * %v (%v, %d packages)\n", snapshot.Name, snapshot.Created.Format("2006-01-02 15:04:05"), snapshot.Packages)
+	}
+}
+
+func snapshotDelete(_ *cobra.Command, args []string) {
+	client := newClient()
+	defer client.Close()
+
+	id, err := client.DeleteSnapshot(args[0], args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while deleting snapshot: %v\n", err)
+		os.Exit(1)
+	}
+	finishJob(client, id)
+}
+
+func snapshotRestore(_ *cobra.Command, args []string) {
+	client := newClient()
+	defer client.Close()
+
+	id, err := client.RestoreSnapshot(args[0], args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while restoring snapshot: %v\n", err)
+		os.Exit(1)
+	}
+	finishJob(client, id)
+}
@@ -20,8 +20,10 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strings"
 
 	"github.com/olekukonko/tablewriter"
+	"github.com/olekukonko/tablewriter/tw"
 	"github.com/spf13/cobra"
 
 	"github.com/getsolus/ferryd/src/libferry"
@@ -45,16 +47,24 @@ func init() {
 	RootCmd.AddCommand(statusCmd)
 }
 
+// newTable returns a borderless table for printing jobs
+func newTable(header []string) *tablewriter.Table {
+	table := tablewriter.NewTable(os.Stdout,
+		tablewriter.WithRendition(tw.Rendition{Borders: tw.BorderNone}))
+	table.Header(header)
+	return table
+}
+
 func printActiveJobs(js []*libferry.Job) {
 	header := []string{
 		"Status",
+		"Priority",
 		"Queued",
 		"Waited",
+		"Progress",
 		"Description",
 	}
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader(header)
-	table.SetBorder(false)
+	table := newTable(header)
 
 	i := 0
 
@@ -69,10 +79,16 @@ func printActiveJobs(js []*libferry.Job) {
 		} else {
 			runType = "running"
 		}
+		var progress string
+		if j.Progress != nil {
+			progress = j.Progress.String()
+		}
 		table.Append([]string{
 			runType,
+			j.Priority,
 			j.Timing.Queued.Format("2006-01-02 15:04:05"),
 			j.QueuedSince().String(),
+			progress,
 			j.Description,
 		})
 	}
@@ -88,9 +104,7 @@ func printFailedJobs(js []*libferry.Job) {
 		"Description",
 		"Error",
 	}
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader(header)
-	table.SetBorder(false)
+	table := newTable(header)
 
 	i := 0
 
@@ -119,9 +133,7 @@ func printCompletedJobs(js []*libferry.Job) {
 		"Execution time",
 		"Description",
 	}
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader(header)
-	table.SetBorder(false)
+	table := newTable(header)
 
 	i := 0
 
@@ -141,13 +153,43 @@ func printCompletedJobs(js []*libferry.Job) {
 	table.Render()
 }
 
+// Print the recurring jobs and when they next run
+func printScheduledJobs(js []libferry.ScheduledJob) {
+	header := []string{
+		"Name",
+		"Schedule",
+		"Next run",
+		"Last run",
+		"Description",
+	}
+	table := newTable(header)
+
+	for _, j := range js {
+		next, last := "never", "never"
+		if !j.NextRun.IsZero() {
+			next = j.NextRun.Local().Format("2006-01-02 15:04")
+		}
+		if !j.LastRun.IsZero() {
+			last = fmt.Sprintf("%s (job %d)", j.LastRun.Local().Format("2006-01-02 15:04"), j.LastJob)
+		}
+		table.Append([]string{
+			j.Name,
+			j.Schedule,
+			next,
+			last,
+			j.Description,
+		})
+	}
+	table.Render()
+}
+
 func getStatus(cmd *cobra.Command, args []string) {
 	if len(args) != 0 {
 		fmt.Fprintf(os.Stderr, "status takes no arguments\n")
 		return
 	}
 
-	client := libferry.NewClient(socketPath)
+	client := newClient()
 	defer client.Close()
 
 	status, err := client.GetStatus()
@@ -159,6 +201,23 @@ func getStatus(cmd *cobra.Command, args []string) {
 	// Show uptime
 	fmt.Printf(" - Daemon uptime: %v\n", status.Uptime())
 	fmt.Printf(" - Daemon version: %v\n", status.Version)
+	if pause := status.Paused; pause.Paused() {
+		var queues []string
+		if pause.Sequential {
+			queues = append(queues, libferry.QueueSequential)
+		}
+		if pause.Async {
+			queues = append(queues, libferry.QueueAsync)
+		}
+		fmt.Printf(" - Paused: %s (since %s)\n", strings.Join(queues, ", "), pause.Since.Local().Format("2006-01-02 15:04:05"))
+	}
+	if status.Paused.Maintenance {
+		reason := status.Paused.Reason
+		if reason == "" {
+			reason = "no reason given"
+		}
+		fmt.Printf(" - In maintenance: %s\n", reason)
+	}
 
 	// Show failing
 	if len(status.FailedJobs) > 0 {
@@ -179,4 +238,9 @@ func getStatus(cmd *cobra.Command, args []string) {
 		fmt.Printf("Completed jobs: (%d tracked)\n\n", len(status.CompletedJobs))
 		printCompletedJobs(status.CompletedJobs)
 	}
+
+	if len(status.ScheduledJobs) > 0 {
+		fmt.Printf("Scheduled jobs: (%d tracked)\n\n", len(status.ScheduledJobs))
+		printScheduledJobs(status.ScheduledJobs)
+	}
 }
@@ -21,8 +21,6 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
-
-	"github.com/getsolus/ferryd/src/libferry"
 )
 
 var trimObsoleteCmd = &cobra.Command{
@@ -34,6 +32,8 @@ var trimObsoleteCmd = &cobra.Command{
 
 func init() {
 	TrimCmd.AddCommand(trimObsoleteCmd)
+	addWaitFlag(trimObsoleteCmd)
+	trimObsoleteCmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Show what would change without changing anything")
 }
 
 func trimObsolete(cmd *cobra.Command, args []string) {
@@ -42,11 +42,23 @@ func trimObsolete(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	client := libferry.NewClient(socketPath)
+	client := newClient()
 	defer client.Close()
 
-	if err := client.TrimObsolete(args[0]); err != nil {
-		fmt.Fprintf(os.Stderr, "Error while trimming obsoletes: %v\n", err)
+	if dryRun {
+		plan, err := client.PlanTrimObsolete(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error while planning obsoletes trim: %v\n", err)
+			return
+		}
+		printPlan(plan)
 		return
 	}
+
+	id, err := client.TrimObsolete(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while trimming obsoletes: %v\n", err)
+		os.Exit(1)
+	}
+	finishJob(client, id)
 }
@@ -22,8 +22,6 @@ import (
 	"strconv"
 
 	"github.com/spf13/cobra"
-
-	"github.com/getsolus/ferryd/src/libferry"
 )
 
 var trimPackagesCmd = &cobra.Command{
@@ -35,6 +33,8 @@ var trimPackagesCmd = &cobra.Command{
 
 func init() {
 	TrimCmd.AddCommand(trimPackagesCmd)
+	addWaitFlag(trimPackagesCmd)
+	trimPackagesCmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Show what would change without changing anything")
 }
 
 func trimPackages(cmd *cobra.Command, args []string) {
@@ -54,13 +54,25 @@ func trimPackages(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	client := libferry.NewClient(socketPath)
+	client := newClient()
 	defer client.Close()
 
 	repoID := args[0]
 
-	if err := client.TrimPackages(repoID, int(maxKeep)); err != nil {
-		fmt.Fprintf(os.Stderr, "Error while trimming packages: %v\n", err)
+	if dryRun {
+		plan, err := client.PlanTrimPackages(repoID, int(maxKeep))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error while planning package trim: %v\n", err)
+			return
+		}
+		printPlan(plan)
 		return
 	}
+
+	id, err := client.TrimPackages(repoID, int(maxKeep))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while trimming packages: %v\n", err)
+		os.Exit(1)
+	}
+	finishJob(client, id)
 }
@@ -17,21 +17,28 @@
 package core
 
 import (
+	"context"
+	"fmt"
 	"path/filepath"
+	"sort"
+	"time"
 
 	"github.com/getsolus/ferryd/src/libeopkg"
 )
 
 // This file provides the public API functions which are used by ferryd
 // and exposed through it's handlers.
+//
+// Operations that reindex or produce deltas take a context, which will stop
+// any compression or CDN purge still running once it is done.
 
 // CreateRepo will request the creation of a new repository
-func (m *Manager) CreateRepo(id string) error {
+func (m *Manager) CreateRepo(ctx context.Context, id string) error {
 	if _, err := m.repo.CreateRepo(m.db, id); err != nil {
 		return err
 	}
 	// Index the newly created repo
-	return m.Index(id)
+	return m.Index(ctx, id)
 }
 
 // CloneRepo will initially construct a new repository, and then ask that
@@ -39,7 +46,7 @@ func (m *Manager) CreateRepo(id string) error {
 //
 // If fullClone is set, all packages are copied. Otherwise only the tip for
 // each package is taken.
-func (m *Manager) CloneRepo(repoID, newClone string, fullClone bool) error {
+func (m *Manager) CloneRepo(ctx context.Context, repoID, newClone string, fullClone bool) error {
 	// Try to get the source repo
 	sourceRepo, err := m.repo.GetRepo(m.db, repoID)
 	if err != nil {
@@ -54,16 +61,17 @@ func (m *Manager) CloneRepo(repoID, newClone string, fullClone bool) error {
 	}
 
 	// Now ask it to clone..
-	if err = newRepo.CloneFrom(m.db, m.pool, sourceRepo, fullClone); err != nil {
+	if err = newRepo.CloneFrom(ctx, m.db, m.pool, sourceRepo, fullClone); err != nil {
 		return err
 	}
 
 	// Success, index the new guy
-	return m.Index(newClone)
+	return m.Index(ctx, newClone)
 }
 
-// PullRepo will pull from one repo, the source ID, into the target repository
-func (m *Manager) PullRepo(sourceID, targetID string) ([]string, error) {
+// PullRepo will pull from one repo, the source ID, into the target repository,
+// using the options to filter packages and resolve conflicts when given
+func (m *Manager) PullRepo(ctx context.Context, sourceID, targetID string, opts *PullOptions) (*PullResult, error) {
 	// Try to get the source repo
 	sourceRepo, err := m.repo.GetRepo(m.db, sourceID)
 	if err != nil {
@@ -77,37 +85,69 @@ func (m *Manager) PullRepo(sourceID, targetID string) ([]string, error) {
 	}
 
 	// Now ask it to pull..
-	changed, err := targetRepo.PullFrom(m.db, m.pool, sourceRepo)
+	result, err := targetRepo.PullFrom(ctx, m.db, m.pool, sourceRepo, opts)
 	if err != nil {
 		return nil, err
 	}
 
 	// Success, index the target
-	if err = m.Index(targetID); err != nil {
+	if err = m.Index(ctx, targetID); err != nil {
 		return nil, err
 	}
 
 	// Tell caller which ones changed
-	return changed, nil
+	return result, nil
+}
+
+// DiffRepos will compare the published packages of the source repository
+// against the target repository
+func (m *Manager) DiffRepos(sourceID, targetID string) (*RepoDiff, error) {
+	sourceRepo, err := m.repo.GetRepo(m.db, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	targetRepo, err := m.repo.GetRepo(m.db, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	return sourceRepo.Diff(m.db, m.pool, targetRepo)
+}
+
+// GetChangelog will collect the updates that are new in the source repository
+// when compared to the target repository
+func (m *Manager) GetChangelog(sourceID, targetID string) (*Changelog, error) {
+	sourceRepo, err := m.repo.GetRepo(m.db, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	targetRepo, err := m.repo.GetRepo(m.db, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	return sourceRepo.Changelog(m.db, m.pool, targetRepo)
 }
 
 // RemoveSource will ask the repo to remove all matching source==release
 // packages.
-func (m *Manager) RemoveSource(repoID, sourceID string, release int) error {
+func (m *Manager) RemoveSource(ctx context.Context, repoID, sourceID string, release int) error {
 	repo, err := m.repo.GetRepo(m.db, repoID)
 	if err != nil {
 		return err
 	}
 
-	if err = repo.RemoveSource(m.db, m.pool, sourceID, release); err != nil {
+	if err = repo.RemoveSource(ctx, m.db, m.pool, sourceID, release); err != nil {
 		return err
 	}
 
-	return m.Index(repoID)
+	return m.Index(ctx, repoID)
 }
 
 // CopySource will ask the repo to copy all matching source==release packages
-func (m *Manager) CopySource(repoID, target, sourceID string, release int, skipIndex bool) error {
+func (m *Manager) CopySource(ctx context.Context, repoID, target, sourceID string, release int, skipIndex bool) error {
 	sourceRepo, err := m.repo.GetRepo(m.db, repoID)
 	if err != nil {
 		return err
@@ -118,43 +158,107 @@ func (m *Manager) CopySource(repoID, target, sourceID string, release int, skipI
 		return err
 	}
 
-	if err = targetRepo.CopySourceFrom(m.db, m.pool, sourceRepo, sourceID, release); err != nil {
+	if err = targetRepo.CopySourceFrom(ctx, m.db, m.pool, sourceRepo, sourceID, release); err != nil {
 		return err
 	}
 
 	if skipIndex {
-		return m.Index(target)
+		return m.Index(ctx, target)
 	} else {
 		return nil
 	}
 }
 
 // TrimObsolete will ask the repo to remove obsolete packages
-func (m *Manager) TrimObsolete(repoID string) error {
+func (m *Manager) TrimObsolete(ctx context.Context, repoID string) error {
 	repo, err := m.repo.GetRepo(m.db, repoID)
 	if err != nil {
 		return err
 	}
 
-	if err = repo.TrimObsolete(m.db, m.pool); err != nil {
+	if err = repo.TrimObsolete(ctx, m.db, m.pool); err != nil {
 		return err
 	}
 
-	return m.Index(repoID)
+	return m.Index(ctx, repoID)
 }
 
 // TrimPackages will ask the repo to remove excessive packages
-func (m *Manager) TrimPackages(repoID string, maxKeep int) error {
+func (m *Manager) TrimPackages(ctx context.Context, repoID string, maxKeep int) error {
 	repo, err := m.repo.GetRepo(m.db, repoID)
 	if err != nil {
 		return err
 	}
 
-	if err = repo.TrimPackages(m.db, m.pool, maxKeep); err != nil {
+	if err = repo.TrimPackages(ctx, m.db, m.pool, maxKeep); err != nil {
 		return err
 	}
 
-	return m.Index(repoID)
+	return m.Index(ctx, repoID)
+}
+
+// PlanPullRepo will return the changes PullRepo would make, without applying them
+func (m *Manager) PlanPullRepo(sourceID, targetID string, opts *PullOptions) (*ChangePlan, error) {
+	sourceRepo, err := m.repo.GetRepo(m.db, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	targetRepo, err := m.repo.GetRepo(m.db, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	return targetRepo.PlanPull(m.db, m.pool, sourceRepo, opts)
+}
+
+// PlanRemoveSource will return the changes RemoveSource would make, without
+// applying them
+func (m *Manager) PlanRemoveSource(repoID, sourceID string, release int) (*ChangePlan, error) {
+	repo, err := m.repo.GetRepo(m.db, repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.PlanRemoveSource(m.db, m.pool, sourceID, release)
+}
+
+// PlanCopySource will return the changes CopySource would make, without
+// applying them
+func (m *Manager) PlanCopySource(repoID, target, sourceID string, release int) (*ChangePlan, error) {
+	sourceRepo, err := m.repo.GetRepo(m.db, repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	targetRepo, err := m.repo.GetRepo(m.db, target)
+	if err != nil {
+		return nil, err
+	}
+
+	return targetRepo.PlanCopySource(m.db, m.pool, sourceRepo, sourceID, release)
+}
+
+// PlanTrimObsolete will return the changes TrimObsolete would make, without
+// applying them
+func (m *Manager) PlanTrimObsolete(ctx context.Context, repoID string) (*ChangePlan, error) {
+	repo, err := m.repo.GetRepo(m.db, repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.PlanTrimObsolete(ctx, m.db, m.pool)
+}
+
+// PlanTrimPackages will return the changes TrimPackages would make, without
+// applying them
+func (m *Manager) PlanTrimPackages(repoID string, maxKeep int) (*ChangePlan, error) {
+	repo, err := m.repo.GetRepo(m.db, repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.PlanTrimPackages(m.db, m.pool, maxKeep)
 }
 
 // GetRepos will return all known repositories
@@ -163,8 +267,8 @@ func (m *Manager) GetRepos() ([]*Repository, error) {
 }
 
 // DeleteRepo exposes the API for repository deletion
-func (m *Manager) DeleteRepo(id string) error {
-	return m.repo.DeleteRepo(m.db, m.pool, id)
+func (m *Manager) DeleteRepo(ctx context.Context, id string) error {
+	return m.repo.DeleteRepo(ctx, m.db, m.pool, id)
 }
 
 // GetRepo will grab the repository if it exists
@@ -179,29 +283,40 @@ func (m *Manager) GetPoolItems() ([]*PoolEntry, error) {
 }
 
 // AddPackages will attempt to add the named packages to the repository
-func (m *Manager) AddPackages(repoID string, packages []string, anal bool) error {
+func (m *Manager) AddPackages(ctx context.Context, repoID string, packages []string, anal bool) error {
 	repo, err := m.GetRepo(repoID)
 	if err != nil {
 		return err
 	}
 
+	// Refuse the whole set before touching anything if any ID conflicts
 	for _, pkg := range packages {
-		if err := repo.AddPackage(m.db, m.pool, pkg, anal); err != nil {
+		if err := m.pool.CheckFile(m.db, pkg); err != nil {
+			return err
+		}
+	}
+
+	for i, pkg := range packages {
+		if err := stepProgress(ctx, "Add packages", i, len(packages)); err != nil {
+			return err
+		}
+		if err := repo.AddPackage(ctx, m.db, m.pool, pkg, anal); err != nil {
 			return err
 		}
 	}
 
-	return m.Index(repoID)
+	return m.Index(ctx, repoID)
 }
 
 // Index will cause the repository's index to be reconstructed
-func (m *Manager) Index(repoID string) error {
+func (m *Manager) Index(ctx context.Context, repoID string) error {
 	repo, err := m.GetRepo(repoID)
 	if err != nil {
 		return err
 	}
 
-	return repo.Index(m.db, m.pool)
+	reportProgress(ctx, "Index", 0, 0)
+	return repo.Index(ctx, m.db, m.pool)
 }
 
 // GetPackageNames will attempt to load all package names for the given
@@ -226,14 +341,57 @@ func (m *Manager) GetPackages(repoID, pkgName string) ([]*libeopkg.MetaPackage,
 	return repo.GetPackages(m.db, m.pool, pkgName)
 }
 
+// Search will find all packages matching the query, across every repository
+// unless the query limits the repositories to search
+func (m *Manager) Search(query *SearchQuery) ([]SearchResult, error) {
+	var repos []*Repository
+	if len(query.Repos) == 0 {
+		all, err := m.repo.GetRepos(m.db)
+		if err != nil {
+			return nil, err
+		}
+		repos = all
+	} else {
+		for _, id := range query.Repos {
+			repo, err := m.repo.GetRepo(m.db, id)
+			if err != nil {
+				return nil, err
+			}
+			repos = append(repos, repo)
+		}
+	}
+
+	sort.Slice(repos, func(i, j int) bool { return repos[i].ID < repos[j].ID })
+
+	var ret []SearchResult
+	for _, repo := range repos {
+		results, err := repo.Search(m.db, m.pool, query)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, results...)
+	}
+	return ret, nil
+}
+
+// GetDependencyGraph will return the runtime dependency graph of the repository
+func (m *Manager) GetDependencyGraph(repoID string) (*DependencyGraph, error) {
+	repo, err := m.GetRepo(repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.DependencyGraph(m.db, m.pool)
+}
+
 // CreateDelta will attempt to create a new delta package between the old and new IDs
-func (m *Manager) CreateDelta(repoID string, oldPkg, newPkg *libeopkg.MetaPackage) (string, error) {
+func (m *Manager) CreateDelta(ctx context.Context, repoID string, oldPkg, newPkg *libeopkg.MetaPackage) (string, error) {
 	repo, err := m.GetRepo(repoID)
 	if err != nil {
 		return "", err
 	}
 
-	return repo.CreateDelta(m.db, oldPkg, newPkg)
+	return repo.CreateDelta(ctx, m.db, oldPkg, newPkg)
 }
 
 // HasDelta will query the repository to determine if it already has the
@@ -248,22 +406,22 @@ func (m *Manager) HasDelta(repoID, pkgID, deltaPath string) (bool, error) {
 
 // AddDelta will attempt to include the delta package specified by deltaPath into
 // the target repository
-func (m *Manager) AddDelta(repoID, deltaPath string, mapping *DeltaInformation) error {
+func (m *Manager) AddDelta(ctx context.Context, repoID, deltaPath string, mapping *DeltaInformation) error {
 	repo, err := m.GetRepo(repoID)
 	if err != nil {
 		return err
 	}
 
-	return repo.AddDelta(m.db, m.pool, deltaPath, mapping)
+	return repo.AddDelta(ctx, m.db, m.pool, deltaPath, mapping)
 }
 
 // RefDelta will dupe an existing delta into the target repository
-func (m *Manager) RefDelta(repoID, deltaID string) error {
+func (m *Manager) RefDelta(ctx context.Context, repoID, deltaID string) error {
 	repo, err := m.GetRepo(repoID)
 	if err != nil {
 		return err
 	}
-	return repo.RefDelta(m.db, m.pool, deltaID)
+	return repo.RefDelta(ctx, m.db, m.pool, deltaID)
 }
 
 // MarkDeltaFailed will permanently record the delta package as failing so we do
@@ -306,3 +464,247 @@ func (m *Manager) UnfreezeRepo(repoID string) error {
 
 	return repo.Unfreeze()
 }
+
+// CheckInstallable will validate the dependencies of every published package
+// in the repository. The report is stored so that it can be retrieved later
+// with GetInstallReport.
+func (m *Manager) CheckInstallable(ctx context.Context, repoID string) (*InstallReport, error) {
+	repo, err := m.repo.GetRepo(m.db, repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := repo.CheckInstallable(ctx, m.db, m.pool)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket := m.db.Bucket([]byte(DatabaseBucketInstallable))
+	if err = bucket.PutObject([]byte(repoID), report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// GetInstallReport will return the report from the last installability check
+// of the repository
+func (m *Manager) GetInstallReport(repoID string) (*InstallReport, error) {
+	report := &InstallReport{}
+	bucket := m.db.Bucket([]byte(DatabaseBucketInstallable))
+	if err := bucket.GetObject([]byte(repoID), report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// SetInstallGate will enable or disable the installability gate on indexing
+// for the repository
+func (m *Manager) SetInstallGate(repoID string, enabled bool) error {
+	repo, err := m.repo.GetRepo(m.db, repoID)
+	if err != nil {
+		return err
+	}
+
+	if enabled {
+		return repo.EnableInstallGate()
+	}
+	return repo.DisableInstallGate()
+}
+
+// CreateSnapshot will record the current state of the repository under the
+// given name
+func (m *Manager) CreateSnapshot(repoID, name string) error {
+	repo, err := m.repo.GetRepo(m.db, repoID)
+	if err != nil {
+		return err
+	}
+
+	return repo.CreateSnapshot(m.db, m.pool, name)
+}
+
+// DeleteSnapshot will remove the named snapshot from the repository
+func (m *Manager) DeleteSnapshot(ctx context.Context, repoID, name string) error {
+	repo, err := m.repo.GetRepo(m.db, repoID)
+	if err != nil {
+		return err
+	}
+
+	return repo.DeleteSnapshot(ctx, m.db, m.pool, name)
+}
+
+// RestoreSnapshot will roll the repository back to the named snapshot
+func (m *Manager) RestoreSnapshot(ctx context.Context, repoID, name string) error {
+	repo, err := m.repo.GetRepo(m.db, repoID)
+	if err != nil {
+		return err
+	}
+
+	if err = repo.RestoreSnapshot(ctx, m.db, m.pool, name); err != nil {
+		return err
+	}
+
+	return m.Index(ctx, repoID)
+}
+
+// GetSnapshots will return all snapshots for the repository
+func (m *Manager) GetSnapshots(repoID string) ([]*RepoSnapshot, error) {
+	repo, err := m.repo.GetRepo(m.db, repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetSnapshots(m.db)
+}
+
+// CheckIntegrity will walk every repository and the pool, looking for any
+// inconsistencies between the database and the files on disk. The report is
+// stored so that it can be retrieved later with GetIntegrityReport.
+func (m *Manager) CheckIntegrity(ctx context.Context) (*IntegrityReport, error) {
+	report := &IntegrityReport{
+		Started: time.Now().UTC(),
+	}
+	refs := make(map[string]uint64)
+
+	repos, err := m.repo.GetRepos(m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range repos {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		repo, err := m.repo.GetRepo(m.db, r.ID)
+		if err != nil {
+			return nil, err
+		}
+		if err = repo.checkIntegrity(ctx, m.db, m.pool, report, refs); err != nil {
+			return nil, err
+		}
+		if err = repo.countSnapshotRefs(m.db, refs); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = m.pool.checkIntegrity(ctx, m.db, report, refs); err != nil {
+		return nil, err
+	}
+
+	report.Finished = time.Now().UTC()
+
+	bucket := m.db.Bucket([]byte(DatabaseBucketIntegrity))
+	if err = bucket.PutObject([]byte(IntegrityReportKey), report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// GetIntegrityReport will return the report from the last integrity check
+func (m *Manager) GetIntegrityReport() (*IntegrityReport, error) {
+	report := &IntegrityReport{}
+	bucket := m.db.Bucket([]byte(DatabaseBucketIntegrity))
+	if err := bucket.GetObject([]byte(IntegrityReportKey), report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// ReplacePackage will replace a known-bad pool entry with the package at path,
+// relinking it into every repository that references it. This is the only way
+// to change the content behind an existing package ID, and returns the IDs of
+// the repositories that were updated.
+func (m *Manager) ReplacePackage(ctx context.Context, path string) ([]string, error) {
+	pkg, err := libeopkg.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer pkg.Close()
+	if err = pkg.ReadMetadata(); err != nil {
+		return nil, err
+	}
+
+	repos, err := m.repo.GetRepos(m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find everyone affected first, so frozen repos stop us before any change
+	var affected []*Repository
+	for _, r := range repos {
+		repo, err := m.repo.GetRepo(m.db, r.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !repo.references(m.db, pkg.Meta.Package.Name, pkg.ID) {
+			continue
+		}
+		if err = repo.checkWrite(); err != nil {
+			return nil, fmt.Errorf("cannot replace %s in %s: %w", pkg.ID, repo.ID, err)
+		}
+		affected = append(affected, repo)
+	}
+
+	oldMeta, err := m.pool.ReplacePackage(m.db, pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated []string
+	for _, repo := range affected {
+		if err = repo.relinkReplaced(ctx, m.db, m.pool, oldMeta, pkg.ID); err != nil {
+			return updated, err
+		}
+		if err = repo.Index(ctx, m.db, m.pool); err != nil {
+			return updated, err
+		}
+		updated = append(updated, repo.ID)
+	}
+
+	return updated, nil
+}
+
+// RepairIntegrity will recompute the pool refcounts from the repositories,
+// restore any missing hardlinks, purge unreferenced pool entries and move
+// any untracked pool files into quarantine.
+//
+// Every repository is held until the pool has been repaired, as a package
+// added after its references were counted would otherwise be purged.
+func (m *Manager) RepairIntegrity(ctx context.Context) (*RepairReport, error) {
+	report := &RepairReport{
+		Started: time.Now().UTC(),
+	}
+	refs := make(map[string]uint64)
+
+	repos, err := m.repo.GetRepos(m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range repos {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		repo, err := m.repo.GetRepo(m.db, r.ID)
+		if err != nil {
+			return nil, err
+		}
+		repo.insertMut.Lock()
+		defer repo.insertMut.Unlock()
+
+		if err = repo.repair(ctx, m.db, m.pool, report, refs); err != nil {
+			return nil, err
+		}
+		if err = repo.countSnapshotRefs(m.db, refs); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = m.pool.repair(ctx, m.db, report, refs); err != nil {
+		return nil, err
+	}
+
+	report.Finished = time.Now().UTC()
+	return report, nil
+}
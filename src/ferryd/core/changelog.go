@@ -0,0 +1,163 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getsolus/ferryd/src/libdb"
+)
+
+// A ChangelogUpdate is a single History update from the package metadata
+type ChangelogUpdate struct {
+	Release  int    // Release number of the update
+	Version  string // Version at the time of the update
+	Date     string // When the update was issued
+	Type     string // Update type, i.e. security
+	Comment  string // Full comment for the update
+	Packager string // Name of the person responsible for the update
+}
+
+// A ChangelogEntry collects every new update for a single source package
+type ChangelogEntry struct {
+	Source      string            // Source package name
+	Packages    []string          // Binary package names that changed
+	FromVersion string            // Version published in the target, empty when new
+	FromRelease int               // Release published in the target, 0 when new
+	ToVersion   string            // Version published in the source
+	ToRelease   int               // Release published in the source
+	Updates     []ChangelogUpdate // Updates between the two, newest first
+}
+
+// A Changelog details everything new in the source repository when compared
+// to the target, i.e. the notes for an unstable -> stable sync
+type Changelog struct {
+	Source  string           // Source repository ID
+	Target  string           // Target repository ID
+	Entries []ChangelogEntry // One entry per changed source package
+}
+
+// Changelog will collect the History updates for every package that would be
+// newer in the target repository after pulling from this repository.
+func (r *Repository) Changelog(db libdb.Database, pool *Pool, target *Repository) (*Changelog, error) {
+	diff, err := r.Diff(db, pool, target)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := diff.Changed
+	for _, pkg := range diff.OnlySource {
+		changes = append(changes, DiffChange{Name: pkg.Name, Source: pkg})
+	}
+
+	log := &Changelog{
+		Source: r.ID,
+		Target: target.ID,
+	}
+	entries := make(map[string]*ChangelogEntry)
+
+	for _, change := range changes {
+		// Only interested in things that move forward
+		if change.Source.Release <= change.Target.Release {
+			continue
+		}
+
+		poolEntry, err := pool.GetEntry(db, change.Source.ID)
+		if err != nil {
+			return nil, err
+		}
+		sourceName := poolEntry.Meta.Source.Name
+
+		// Sub-packages share the history of their source
+		if entry, ok := entries[sourceName]; ok {
+			entry.Packages = append(entry.Packages, change.Name)
+			continue
+		}
+
+		entry := &ChangelogEntry{
+			Source:      sourceName,
+			Packages:    []string{change.Name},
+			FromVersion: change.Target.Version,
+			FromRelease: change.Target.Release,
+			ToVersion:   change.Source.Version,
+			ToRelease:   change.Source.Release,
+		}
+		for _, update := range poolEntry.Meta.History {
+			if update.Release <= change.Target.Release {
+				continue
+			}
+			entry.Updates = append(entry.Updates, ChangelogUpdate{
+				Release:  update.Release,
+				Version:  update.Version,
+				Date:     update.Date,
+				Type:     update.Type,
+				Comment:  strings.TrimSpace(update.Comment.Value),
+				Packager: update.Name.Value,
+			})
+		}
+		entries[sourceName] = entry
+	}
+
+	for _, entry := range entries {
+		sort.Strings(entry.Packages)
+		log.Entries = append(log.Entries, *entry)
+	}
+	sort.Slice(log.Entries, func(i, j int) bool {
+		return log.Entries[i].Source < log.Entries[j].Source
+	})
+
+	return log, nil
+}
+
+// Markdown will render the changelog as a Markdown document, using the first
+// line of each update comment
+func (c *Changelog) Markdown() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "# Changes from %s to %s\n", c.Source, c.Target)
+
+	if len(c.Entries) == 0 {
+		sb.WriteString("\nNo changes.\n")
+		return sb.String()
+	}
+
+	for _, entry := range c.Entries {
+		if entry.FromRelease == 0 {
+			fmt.Fprintf(&sb, "\n## %s %s-%d (new)\n\n", entry.Source, entry.ToVersion, entry.ToRelease)
+		} else {
+			fmt.Fprintf(&sb, "\n## %s %s-%d → %s-%d\n\n", entry.Source, entry.FromVersion, entry.FromRelease, entry.ToVersion, entry.ToRelease)
+		}
+		fmt.Fprintf(&sb, "Packages: %s\n\n", strings.Join(entry.Packages, ", "))
+
+		for _, update := range entry.Updates {
+			summary := strings.SplitN(update.Comment, "\n", 2)[0]
+			fmt.Fprintf(&sb, "- **%s-%d**", update.Version, update.Release)
+			if update.Type != "" {
+				fmt.Fprintf(&sb, " [%s]", update.Type)
+			}
+			fmt.Fprintf(&sb, " %s (%s", summary, update.Packager)
+			if update.Date != "" {
+				fmt.Fprintf(&sb, ", %s", update.Date)
+			}
+			sb.WriteString(")\n")
+		}
+	}
+
+	return sb.String()
+}
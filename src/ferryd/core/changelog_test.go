@@ -0,0 +1,69 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestChangelog(t *testing.T) {
+	manager := initTestRepo(t, "unstable", integrityTestPackage, snapshotTestPackage)
+	defer manager.Close()
+
+	if err := manager.CreateRepo(context.Background(), "stable"); err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	if err := manager.AddPackages(context.Background(), "stable", []string{integrityTestPackage}, false); err != nil {
+		t.Fatalf("Failed to add packages: %v", err)
+	}
+
+	log, err := manager.GetChangelog("unstable", "stable")
+	if err != nil {
+		t.Fatalf("Failed to get changelog: %v", err)
+	}
+	if len(log.Entries) != 1 {
+		t.Fatalf("Expected a single entry, got %d", len(log.Entries))
+	}
+	entry := log.Entries[0]
+	if entry.Source != "nano" || entry.FromRelease != 63 || entry.ToRelease != 75 {
+		t.Fatalf("Unexpected entry: %+v", entry)
+	}
+	if len(entry.Updates) == 0 || entry.Updates[0].Release != 75 {
+		t.Fatalf("Expected updates starting at 75, got: %+v", entry.Updates)
+	}
+	for _, update := range entry.Updates {
+		if update.Release <= 63 {
+			t.Fatalf("Update %d is already published in the target", update.Release)
+		}
+	}
+
+	md := log.Markdown()
+	if !strings.Contains(md, "## nano 2.7.1-63 → 2.8.5-75") || !strings.Contains(md, "- **2.8.5-75** Update to 2.8.5") {
+		t.Fatalf("Unexpected markdown:\n%s", md)
+	}
+
+	// Nothing new going the other way
+	log, err = manager.GetChangelog("stable", "unstable")
+	if err != nil {
+		t.Fatalf("Failed to get changelog: %v", err)
+	}
+	if len(log.Entries) != 0 {
+		t.Fatalf("Expected no entries, got: %+v", log.Entries)
+	}
+}
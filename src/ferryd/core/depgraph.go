@@ -0,0 +1,153 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getsolus/ferryd/src/libdb"
+)
+
+// A DependencyNode is a published package and the names it depends on at runtime
+type DependencyNode struct {
+	Name    string   // Package name
+	ID      string   // Published package ID
+	Version string   // Version of the published package
+	Release int      // Release of the published package
+	Depends []string // Runtime dependency names, sorted
+}
+
+// A DependencyGraph is the runtime dependency graph of the published packages
+// within a repository
+type DependencyGraph struct {
+	Repo    string           // Repository ID
+	Nodes   []DependencyNode // One node per package name, sorted
+	Missing []string         // Dependency names not published in the repository
+}
+
+// DependencyGraph will build the runtime dependency graph for all published
+// packages in the repository
+func (r *Repository) DependencyGraph(db libdb.Database, pool *Pool) (*DependencyGraph, error) {
+	entries, err := r.getEntries(db)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &DependencyGraph{
+		Repo: r.ID,
+	}
+	names := make(map[string]bool)
+
+	for _, entry := range entries {
+		poolEntry, err := pool.GetEntry(db, entry.Published)
+		if err != nil {
+			return nil, err
+		}
+		node := DependencyNode{
+			Name:    entry.Name,
+			ID:      entry.Published,
+			Version: poolEntry.Meta.GetVersion(),
+			Release: poolEntry.Meta.GetRelease(),
+		}
+		if poolEntry.Meta.RuntimeDependencies != nil {
+			seen := make(map[string]bool)
+			for _, dep := range *poolEntry.Meta.RuntimeDependencies {
+				if seen[dep.Name] {
+					continue
+				}
+				seen[dep.Name] = true
+				node.Depends = append(node.Depends, dep.Name)
+			}
+			sort.Strings(node.Depends)
+		}
+		names[entry.Name] = true
+		graph.Nodes = append(graph.Nodes, node)
+	}
+
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].Name < graph.Nodes[j].Name })
+
+	missing := make(map[string]bool)
+	for _, node := range graph.Nodes {
+		for _, dep := range node.Depends {
+			if !names[dep] && !missing[dep] {
+				missing[dep] = true
+				graph.Missing = append(graph.Missing, dep)
+			}
+		}
+	}
+	sort.Strings(graph.Missing)
+
+	return graph, nil
+}
+
+// ReverseDependencies will return the names of all packages depending on the
+// named package. When transitive is set, packages that depend on it indirectly
+// are included too.
+func (g *DependencyGraph) ReverseDependencies(name string, transitive bool) []string {
+	rdeps := make(map[string][]string)
+	for _, node := range g.Nodes {
+		for _, dep := range node.Depends {
+			rdeps[dep] = append(rdeps[dep], node.Name)
+		}
+	}
+
+	seen := map[string]bool{name: true}
+	queue := []string{name}
+	var ret []string
+
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		for _, rdep := range rdeps[next] {
+			if seen[rdep] {
+				continue
+			}
+			seen[rdep] = true
+			ret = append(ret, rdep)
+			if transitive {
+				queue = append(queue, rdep)
+			}
+		}
+	}
+
+	sort.Strings(ret)
+	return ret
+}
+
+// DOT will render the graph in the Graphviz DOT language. Dependencies that
+// are not published in the repository are drawn dashed.
+func (g *DependencyGraph) DOT() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "digraph %q {\n", g.Repo)
+	for _, node := range g.Nodes {
+		fmt.Fprintf(&sb, "\t%q [label=%q];\n", node.Name, fmt.Sprintf("%s\n%s-%d", node.Name, node.Version, node.Release))
+	}
+	for _, name := range g.Missing {
+		fmt.Fprintf(&sb, "\t%q [style=dashed];\n", name)
+	}
+	for _, node := range g.Nodes {
+		for _, dep := range node.Depends {
+			fmt.Fprintf(&sb, "\t%q -> %q;\n", node.Name, dep)
+		}
+	}
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
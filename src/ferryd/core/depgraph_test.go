@@ -0,0 +1,72 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDependencyGraph(t *testing.T) {
+	graph := &DependencyGraph{
+		Repo: "unstable",
+		Nodes: []DependencyNode{
+			{Name: "app", Depends: []string{"libfoo"}},
+			{Name: "libbar", Depends: []string{"libfoo"}},
+			{Name: "libfoo", Depends: []string{"glibc"}},
+			{Name: "tool", Depends: []string{"libbar"}},
+		},
+		Missing: []string{"glibc"},
+	}
+
+	if rdeps := graph.ReverseDependencies("libfoo", false); strings.Join(rdeps, " ") != "app libbar" {
+		t.Fatalf("Unexpected direct reverse dependencies: %v", rdeps)
+	}
+	if rdeps := graph.ReverseDependencies("libfoo", true); strings.Join(rdeps, " ") != "app libbar tool" {
+		t.Fatalf("Unexpected transitive reverse dependencies: %v", rdeps)
+	}
+	if rdeps := graph.ReverseDependencies("tool", true); len(rdeps) != 0 {
+		t.Fatalf("Nothing should depend on tool: %v", rdeps)
+	}
+
+	dot := graph.DOT()
+	if !strings.Contains(dot, "\"tool\" -> \"libbar\";") || !strings.Contains(dot, "\"glibc\" [style=dashed];") {
+		t.Fatalf("Unexpected DOT output:\n%s", dot)
+	}
+}
+
+func TestRepoDependencyGraph(t *testing.T) {
+	manager := initTestRepo(t, "unstable", snapshotTestPackage)
+	defer manager.Close()
+
+	graph, err := manager.GetDependencyGraph("unstable")
+	if err != nil {
+		t.Fatalf("Failed to build dependency graph: %v", err)
+	}
+	if len(graph.Nodes) != 1 || graph.Nodes[0].Name != "nano" {
+		t.Fatalf("Unexpected nodes: %+v", graph.Nodes)
+	}
+	if deps := strings.Join(graph.Nodes[0].Depends, " "); deps != "file glibc ncurses" {
+		t.Fatalf("Unexpected dependencies: %s", deps)
+	}
+	if missing := strings.Join(graph.Missing, " "); missing != "file glibc ncurses" {
+		t.Fatalf("Unexpected missing dependencies: %s", missing)
+	}
+	if rdeps := graph.ReverseDependencies("ncurses", false); len(rdeps) != 1 || rdeps[0] != "nano" {
+		t.Fatalf("Expected nano to depend on ncurses: %v", rdeps)
+	}
+}
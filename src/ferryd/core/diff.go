@@ -0,0 +1,116 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package core
+
+import (
+	"sort"
+
+	"github.com/getsolus/ferryd/src/libdb"
+)
+
+// A DiffPackage describes the published package for a name in one repository
+type DiffPackage struct {
+	Name    string // Package name
+	ID      string // Published package ID
+	Version string // Version of the published package
+	Release int    // Release of the published package
+}
+
+// A DiffChange describes a package name published with a different ID in each
+// repository
+type DiffChange struct {
+	Name   string      // Package name
+	Source DiffPackage // Published package in the source repository
+	Target DiffPackage // Published package in the target repository
+}
+
+// A RepoDiff is the comparison of two repositories by package name. The source
+// is typically the repository that would be pulled from, i.e. unstable.
+type RepoDiff struct {
+	Source     string        // Source repository ID
+	Target     string        // Target repository ID
+	OnlySource []DiffPackage // Names only present in the source
+	OnlyTarget []DiffPackage // Names only present in the target
+	Changed    []DiffChange  // Names published with a different ID
+}
+
+// publishedPackages will return the published package for every name in the repository
+func (r *Repository) publishedPackages(db libdb.Database, pool *Pool) (map[string]DiffPackage, error) {
+	entries, err := r.getEntries(db)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make(map[string]DiffPackage)
+	for _, entry := range entries {
+		poolEntry, err := pool.GetEntry(db, entry.Published)
+		if err != nil {
+			return nil, err
+		}
+		ret[entry.Name] = DiffPackage{
+			Name:    entry.Name,
+			ID:      entry.Published,
+			Version: poolEntry.Meta.GetVersion(),
+			Release: poolEntry.Meta.GetRelease(),
+		}
+	}
+	return ret, nil
+}
+
+// Diff will compare the published packages of this repository, as the source,
+// against those of the target.
+func (r *Repository) Diff(db libdb.Database, pool *Pool, target *Repository) (*RepoDiff, error) {
+	sourcePkgs, err := r.publishedPackages(db, pool)
+	if err != nil {
+		return nil, err
+	}
+	targetPkgs, err := target.publishedPackages(db, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &RepoDiff{
+		Source: r.ID,
+		Target: target.ID,
+	}
+
+	for name, sourcePkg := range sourcePkgs {
+		targetPkg, ok := targetPkgs[name]
+		if !ok {
+			diff.OnlySource = append(diff.OnlySource, sourcePkg)
+			continue
+		}
+		if sourcePkg.ID != targetPkg.ID {
+			diff.Changed = append(diff.Changed, DiffChange{
+				Name:   name,
+				Source: sourcePkg,
+				Target: targetPkg,
+			})
+		}
+	}
+	for name, targetPkg := range targetPkgs {
+		if _, ok := sourcePkgs[name]; !ok {
+			diff.OnlyTarget = append(diff.OnlyTarget, targetPkg)
+		}
+	}
+
+	sort.Slice(diff.OnlySource, func(i, j int) bool { return diff.OnlySource[i].Name < diff.OnlySource[j].Name })
+	sort.Slice(diff.OnlyTarget, func(i, j int) bool { return diff.OnlyTarget[i].Name < diff.OnlyTarget[j].Name })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Name < diff.Changed[j].Name })
+
+	return diff, nil
+}
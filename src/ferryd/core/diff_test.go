@@ -0,0 +1,56 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package core
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDiffRepos(t *testing.T) {
+	manager := initTestRepo(t, "unstable", integrityTestPackage, snapshotTestPackage)
+	defer manager.Close()
+
+	for _, id := range []string{"stable", "empty"} {
+		if err := manager.CreateRepo(context.Background(), id); err != nil {
+			t.Fatalf("Failed to create repository: %v", err)
+		}
+	}
+	if err := manager.AddPackages(context.Background(), "stable", []string{integrityTestPackage}, false); err != nil {
+		t.Fatalf("Failed to add packages: %v", err)
+	}
+
+	diff, err := manager.DiffRepos("unstable", "stable")
+	if err != nil {
+		t.Fatalf("Failed to diff repositories: %v", err)
+	}
+	if len(diff.OnlySource) != 0 || len(diff.OnlyTarget) != 0 || len(diff.Changed) != 1 {
+		t.Fatalf("Unexpected diff: %+v", diff)
+	}
+	change := diff.Changed[0]
+	if change.Source.Release != 75 || change.Target.Release != 63 || change.Source.Version != "2.8.5" {
+		t.Fatalf("Unexpected change: %+v", change)
+	}
+
+	diff, err = manager.DiffRepos("empty", "unstable")
+	if err != nil {
+		t.Fatalf("Failed to diff repositories: %v", err)
+	}
+	if len(diff.OnlyTarget) != 1 || diff.OnlyTarget[0].ID != snapshotTestID {
+		t.Fatalf("Unexpected diff: %+v", diff)
+	}
+}
@@ -0,0 +1,225 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/getsolus/ferryd/src/libdb"
+	"github.com/getsolus/ferryd/src/libeopkg"
+)
+
+// DatabaseBucketInstallable is where we store the last installability report
+// for each repository, keyed by repository ID
+const DatabaseBucketInstallable = "installable"
+
+// ErrNotInstallable is returned by Index when the install gate is enabled and
+// the repository has packages that cannot be installed
+var ErrNotInstallable = errors.New("repository has uninstallable packages")
+
+// InstallIssueKind identifies why a dependency cannot be satisfied
+type InstallIssueKind string
+
+const (
+	// IssueMissingDependency is reported when no package of that name is published
+	IssueMissingDependency InstallIssueKind = "MissingDependency"
+
+	// IssueObsoleteDependency is reported when the dependency is marked obsolete
+	// in the distribution.xml and won't be emitted in the index
+	IssueObsoleteDependency InstallIssueKind = "ObsoleteDependency"
+
+	// IssueUnsatisfiedDependency is reported when the published package doesn't
+	// meet the release or version constraints of the dependency
+	IssueUnsatisfiedDependency InstallIssueKind = "UnsatisfiedDependency"
+)
+
+// An InstallIssue is a single dependency that cannot be satisfied
+type InstallIssue struct {
+	Kind       InstallIssueKind // Class of problem
+	Package    string           // Name of the package with the dependency
+	ID         string           // Published ID of the package
+	Dependency string           // Name of the dependency
+	Detail     string           // Human readable explanation
+}
+
+// An InstallReport is the result of checking every published package in a
+// repository for unresolved dependencies
+type InstallReport struct {
+	Repo     string         // Repository ID
+	Started  time.Time      // When the check began
+	Finished time.Time      // When the check completed
+	Packages int            // How many published packages were checked
+	Issues   []InstallIssue // Every problem found
+}
+
+// checkDependency will determine if the published package satisfies the
+// constraints of the dependency, returning a reason when it does not
+func checkDependency(dep *libeopkg.Dependency, meta *libeopkg.MetaPackage) (bool, string) {
+	release := meta.GetRelease()
+	version := meta.GetVersion()
+
+	if dep.Release != 0 && release != dep.Release {
+		return false, fmt.Sprintf("requires release %d, found %d", dep.Release, release)
+	}
+	if dep.ReleaseFrom != 0 && release < dep.ReleaseFrom {
+		return false, fmt.Sprintf("requires release >= %d, found %d", dep.ReleaseFrom, release)
+	}
+	if dep.ReleaseTo != 0 && release > dep.ReleaseTo {
+		return false, fmt.Sprintf("requires release <= %d, found %d", dep.ReleaseTo, release)
+	}
+	if dep.Version != "" && libeopkg.CompareVersions(version, dep.Version) != 0 {
+		return false, fmt.Sprintf("requires version %s, found %s", dep.Version, version)
+	}
+	if dep.VersionFrom != "" && libeopkg.CompareVersions(version, dep.VersionFrom) < 0 {
+		return false, fmt.Sprintf("requires version >= %s, found %s", dep.VersionFrom, version)
+	}
+	if dep.VersionTo != "" && libeopkg.CompareVersions(version, dep.VersionTo) > 0 {
+		return false, fmt.Sprintf("requires version <= %s, found %s", dep.VersionTo, version)
+	}
+	return true, ""
+}
+
+// checkInstallable does the heavy lifting of CheckInstallable, and requires
+// that the indexMut is held and the distribution has been loaded.
+func (r *Repository) checkInstallable(ctx context.Context, db libdb.Database, pool *Pool) (*InstallReport, error) {
+	report := &InstallReport{
+		Repo:    r.ID,
+		Started: time.Now().UTC(),
+	}
+
+	entries, err := r.getEntries(db)
+	if err != nil {
+		return nil, err
+	}
+
+	// Obsolete packages are never emitted in the index, so can't satisfy anything
+	published := make(map[string]*PoolEntry)
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if r.dist != nil && r.dist.IsObsolete(entry.Name) {
+			continue
+		}
+		poolEntry, err := pool.GetEntry(db, entry.Published)
+		if err != nil {
+			return nil, err
+		}
+		published[entry.Name] = poolEntry
+	}
+
+	for name, poolEntry := range published {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		report.Packages++
+		if poolEntry.Meta.RuntimeDependencies == nil {
+			continue
+		}
+		for i := range *poolEntry.Meta.RuntimeDependencies {
+			dep := &(*poolEntry.Meta.RuntimeDependencies)[i]
+			issue := InstallIssue{
+				Package:    name,
+				ID:         poolEntry.Name,
+				Dependency: dep.Name,
+			}
+
+			target, ok := published[dep.Name]
+			switch {
+			case r.dist != nil && r.dist.IsObsolete(dep.Name):
+				issue.Kind = IssueObsoleteDependency
+				issue.Detail = "dependency is obsolete"
+			case !ok:
+				issue.Kind = IssueMissingDependency
+				issue.Detail = "dependency is not published"
+			default:
+				satisfied, reason := checkDependency(dep, target.Meta)
+				if satisfied {
+					continue
+				}
+				issue.Kind = IssueUnsatisfiedDependency
+				issue.Detail = reason
+			}
+			report.Issues = append(report.Issues, issue)
+		}
+	}
+
+	sort.Slice(report.Issues, func(i, j int) bool {
+		if report.Issues[i].Package != report.Issues[j].Package {
+			return report.Issues[i].Package < report.Issues[j].Package
+		}
+		return report.Issues[i].Dependency < report.Issues[j].Dependency
+	})
+
+	report.Finished = time.Now().UTC()
+	return report, nil
+}
+
+// CheckInstallable will validate the runtime dependencies of every published
+// package against the published set of the repository.
+func (r *Repository) CheckInstallable(ctx context.Context, db libdb.Database, pool *Pool) (*InstallReport, error) {
+	r.indexMut.Lock()
+	defer r.indexMut.Unlock()
+
+	if err := r.initDistribution(ctx); err != nil {
+		return nil, err
+	}
+
+	return r.checkInstallable(ctx, db, pool)
+}
+
+// EnableInstallGate will prevent Index from publishing a new index while the
+// repository has uninstallable packages.
+// This can be undone using DisableInstallGate.
+func (r *Repository) EnableInstallGate() error {
+	if err := os.WriteFile(r.installGateFile(), nil, 0o0644); err != nil {
+		return fmt.Errorf("enable install gate for repository %q: %w", r.ID, err)
+	}
+
+	return nil
+}
+
+// DisableInstallGate undoes EnableInstallGate.
+func (r *Repository) DisableInstallGate() error {
+	if err := os.Remove(r.installGateFile()); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("disable install gate for repository %q: %w", r.ID, err)
+	}
+
+	return nil
+}
+
+// IsInstallGated returns true if the install gate has been enabled using
+// EnableInstallGate.
+func (r *Repository) IsInstallGated() bool {
+	_, err := os.Stat(r.installGateFile())
+
+	return err == nil
+}
+
+func (r *Repository) installGateFile() string {
+	return filepath.Join(r.path, ".install-gate")
+}
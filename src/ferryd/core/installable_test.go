@@ -0,0 +1,99 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/getsolus/ferryd/src/libeopkg"
+)
+
+func TestCheckDependency(t *testing.T) {
+	meta := &libeopkg.MetaPackage{
+		History: []libeopkg.Update{{Release: 10, Version: "1.2.3"}},
+	}
+
+	tests := []struct {
+		dep  libeopkg.Dependency
+		want bool
+	}{
+		{libeopkg.Dependency{}, true},
+		{libeopkg.Dependency{ReleaseFrom: 10}, true},
+		{libeopkg.Dependency{ReleaseFrom: 11}, false},
+		{libeopkg.Dependency{ReleaseTo: 9}, false},
+		{libeopkg.Dependency{Release: 10}, true},
+		{libeopkg.Dependency{Release: 9}, false},
+		{libeopkg.Dependency{VersionFrom: "1.2"}, true},
+		{libeopkg.Dependency{VersionFrom: "1.10"}, false},
+		{libeopkg.Dependency{VersionTo: "1.2.3"}, true},
+		{libeopkg.Dependency{VersionFrom: "1.2.3rc1"}, true},
+		{libeopkg.Dependency{VersionTo: "1.2.3rc1"}, false},
+		{libeopkg.Dependency{Version: "1.2.4"}, false},
+	}
+
+	for _, test := range tests {
+		if got, reason := checkDependency(&test.dep, meta); got != test.want {
+			t.Fatalf("Dependency %+v: got %v (%s), expected %v", test.dep, got, reason, test.want)
+		}
+	}
+}
+
+func TestCheckInstallable(t *testing.T) {
+	manager := initTestRepo(t, "unstable", snapshotTestPackage)
+	defer manager.Close()
+
+	report, err := manager.CheckInstallable(context.Background(), "unstable")
+	if err != nil {
+		t.Fatalf("Failed to check installability: %v", err)
+	}
+	if report.Packages != 1 || len(report.Issues) != 3 {
+		t.Fatalf("Expected 3 issues for 1 package, got: %+v", report)
+	}
+	for _, issue := range report.Issues {
+		if issue.Kind != IssueMissingDependency || issue.Package != "nano" {
+			t.Fatalf("Unexpected issue: %+v", issue)
+		}
+	}
+
+	stored, err := manager.GetInstallReport("unstable")
+	if err != nil {
+		t.Fatalf("Failed to get stored report: %v", err)
+	}
+	if len(stored.Issues) != 3 {
+		t.Fatalf("Stored report doesn't match: %+v", stored)
+	}
+
+	// The gate must stop us publishing the broken index
+	if err = manager.SetInstallGate("unstable", true); err != nil {
+		t.Fatalf("Failed to enable install gate: %v", err)
+	}
+	if err = manager.Index(context.Background(), "unstable"); !errors.Is(err, ErrNotInstallable) {
+		t.Fatalf("Expected ErrNotInstallable, got: %v", err)
+	}
+	if err = manager.SetInstallGate("unstable", false); err != nil {
+		t.Fatalf("Failed to disable install gate: %v", err)
+	}
+	repo, err := manager.GetRepo("unstable")
+	if err != nil {
+		t.Fatalf("Failed to get repository: %v", err)
+	}
+	if repo.IsInstallGated() {
+		t.Fatalf("Install gate should be disabled")
+	}
+}
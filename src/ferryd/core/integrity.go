@@ -0,0 +1,187 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/getsolus/ferryd/src/libdb"
+)
+
+const (
+	// DatabaseBucketIntegrity is where we store the results of the last integrity check
+	DatabaseBucketIntegrity = "integrity"
+
+	// IntegrityReportKey is the key for the most recent IntegrityReport
+	IntegrityReportKey = "report"
+)
+
+// IntegrityIssueKind identifies the class of problem found during an integrity check
+type IntegrityIssueKind string
+
+const (
+	// IssueMissingPoolFile is reported when a pool entry has no file on disk
+	IssueMissingPoolFile IntegrityIssueKind = "MissingPoolFile"
+
+	// IssueHashMismatch is reported when a pool file no longer matches the stored sha1sum
+	IssueHashMismatch IntegrityIssueKind = "HashMismatch"
+
+	// IssueRefCountMismatch is reported when the stored refcount of a pool entry
+	// doesn't match the number of references held by repositories
+	IssueRefCountMismatch IntegrityIssueKind = "RefCountMismatch"
+
+	// IssueMissingPoolEntry is reported when a repository references an ID
+	// that is not present in the pool
+	IssueMissingPoolEntry IntegrityIssueKind = "MissingPoolEntry"
+
+	// IssuePublishedUnavailable is reported when the published ID of a repository
+	// entry is not listed in its available set
+	IssuePublishedUnavailable IntegrityIssueKind = "PublishedUnavailable"
+
+	// IssueMissingRepoFile is reported when a repository is missing the hardlink
+	// for one of its packages
+	IssueMissingRepoFile IntegrityIssueKind = "MissingRepoFile"
+
+	// IssueInvalidDelta is reported when a repository lists a delta that has
+	// no delta information in the pool
+	IssueInvalidDelta IntegrityIssueKind = "InvalidDelta"
+)
+
+// An IntegrityIssue is a single problem found during an integrity check
+type IntegrityIssue struct {
+	Kind   IntegrityIssueKind // Class of problem
+	Repo   string             // Affected repository, empty for pool issues
+	ID     string             // Affected package ID
+	Detail string             // Human readable explanation
+}
+
+// An IntegrityReport is the result of walking the pool and all repositories
+type IntegrityReport struct {
+	Started     time.Time        // When the check began
+	Finished    time.Time        // When the check completed
+	PoolEntries int              // How many pool entries were checked
+	RepoEntries int              // How many repository entries were checked
+	Issues      []IntegrityIssue // Every problem found
+}
+
+// addIssue is a convenience wrapper to record a new problem in the report
+func (i *IntegrityReport) addIssue(kind IntegrityIssueKind, repo, id, format string, args ...interface{}) {
+	i.Issues = append(i.Issues, IntegrityIssue{
+		Kind:   kind,
+		Repo:   repo,
+		ID:     id,
+		Detail: fmt.Sprintf(format, args...),
+	})
+}
+
+// checkIntegrity will examine every entry in the repository, recording any
+// problems in the report. Every ID referenced by the repository is counted
+// in refs so that the pool refcounts can be validated afterwards.
+func (r *Repository) checkIntegrity(ctx context.Context, db libdb.Database, pool *Pool, report *IntegrityReport, refs map[string]uint64) error {
+	rootBucket := db.Bucket([]byte(DatabaseBucketRepo)).Bucket([]byte(r.ID)).Bucket([]byte(DatabaseBucketPackage))
+
+	return rootBucket.ForEach(func(k, v []byte) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		entry := RepoEntry{}
+		if err := rootBucket.Decode(v, &entry); err != nil {
+			return err
+		}
+		report.RepoEntries++
+
+		published := false
+		for _, id := range entry.Available {
+			refs[id]++
+			if id == entry.Published {
+				published = true
+			}
+			r.checkPackageFile(db, pool, report, id)
+		}
+
+		if !published {
+			report.addIssue(IssuePublishedUnavailable, r.ID, entry.Published, "published package for '%s' is not available", entry.Name)
+		}
+
+		for _, id := range entry.Deltas {
+			refs[id]++
+			poolEntry := r.checkPackageFile(db, pool, report, id)
+			if poolEntry != nil && poolEntry.Delta == nil {
+				report.addIssue(IssueInvalidDelta, r.ID, id, "delta for '%s' has no delta information", entry.Name)
+			}
+		}
+
+		return nil
+	})
+}
+
+// checkPackageFile ensures the pool entry for id exists and that we have the
+// file linked into our own tree, returning the pool entry if one was found.
+func (r *Repository) checkPackageFile(db libdb.Database, pool *Pool, report *IntegrityReport, id string) *PoolEntry {
+	poolEntry, err := pool.GetEntry(db, id)
+	if err != nil {
+		report.addIssue(IssueMissingPoolEntry, r.ID, id, "no pool entry: %v", err)
+		return nil
+	}
+
+	pkgPath := filepath.Join(r.path, poolEntry.Meta.GetPathComponent(), id)
+	if !PathExists(pkgPath) {
+		report.addIssue(IssueMissingRepoFile, r.ID, id, "missing file %s", pkgPath)
+	}
+
+	return poolEntry
+}
+
+// checkIntegrity will verify every pool entry has a valid file on disk, and
+// that the refcount matches the references counted in refs.
+func (p *Pool) checkIntegrity(ctx context.Context, db libdb.Database, report *IntegrityReport, refs map[string]uint64) error {
+	entries, err := p.GetPoolItems(db)
+	if err != nil {
+		return err
+	}
+
+	for i, entry := range entries {
+		if err := stepProgress(ctx, "Check pool", i, len(entries)); err != nil {
+			return err
+		}
+		report.PoolEntries++
+
+		if entry.RefCount != refs[entry.Name] {
+			report.addIssue(IssueRefCountMismatch, "", entry.Name, "stored refcount %d, referenced %d times", entry.RefCount, refs[entry.Name])
+		}
+
+		pkgPath := p.GetMetaPoolPath(entry.Name, entry.Meta)
+		if !PathExists(pkgPath) {
+			report.addIssue(IssueMissingPoolFile, "", entry.Name, "missing file %s", pkgPath)
+			continue
+		}
+
+		sha, err := FileSha1sum(pkgPath)
+		if err != nil {
+			report.addIssue(IssueHashMismatch, "", entry.Name, "cannot hash %s: %v", pkgPath, err)
+			continue
+		}
+		if sha != entry.Meta.PackageHash {
+			report.addIssue(IssueHashMismatch, "", entry.Name, "expected sha1 %s, found %s", entry.Meta.PackageHash, sha)
+		}
+	}
+
+	return nil
+}
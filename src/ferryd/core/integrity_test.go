@@ -0,0 +1,194 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const (
+	integrityTestPackage = "../../libeopkg/testdata/nano-2.7.1-63-1-x86_64.eopkg"
+	integrityTestID      = "nano-2.7.1-63-1-x86_64.eopkg"
+)
+
+// initTestRepo will construct a manager with a single repository containing
+// the given packages
+func initTestRepo(t *testing.T, repoID string, pkgs ...string) *Manager {
+	manager, err := NewManager(initTestArea(t))
+	if err != nil {
+		t.Fatalf("Failed to initialise a new manager: %v", err)
+	}
+	if err = manager.CreateRepo(context.Background(), repoID); err != nil {
+		manager.Close()
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	if err = manager.AddPackages(context.Background(), repoID, pkgs, false); err != nil {
+		manager.Close()
+		t.Fatalf("Failed to add packages: %v", err)
+	}
+	return manager
+}
+
+func TestCheckIntegrity(t *testing.T) {
+	manager := initTestRepo(t, "unstable", integrityTestPackage)
+	defer manager.Close()
+
+	report, err := manager.CheckIntegrity(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to check integrity: %v", err)
+	}
+	if len(report.Issues) != 0 {
+		t.Fatalf("Expected a clean report, got: %v", report.Issues)
+	}
+	if report.PoolEntries != 1 || report.RepoEntries != 1 {
+		t.Fatalf("Invalid entry counts: %d pool, %d repo", report.PoolEntries, report.RepoEntries)
+	}
+
+	// Break the repository hardlink
+	repo, err := manager.GetRepo("unstable")
+	if err != nil {
+		t.Fatalf("Failed to get repository: %v", err)
+	}
+	if err = os.Remove(filepath.Join(repo.path, "n", "nano", integrityTestID)); err != nil {
+		t.Fatalf("Failed to remove repository file: %v", err)
+	}
+
+	report, err = manager.GetIntegrityReport()
+	if err != nil {
+		t.Fatalf("Failed to get stored report: %v", err)
+	}
+	if len(report.Issues) != 0 {
+		t.Fatalf("Stored report should not have changed: %v", report.Issues)
+	}
+
+	report, err = manager.CheckIntegrity(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to check integrity: %v", err)
+	}
+	if len(report.Issues) != 1 || report.Issues[0].Kind != IssueMissingRepoFile {
+		t.Fatalf("Expected a missing repository file, got: %v", report.Issues)
+	}
+
+	// A cancelled check stops without replacing the stored report
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = manager.CheckIntegrity(ctx); err != context.Canceled {
+		t.Fatalf("Expected the check to be cancelled, got %v", err)
+	}
+	if _, err = manager.RepairIntegrity(ctx); err != context.Canceled {
+		t.Fatalf("Expected the repair to be cancelled, got %v", err)
+	}
+	report, err = manager.GetIntegrityReport()
+	if err != nil || len(report.Issues) != 1 {
+		t.Fatalf("Stored report should not have changed: %v (%v)", report, err)
+	}
+}
+
+func TestRepairIntegrity(t *testing.T) {
+	manager := initTestRepo(t, "unstable", integrityTestPackage)
+	defer manager.Close()
+
+	repo, err := manager.GetRepo("unstable")
+	if err != nil {
+		t.Fatalf("Failed to get repository: %v", err)
+	}
+
+	// Break the hardlink, the refcount, and leave an orphan in the pool
+	if err = os.Remove(filepath.Join(repo.path, "n", "nano", integrityTestID)); err != nil {
+		t.Fatalf("Failed to remove repository file: %v", err)
+	}
+	entry, err := manager.pool.GetEntry(manager.db, integrityTestID)
+	if err != nil {
+		t.Fatalf("Failed to get pool entry: %v", err)
+	}
+	entry.RefCount = 5
+	if err = manager.pool.putEntry(manager.db, entry); err != nil {
+		t.Fatalf("Failed to store pool entry: %v", err)
+	}
+	orphan := filepath.Join(manager.pool.poolDir, "o", "orphan", "orphan-1-1-1-x86_64.eopkg")
+	if err = os.MkdirAll(filepath.Dir(orphan), 00755); err != nil {
+		t.Fatalf("Failed to create orphan directory: %v", err)
+	}
+	if err = os.WriteFile(orphan, []byte("orphan"), 00644); err != nil {
+		t.Fatalf("Failed to create orphan: %v", err)
+	}
+
+	report, err := manager.RepairIntegrity(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to repair integrity: %v", err)
+	}
+	if len(report.Relinked) != 1 || len(report.RefCounts) != 1 || len(report.Quarantined) != 1 {
+		t.Fatalf("Unexpected repair actions: %+v", report)
+	}
+	if PathExists(filepath.Dir(orphan)) {
+		t.Fatalf("Orphan directory should have been removed")
+	}
+	if !PathExists(filepath.Join(manager.pool.quarantineDir, "o", "orphan", "orphan-1-1-1-x86_64.eopkg")) {
+		t.Fatalf("Orphan should have been quarantined")
+	}
+
+	check, err := manager.CheckIntegrity(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to check integrity: %v", err)
+	}
+	if len(check.Issues) != 0 {
+		t.Fatalf("Expected a clean report after repair, got: %v", check.Issues)
+	}
+}
+
+func TestRepairConcurrentAdd(t *testing.T) {
+	manager := initTestRepo(t, "unstable")
+	defer manager.Close()
+
+	// Add a package as the pool is about to be walked, giving it a chance to
+	// finish
+	added := make(chan error, 1)
+	t.Cleanup(func() { repairWalkHook = nil })
+	repairWalkHook = func() {
+		go func() {
+			added <- manager.AddPackages(context.Background(), "unstable", []string{integrityTestPackage}, false)
+		}()
+		select {
+		case err := <-added:
+			added <- err
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	report, err := manager.RepairIntegrity(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to repair integrity: %v", err)
+	}
+	if len(report.Quarantined) != 0 || len(report.Purged) != 0 {
+		t.Fatalf("Concurrently added package must be left alone: %+v", report)
+	}
+	if err = <-added; err != nil {
+		t.Fatalf("Failed to add package: %v", err)
+	}
+
+	check, err := manager.CheckIntegrity(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to check integrity: %v", err)
+	}
+	if check.PoolEntries != 1 || len(check.Issues) != 0 {
+		t.Fatalf("Expected a clean report with the new package, got: %+v", check)
+	}
+}
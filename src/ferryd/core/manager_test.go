@@ -17,8 +17,12 @@
 package core
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 )
 
@@ -45,3 +49,91 @@ func TestManagerBasic(t *testing.T) {
 	}
 	manager.Close()
 }
+
+func TestParallelRepos(t *testing.T) {
+	manager := initTestRepo(t, "unstable", integrityTestPackage)
+	defer manager.Close()
+
+	// parallel runs f for each of n repositories at once
+	parallel := func(n int, f func(id string) error) {
+		var wg sync.WaitGroup
+		errs := make(chan error, n)
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(id string) {
+				defer wg.Done()
+				errs <- f(id)
+			}(fmt.Sprintf("clone%d", i))
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			if err != nil {
+				t.Fatalf("Parallel operation failed: %v", err)
+			}
+		}
+	}
+
+	parallel(8, func(id string) error {
+		return manager.CloneRepo(context.Background(), "unstable", id, false)
+	})
+	parallel(8, func(id string) error {
+		return manager.CreateSnapshot(id, "before")
+	})
+	parallel(4, func(id string) error {
+		return manager.DeleteRepo(context.Background(), id)
+	})
+
+	entry, err := manager.pool.GetEntry(manager.db, integrityTestID)
+	if err != nil {
+		t.Fatalf("Failed to get pool entry: %v", err)
+	}
+	// unstable, 4 remaining clones and their snapshots
+	if entry.RefCount != 9 {
+		t.Fatalf("Expected refcount of 9, got %d", entry.RefCount)
+	}
+
+	report, err := manager.CheckIntegrity(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to check integrity: %v", err)
+	}
+	if len(report.Issues) != 0 {
+		t.Fatalf("Expected a clean report, got: %v", report.Issues)
+	}
+}
+
+func TestCancelledOperations(t *testing.T) {
+	manager := initTestRepo(t, "unstable", integrityTestPackage)
+	defer manager.Close()
+
+	if err := manager.CreateRepo(context.Background(), "stable"); err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	if err := manager.CreateSnapshot("unstable", "before"); err != nil {
+		t.Fatalf("Failed to create snapshot: %v", err)
+	}
+	if err := manager.AddPackages(context.Background(), "unstable", []string{snapshotTestPackage}, false); err != nil {
+		t.Fatalf("Failed to add package: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := manager.PullRepo(ctx, "unstable", "stable", nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected a cancelled pull, got: %v", err)
+	}
+	if pkgs, _ := manager.GetPackages("stable", "nano"); len(pkgs) != 0 {
+		t.Fatalf("Cancelled pull should not have pulled anything")
+	}
+
+	if err := manager.RestoreSnapshot(ctx, "unstable", "before"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected a cancelled restore, got: %v", err)
+	}
+	if pkgs, _ := manager.GetPackages("unstable", "nano"); len(pkgs) != 2 {
+		t.Fatalf("Cancelled restore should not have changed the repository, got %d packages", len(pkgs))
+	}
+
+	if err := manager.TrimPackages(ctx, "unstable", 1); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected a cancelled trim, got: %v", err)
+	}
+}
@@ -0,0 +1,332 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/getsolus/ferryd/src/libdb"
+)
+
+// A PublishedChange records the published tip of a package name moving
+type PublishedChange struct {
+	Name string // Package name
+	From string // Previous published ID, empty if the name is new
+	To   string // New published ID, empty if the name is removed
+}
+
+// A ChangePlan describes exactly what an operation would do to a repository,
+// without touching the database or the disk.
+type ChangePlan struct {
+	Repo      string            // Repository that would be modified
+	Ref       []string          // Package IDs that would be added
+	Unref     []string          // Package IDs that would be removed
+	Published []PublishedChange // Changes to the published tips
+	Deltas    []string          // Delta IDs that would be dropped
+	Skipped   []string          // Package names left alone due to conflicts
+}
+
+// changePlanner simulates RefPackage and UnrefPackage against in-memory
+// copies of the repository entries
+type changePlanner struct {
+	db       libdb.Database
+	pool     *Pool
+	repo     *Repository
+	plan     *ChangePlan
+	entries  map[string]*RepoEntry // Simulated entries, nil once removed
+	original map[string]string     // Published IDs before any change
+}
+
+// newPlanner will return a planner for changes to this repository
+func (r *Repository) newPlanner(db libdb.Database, pool *Pool) *changePlanner {
+	return &changePlanner{
+		db:       db,
+		pool:     pool,
+		repo:     r,
+		plan:     &ChangePlan{Repo: r.ID},
+		entries:  make(map[string]*RepoEntry),
+		original: make(map[string]string),
+	}
+}
+
+// entry will return the simulated entry for name, or nil if there is none
+func (c *changePlanner) entry(name string) *RepoEntry {
+	if entry, ok := c.entries[name]; ok {
+		return entry
+	}
+	entry, err := c.repo.GetEntry(c.db, name)
+	if err != nil {
+		c.entries[name] = nil
+		c.original[name] = ""
+		return nil
+	}
+	// Never alias the decoded slices
+	entry.Available = append([]string{}, entry.Available...)
+	entry.Deltas = append([]string{}, entry.Deltas...)
+	c.entries[name] = entry
+	c.original[name] = entry.Published
+	return entry
+}
+
+// ref mirrors the behaviour of RefPackage
+func (c *changePlanner) ref(id string) error {
+	poolEntry, err := c.pool.GetEntry(c.db, id)
+	if err != nil {
+		return err
+	}
+
+	name := poolEntry.Meta.Name
+	entry := c.entry(name)
+	if entry == nil {
+		entry = &RepoEntry{
+			SchemaVersion: RepoSchemaVersion,
+			Name:          name,
+			Published:     id,
+		}
+		c.entries[name] = entry
+	} else {
+		for _, avail := range entry.Available {
+			if avail == id {
+				return nil
+			}
+		}
+		published, err := c.pool.GetEntry(c.db, entry.Published)
+		if err != nil || poolEntry.Meta.GetRelease() > published.Meta.GetRelease() {
+			entry.Published = id
+		}
+	}
+
+	entry.Available = append(entry.Available, id)
+	sort.Strings(entry.Available)
+	c.plan.Ref = append(c.plan.Ref, id)
+	return nil
+}
+
+// unref mirrors the behaviour of UnrefPackage
+func (c *changePlanner) unref(id string) error {
+	poolEntry, err := c.pool.GetEntry(c.db, id)
+	if err != nil {
+		return err
+	}
+
+	entry := c.entry(poolEntry.Meta.Name)
+	if entry == nil {
+		return fmt.Errorf("package '%s' is not in repository '%s'", id, c.repo.ID)
+	}
+
+	var remainDeltas []string
+	for _, deltaID := range entry.Deltas {
+		pkgDelta, err := c.pool.GetEntry(c.db, deltaID)
+		if err != nil {
+			return err
+		}
+		if pkgDelta.Delta.FromID == id || pkgDelta.Delta.ToID == id {
+			c.plan.Deltas = append(c.plan.Deltas, deltaID)
+			continue
+		}
+		remainDeltas = append(remainDeltas, deltaID)
+	}
+	entry.Deltas = remainDeltas
+
+	newHighest := 0
+	var newHighestID string
+	var remainAvailable []string
+	for _, avail := range entry.Available {
+		if avail == id {
+			continue
+		}
+		availEntry, err := c.pool.GetEntry(c.db, avail)
+		if err != nil {
+			return err
+		}
+		if rel := availEntry.Meta.GetRelease(); rel > newHighest {
+			newHighest = rel
+			newHighestID = avail
+		}
+		remainAvailable = append(remainAvailable, avail)
+	}
+	entry.Available = remainAvailable
+	entry.Published = newHighestID
+
+	if len(entry.Available) < 1 {
+		c.entries[entry.Name] = nil
+	}
+
+	c.plan.Unref = append(c.plan.Unref, id)
+	return nil
+}
+
+// finish will compute the published changes and return the final plan
+func (c *changePlanner) finish() *ChangePlan {
+	for name, from := range c.original {
+		to := ""
+		if entry := c.entries[name]; entry != nil {
+			to = entry.Published
+		}
+		if to == from {
+			continue
+		}
+		c.plan.Published = append(c.plan.Published, PublishedChange{
+			Name: name,
+			From: from,
+			To:   to,
+		})
+	}
+	sort.Slice(c.plan.Published, func(i, j int) bool {
+		return c.plan.Published[i].Name < c.plan.Published[j].Name
+	})
+	return c.plan
+}
+
+// PlanPull will return the plan for PullFrom without applying it
+func (r *Repository) PlanPull(db libdb.Database, pool *Pool, sourceRepo *Repository, opts *PullOptions) (*ChangePlan, error) {
+	if err := r.checkWrite(); err != nil {
+		return nil, err
+	}
+
+	set, err := r.pullChanges(db, pool, sourceRepo, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	planner := r.newPlanner(db, pool)
+	for _, id := range set.unrefIDs {
+		if err = planner.unref(id); err != nil {
+			return nil, err
+		}
+	}
+	for _, id := range set.copyIDs {
+		if err = planner.ref(id); err != nil {
+			return nil, err
+		}
+	}
+
+	// Deltas are carried for the new tips too
+	for _, id := range set.copyIDs {
+		poolEntry, err := pool.GetEntry(db, id)
+		if err != nil {
+			return nil, err
+		}
+		entry := planner.entry(poolEntry.Meta.Name)
+		if entry == nil || entry.Published != id {
+			continue
+		}
+		carried, _, err := carryDeltas(db, pool, entry, id)
+		if err != nil {
+			return nil, err
+		}
+		planner.plan.Ref = append(planner.plan.Ref, carried...)
+	}
+
+	for _, conflict := range set.skipped {
+		planner.plan.Skipped = append(planner.plan.Skipped, conflict.Name)
+	}
+
+	return planner.finish(), nil
+}
+
+// PlanRemoveSource will return the plan for RemoveSource without applying it
+func (r *Repository) PlanRemoveSource(db libdb.Database, pool *Pool, sourceID string, release int) (*ChangePlan, error) {
+	if err := r.checkWrite(); err != nil {
+		return nil, err
+	}
+
+	deleteIDs, err := r.sourceIDs(db, pool, sourceID, release)
+	if err != nil {
+		return nil, err
+	}
+	if len(deleteIDs) == 0 {
+		return nil, errors.New("no matching sources found")
+	}
+
+	planner := r.newPlanner(db, pool)
+	for _, id := range deleteIDs {
+		if err = planner.unref(id); err != nil {
+			return nil, err
+		}
+	}
+	return planner.finish(), nil
+}
+
+// PlanCopySource will return the plan for CopySourceFrom without applying it
+func (r *Repository) PlanCopySource(db libdb.Database, pool *Pool, sourceRepo *Repository, sourceID string, release int) (*ChangePlan, error) {
+	if err := r.checkWrite(); err != nil {
+		return nil, err
+	}
+
+	copyIDs, err := sourceRepo.sourceIDs(db, pool, sourceID, release)
+	if err != nil {
+		return nil, err
+	}
+	if len(copyIDs) == 0 {
+		return nil, errors.New("no matching sources found")
+	}
+
+	planner := r.newPlanner(db, pool)
+	for _, id := range copyIDs {
+		if err = planner.ref(id); err != nil {
+			return nil, err
+		}
+	}
+	return planner.finish(), nil
+}
+
+// PlanTrimObsolete will return the plan for TrimObsolete without applying it
+func (r *Repository) PlanTrimObsolete(ctx context.Context, db libdb.Database, pool *Pool) (*ChangePlan, error) {
+	r.indexMut.Lock()
+	defer r.indexMut.Unlock()
+
+	if err := r.checkWrite(); err != nil {
+		return nil, err
+	}
+
+	removalIDs, err := r.obsoleteIDs(ctx, db, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	planner := r.newPlanner(db, pool)
+	for _, id := range removalIDs {
+		if err = planner.unref(id); err != nil {
+			return nil, err
+		}
+	}
+	return planner.finish(), nil
+}
+
+// PlanTrimPackages will return the plan for TrimPackages without applying it
+func (r *Repository) PlanTrimPackages(db libdb.Database, pool *Pool, maxKeep int) (*ChangePlan, error) {
+	if err := r.checkWrite(); err != nil {
+		return nil, err
+	}
+
+	removalIDs, err := r.trimIDs(db, pool, maxKeep)
+	if err != nil {
+		return nil, err
+	}
+
+	planner := r.newPlanner(db, pool)
+	for _, id := range removalIDs {
+		if err = planner.unref(id); err != nil {
+			return nil, err
+		}
+	}
+	return planner.finish(), nil
+}
@@ -0,0 +1,78 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package core
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestPlanChanges(t *testing.T) {
+	manager := initTestRepo(t, "unstable", integrityTestPackage, snapshotTestPackage)
+	defer manager.Close()
+
+	plan, err := manager.PlanTrimPackages("unstable", 1)
+	if err != nil {
+		t.Fatalf("Failed to plan trim: %v", err)
+	}
+	if !reflect.DeepEqual(plan.Unref, []string{integrityTestID}) || len(plan.Published) != 0 {
+		t.Fatalf("Unexpected trim plan: %+v", plan)
+	}
+
+	plan, err = manager.PlanRemoveSource("unstable", "nano", 75)
+	if err != nil {
+		t.Fatalf("Failed to plan source removal: %v", err)
+	}
+	expected := []PublishedChange{{Name: "nano", From: snapshotTestID, To: integrityTestID}}
+	if !reflect.DeepEqual(plan.Unref, []string{snapshotTestID}) || !reflect.DeepEqual(plan.Published, expected) {
+		t.Fatalf("Unexpected removal plan: %+v", plan)
+	}
+
+	// Nothing must have changed
+	pkgs, err := manager.GetPackages("unstable", "nano")
+	if err != nil {
+		t.Fatalf("Failed to get packages: %v", err)
+	}
+	if len(pkgs) != 2 {
+		t.Fatalf("Planning modified the repository, got %d packages", len(pkgs))
+	}
+
+	if err = manager.CreateRepo(context.Background(), "stable"); err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	plan, err = manager.PlanPullRepo("unstable", "stable", nil)
+	if err != nil {
+		t.Fatalf("Failed to plan pull: %v", err)
+	}
+	expected = []PublishedChange{{Name: "nano", To: snapshotTestID}}
+	if !reflect.DeepEqual(plan.Ref, []string{snapshotTestID}) || !reflect.DeepEqual(plan.Published, expected) {
+		t.Fatalf("Unexpected pull plan: %+v", plan)
+	}
+
+	// The real thing must match the plan
+	if _, err = manager.PullRepo(context.Background(), "unstable", "stable", nil); err != nil {
+		t.Fatalf("Failed to pull: %v", err)
+	}
+	pkgs, err = manager.GetPackages("stable", "nano")
+	if err != nil {
+		t.Fatalf("Failed to get packages: %v", err)
+	}
+	if len(pkgs) != 1 || pkgs[0].GetID() != snapshotTestID {
+		t.Fatalf("Pull did not match the plan")
+	}
+}
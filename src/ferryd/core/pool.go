@@ -17,9 +17,12 @@
 package core
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 
 	log "github.com/sirupsen/logrus"
 
@@ -37,10 +40,17 @@ const (
 	// PoolPathComponent is the storage directory for all of our main files
 	PoolPathComponent = "pool"
 
+	// QuarantinePathComponent is where we move pool files that have no database record
+	QuarantinePathComponent = "quarantine"
+
 	// PoolSchemaVersion is the current schema version for a PoolEntry
 	PoolSchemaVersion = "1.0"
 )
 
+// ErrPackageConflict is returned when a package ID already exists within the
+// pool, but the incoming file has different content.
+var ErrPackageConflict = errors.New("package ID already exists in the pool with different content")
+
 // DeltaInformation is included in pool entries if they're actually a delta
 // package and not a normal package
 type DeltaInformation struct {
@@ -71,17 +81,23 @@ type PoolEntry struct {
 	RefCount      uint64                // How many instances of this file exist right now
 	Meta          *libeopkg.MetaPackage // The eopkg metadata
 	Delta         *DeltaInformation     // May actually be nil if not a delta
+	Sha256        string                // sha256sum of the file, empty for older entries
 }
 
 // A Pool is used to manage and deduplicate resources between multiple resources,
 // and represents the real backing store for referenced eopkg files.
 type Pool struct {
-	poolDir string // Storage area
+	poolDir       string // Storage area
+	quarantineDir string // Where orphaned files are moved during repair
+
+	refMut *sync.Mutex // Repositories are changed in parallel, serialise refcounts
 }
 
 // Init will create our initial working paths and DB bucket
 func (p *Pool) Init(ctx *Context, db libdb.Database) error {
 	p.poolDir = filepath.Join(ctx.BaseDir, PoolPathComponent)
+	p.quarantineDir = filepath.Join(ctx.BaseDir, QuarantinePathComponent)
+	p.refMut = &sync.Mutex{}
 	return os.MkdirAll(p.poolDir, 00755)
 }
 
@@ -159,8 +175,14 @@ func (p *Pool) GetPackagePoolPath(pkg *libeopkg.Package) string {
 // information too. Note that a delta package is still a package in its own
 // right, its just installed and handled differently (lacking files, etc.)
 func (p *Pool) AddDelta(db libdb.Database, pkg *libeopkg.Package, mapping *DeltaInformation, copyDisk bool) (*PoolEntry, error) {
+	p.refMut.Lock()
+	defer p.refMut.Unlock()
+
 	// Check if this is just a simple case of bumping the refcount
 	if entry, err := p.GetEntry(db, pkg.ID); err == nil {
+		if err = p.verifyContent(entry, pkg.Path); err != nil {
+			return nil, err
+		}
 		entry.RefCount++
 		return entry, p.putEntry(db, entry)
 	}
@@ -183,10 +205,13 @@ func (p *Pool) AddDelta(db libdb.Database, pkg *libeopkg.Package, mapping *Delta
 }
 
 // addPackageInternal used by both AddDelta and AddPackage for the main bulk of
-// the work
+// the work. The caller must hold refMut.
 func (p *Pool) addPackageInternal(db libdb.Database, pkg *libeopkg.Package, copyDisk bool, delta *DeltaInformation) (*PoolEntry, error) {
 	// Check if this is just a simple case of bumping the refcount
 	if entry, err := p.GetEntry(db, pkg.ID); err == nil {
+		if err = p.verifyContent(entry, pkg.Path); err != nil {
+			return nil, err
+		}
 		entry.RefCount++
 		return entry, p.putEntry(db, entry)
 	}
@@ -211,6 +236,10 @@ func (p *Pool) addPackageInternal(db libdb.Database, pkg *libeopkg.Package, copy
 	if err != nil {
 		return nil, err
 	}
+	sha256, err := FileSha256sum(pkg.Path)
+	if err != nil {
+		return nil, err
+	}
 
 	// Store immediately useful index bits here
 	pkg.Meta.Package.PackageHash = sha
@@ -223,6 +252,7 @@ func (p *Pool) addPackageInternal(db libdb.Database, pkg *libeopkg.Package, copy
 		RefCount:      1,
 		Meta:          &pkg.Meta.Package,
 		Delta:         delta, // Might be nil, thats OK
+		Sha256:        sha256,
 	}
 
 	if err := p.putEntry(db, entry); err != nil {
@@ -235,16 +265,80 @@ func (p *Pool) addPackageInternal(db libdb.Database, pkg *libeopkg.Package, copy
 	return entry, nil
 }
 
+// verifyContent ensures the file at path has the same content as the existing
+// pool entry, so that an ID can never silently point at different bytes.
+func (p *Pool) verifyContent(entry *PoolEntry, path string) error {
+	sha, err := FileSha1sum(path)
+	if err != nil {
+		return err
+	}
+	if sha != entry.Meta.PackageHash {
+		return fmt.Errorf("%w: %s has sha1 %s, pool has %s", ErrPackageConflict, entry.Name, sha, entry.Meta.PackageHash)
+	}
+
+	// Older entries only know the sha1sum
+	if entry.Sha256 == "" {
+		return nil
+	}
+	sha256, err := FileSha256sum(path)
+	if err != nil {
+		return err
+	}
+	if sha256 != entry.Sha256 {
+		return fmt.Errorf("%w: %s has sha256 %s, pool has %s", ErrPackageConflict, entry.Name, sha256, entry.Sha256)
+	}
+	return nil
+}
+
+// CheckFile will return ErrPackageConflict if the pool already has an entry
+// for the file's ID with different content. Unknown IDs are always accepted.
+func (p *Pool) CheckFile(db libdb.Database, path string) error {
+	id := filepath.Base(path)
+	has, err := db.Bucket([]byte(DatabaseBucketPool)).HasObject([]byte(id))
+	if err != nil {
+		return err
+	}
+	if !has {
+		return nil
+	}
+	entry, err := p.GetEntry(db, id)
+	if err != nil {
+		return err
+	}
+	return p.verifyContent(entry, path)
+}
+
 // AddPackage will determine where the new eopkg goes, and whether we need
 // to actually push it on disk, or simply bump the ref count. Any file
 // passed to us is believed to be under our ownership now.
 func (p *Pool) AddPackage(db libdb.Database, pkg *libeopkg.Package, copy bool) (*PoolEntry, error) {
+	p.refMut.Lock()
+	defer p.refMut.Unlock()
+
 	return p.addPackageInternal(db, pkg, copy, nil)
 }
 
+// update will run f as a batched update while holding the reference lock.
+// Reads within a batch don't see its own writes, so any batch changing
+// refcounts must go through here, using refEntry and unrefEntry within f.
+func (p *Pool) update(db libdb.Database, f libdb.WriterFunc) error {
+	p.refMut.Lock()
+	defer p.refMut.Unlock()
+
+	return db.Update(f)
+}
+
 // RefEntry will include the given eopkg if it doesn't yet exist, otherwise
 // it will simply increase the ref count by 1.
 func (p *Pool) RefEntry(db libdb.Database, id string) error {
+	p.refMut.Lock()
+	defer p.refMut.Unlock()
+
+	return p.refEntry(db, id)
+}
+
+// refEntry is RefEntry for callers already holding refMut
+func (p *Pool) refEntry(db libdb.Database, id string) error {
 	entry, err := p.GetEntry(db, id)
 	if err != nil {
 		return err
@@ -256,7 +350,15 @@ func (p *Pool) RefEntry(db libdb.Database, id string) error {
 // UnrefEntry will unref a given ID from the repository.
 // Should the refcount hit 0, the package will then be removed from the pool
 // storage.
-func (p *Pool) UnrefEntry(db libdb.Database, id string) error {
+func (p *Pool) UnrefEntry(ctx context.Context, db libdb.Database, id string) error {
+	p.refMut.Lock()
+	defer p.refMut.Unlock()
+
+	return p.unrefEntry(ctx, db, id)
+}
+
+// unrefEntry is UnrefEntry for callers already holding refMut
+func (p *Pool) unrefEntry(ctx context.Context, db libdb.Database, id string) error {
 	entry, err := p.GetEntry(db, id)
 	if err != nil {
 		return err
@@ -267,9 +369,15 @@ func (p *Pool) UnrefEntry(db libdb.Database, id string) error {
 	}
 
 	// RefCount is 0 so we now need to delete this entry
-	pkgPath := filepath.Join(p.poolDir, entry.Meta.GetPathComponent(), id)
+	return p.removeEntry(ctx, db, entry)
+}
+
+// removeEntry will remove the pool file for the entry from disk, and then
+// delete the entry itself from the DB
+func (p *Pool) removeEntry(ctx context.Context, db libdb.Database, entry *PoolEntry) error {
+	pkgPath := p.GetMetaPoolPath(entry.Name, entry.Meta)
 	if err := os.Remove(pkgPath); err != nil {
-		log.WithFields(log.Fields{
+		log.WithContext(ctx).WithFields(log.Fields{
 			"path":  pkgPath,
 			"error": err,
 		}).Warning("Failed to remove package")
@@ -277,7 +385,7 @@ func (p *Pool) UnrefEntry(db libdb.Database, id string) error {
 
 	// Warn if unable to delete parents
 	if err := RemovePackageParents(pkgPath); err != nil {
-		log.WithFields(log.Fields{
+		log.WithContext(ctx).WithFields(log.Fields{
 			"path":  pkgPath,
 			"error": err,
 		}).Warning("Failed to remove package parents")
@@ -285,7 +393,7 @@ func (p *Pool) UnrefEntry(db libdb.Database, id string) error {
 
 	// Now remove from DB
 	b := db.Bucket([]byte(DatabaseBucketPool))
-	return b.DeleteObject([]byte(id))
+	return b.DeleteObject([]byte(entry.Name))
 }
 
 // MarkDeltaFailed will insert a record indicating that it is not possible
@@ -0,0 +1,125 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package core
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// initConflictingPackage will write a copy of the integrity test package with
+// different content, but the same ID
+func initConflictingPackage(t *testing.T) string {
+	data, err := os.ReadFile(integrityTestPackage)
+	if err != nil {
+		t.Fatalf("Failed to read test package: %v", err)
+	}
+	dir := filepath.Join("testenv", "upload")
+	if err = os.MkdirAll(dir, 00755); err != nil {
+		t.Fatalf("Failed to create upload directory: %v", err)
+	}
+	path := filepath.Join(dir, integrityTestID)
+	if err = os.WriteFile(path, append(data, []byte("rebuilt")...), 00644); err != nil {
+		t.Fatalf("Failed to write conflicting package: %v", err)
+	}
+	return path
+}
+
+func TestPackageConflict(t *testing.T) {
+	manager := initTestRepo(t, "unstable", integrityTestPackage)
+	defer manager.Close()
+
+	// The exact same file is fine
+	if err := manager.AddPackages(context.Background(), "unstable", []string{integrityTestPackage}, false); err != nil {
+		t.Fatalf("Failed to re-add identical package: %v", err)
+	}
+
+	conflict := initConflictingPackage(t)
+	if err := manager.CreateRepo(context.Background(), "stable"); err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	err := manager.AddPackages(context.Background(), "stable", []string{conflict}, false)
+	if !errors.Is(err, ErrPackageConflict) {
+		t.Fatalf("Expected a package conflict, got: %v", err)
+	}
+	if _, err = manager.GetPackages("stable", "nano"); err == nil {
+		t.Fatalf("Conflicting package should not have been added")
+	}
+}
+
+func TestCheckFileError(t *testing.T) {
+	manager := initTestRepo(t, "unstable")
+	defer manager.Close()
+
+	// An unreadable entry must not be mistaken for an unused ID
+	if err := manager.db.Bucket([]byte(DatabaseBucketPool)).PutObject([]byte(integrityTestID), "garbage"); err != nil {
+		t.Fatalf("Failed to store broken pool entry: %v", err)
+	}
+	if err := manager.pool.CheckFile(manager.db, integrityTestPackage); err == nil {
+		t.Fatalf("Expected an unreadable pool entry to fail the check")
+	}
+}
+
+func TestReplacePackage(t *testing.T) {
+	manager := initTestRepo(t, "unstable", integrityTestPackage)
+	defer manager.Close()
+
+	replacement := initConflictingPackage(t)
+	sha, err := FileSha1sum(replacement)
+	if err != nil {
+		t.Fatalf("Failed to hash replacement: %v", err)
+	}
+
+	updated, err := manager.ReplacePackage(context.Background(), replacement)
+	if err != nil {
+		t.Fatalf("Failed to replace package: %v", err)
+	}
+	if len(updated) != 1 || updated[0] != "unstable" {
+		t.Fatalf("Expected unstable to be updated, got: %v", updated)
+	}
+
+	entry, err := manager.GetPoolEntry(integrityTestID)
+	if err != nil {
+		t.Fatalf("Failed to get pool entry: %v", err)
+	}
+	if entry.PackageHash != sha {
+		t.Fatalf("Pool entry has stale sha1 %s, expected %s", entry.PackageHash, sha)
+	}
+
+	repo, err := manager.GetRepo("unstable")
+	if err != nil {
+		t.Fatalf("Failed to get repository: %v", err)
+	}
+	repoSha, err := FileSha1sum(filepath.Join(repo.path, "n", "nano", integrityTestID))
+	if err != nil {
+		t.Fatalf("Failed to hash repository file: %v", err)
+	}
+	if repoSha != sha {
+		t.Fatalf("Repository file was not relinked")
+	}
+
+	report, err := manager.CheckIntegrity(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to check integrity: %v", err)
+	}
+	if len(report.Issues) != 0 {
+		t.Fatalf("Expected a clean report after replace, got: %v", report.Issues)
+	}
+}
@@ -0,0 +1,50 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package core
+
+import (
+	"context"
+)
+
+// A ProgressFunc is told how far a long running operation has got, as the
+// number of items done out of the total within the named step. A total of
+// zero means the step can't be measured.
+type ProgressFunc func(step string, done, total int)
+
+// progressKey is used to store the ProgressFunc within a context
+type progressKey struct{}
+
+// WithProgress returns a context that will report the progress of operations
+// it is passed to through fn
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// reportProgress will pass progress on to the ProgressFunc within ctx, if any
+func reportProgress(ctx context.Context, step string, done, total int) {
+	if fn, ok := ctx.Value(progressKey{}).(ProgressFunc); ok {
+		fn(step, done, total)
+	}
+}
+
+// stepProgress will report progress like reportProgress, returning an error
+// once ctx is cancelled or has timed out. Long running loops call it before
+// each item, so that they stop between items rather than running to the end.
+func stepProgress(ctx context.Context, step string, done, total int) error {
+	reportProgress(ctx, step, done, total)
+	return ctx.Err()
+}
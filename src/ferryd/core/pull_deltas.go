@@ -0,0 +1,109 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package core
+
+import (
+	"context"
+	"github.com/getsolus/ferryd/src/libdb"
+	"github.com/getsolus/ferryd/src/libeopkg"
+)
+
+// A PullResult describes what PullFrom changed in the target repository
+type PullResult struct {
+	Changed    []string       `json:"changed"`    // Package names with a new published package
+	Deltas     []string       `json:"deltas"`     // Existing delta IDs carried into the repository
+	NeedDeltas []string       `json:"needDeltas"` // Package names still missing deltas to their new tip
+	Skipped    []PullConflict `json:"skipped"`    // Packages left alone as they're newer in the target
+	Downgraded []PullConflict `json:"downgraded"` // Packages forcibly downgraded to the source
+}
+
+// carryDeltas will find the deltas already in the pool that update from any
+// package available in entry to toID, and which entry doesn't include yet.
+// missing is set when at least one possible delta has never been produced.
+func carryDeltas(db libdb.Database, pool *Pool, entry *RepoEntry, toID string) (carried []string, missing bool, err error) {
+	toEntry, err := pool.GetEntry(db, toID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	known := make(map[string]bool)
+	for _, id := range entry.Deltas {
+		known[id] = true
+	}
+
+	for _, fromID := range entry.Available {
+		if fromID == toID {
+			continue
+		}
+		fromEntry, err := pool.GetEntry(db, fromID)
+		if err != nil {
+			return nil, false, err
+		}
+		if !libeopkg.IsDeltaPossible(fromEntry.Meta, toEntry.Meta) {
+			continue
+		}
+
+		deltaID := libeopkg.ComputeDeltaName(fromEntry.Meta, toEntry.Meta)
+		if known[deltaID] || pool.GetDeltaFailed(db, deltaID) {
+			continue
+		}
+
+		deltaEntry, err := pool.GetEntry(db, deltaID)
+		if err != nil || deltaEntry.Delta == nil || deltaEntry.Delta.FromID != fromID || deltaEntry.Delta.ToID != toID {
+			missing = true
+			continue
+		}
+		carried = append(carried, deltaID)
+	}
+
+	return carried, missing, nil
+}
+
+// pullDeltas will ref the existing deltas for every newly pulled package,
+// recording the names which still need deltas producing in the result.
+func (r *Repository) pullDeltas(ctx context.Context, db libdb.Database, pool *Pool, copyIDs []string, result *PullResult) error {
+	for _, id := range copyIDs {
+		poolEntry, err := pool.GetEntry(db, id)
+		if err != nil {
+			return err
+		}
+		entry, err := r.GetEntry(db, poolEntry.Meta.Name)
+		if err != nil {
+			return err
+		}
+
+		// Only the tip gets deltas
+		if entry.Published != id {
+			continue
+		}
+
+		carried, missing, err := carryDeltas(db, pool, entry, id)
+		if err != nil {
+			return err
+		}
+		for _, deltaID := range carried {
+			if err = r.RefDelta(ctx, db, pool, deltaID); err != nil {
+				return err
+			}
+		}
+		result.Deltas = append(result.Deltas, carried...)
+		if missing {
+			result.NeedDeltas = append(result.NeedDeltas, entry.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package core
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/getsolus/ferryd/src/libeopkg"
+)
+
+const (
+	deltaTestPackage = "../../libeopkg/testdata/delta/nano-2.8.6-76-1-x86_64.eopkg"
+	deltaTestID      = "nano-2.8.6-76-1-x86_64.eopkg"
+)
+
+func TestPullDeltas(t *testing.T) {
+	manager := initTestRepo(t, "unstable", snapshotTestPackage)
+	defer manager.Close()
+
+	// stable starts from the same package as unstable
+	if err := manager.CreateRepo(context.Background(), "stable"); err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	if _, err := manager.PullRepo(context.Background(), "unstable", "stable", nil); err != nil {
+		t.Fatalf("Failed to pull: %v", err)
+	}
+
+	// Update unstable and produce the delta there
+	if err := manager.AddPackages(context.Background(), "unstable", []string{deltaTestPackage}, false); err != nil {
+		t.Fatalf("Failed to add packages: %v", err)
+	}
+	oldPkg, err := manager.GetPoolEntry(snapshotTestID)
+	if err != nil {
+		t.Fatalf("Failed to get pool entry: %v", err)
+	}
+	newPkg, err := manager.GetPoolEntry(deltaTestID)
+	if err != nil {
+		t.Fatalf("Failed to get pool entry: %v", err)
+	}
+	deltaPath, err := manager.CreateDelta(context.Background(), "unstable", oldPkg, newPkg)
+	if err != nil {
+		t.Fatalf("Failed to create delta: %v", err)
+	}
+	mapping := &DeltaInformation{
+		FromID:      snapshotTestID,
+		FromRelease: 75,
+		ToID:        deltaTestID,
+		ToRelease:   76,
+	}
+	if err = manager.AddDelta(context.Background(), "unstable", deltaPath, mapping); err != nil {
+		t.Fatalf("Failed to add delta: %v", err)
+	}
+	os.Remove(deltaPath)
+	deltaID := libeopkg.ComputeDeltaName(oldPkg, newPkg)
+
+	plan, err := manager.PlanPullRepo("unstable", "stable", nil)
+	if err != nil {
+		t.Fatalf("Failed to plan pull: %v", err)
+	}
+	if !reflect.DeepEqual(plan.Ref, []string{deltaTestID, deltaID}) {
+		t.Fatalf("Expected the plan to carry the delta: %+v", plan.Ref)
+	}
+
+	result, err := manager.PullRepo(context.Background(), "unstable", "stable", nil)
+	if err != nil {
+		t.Fatalf("Failed to pull: %v", err)
+	}
+	if !reflect.DeepEqual(result.Deltas, []string{deltaID}) || len(result.NeedDeltas) != 0 {
+		t.Fatalf("Expected the delta to be carried: %+v", result)
+	}
+
+	repo, err := manager.GetRepo("stable")
+	if err != nil {
+		t.Fatalf("Failed to get repository: %v", err)
+	}
+	has, err := repo.HasDelta(manager.db, "nano", deltaID)
+	if err != nil || !has {
+		t.Fatalf("stable should now include the delta: %v", err)
+	}
+
+	report, err := manager.CheckIntegrity(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to check integrity: %v", err)
+	}
+	if len(report.Issues) != 0 {
+		t.Fatalf("Carrying deltas broke integrity: %+v", report.Issues)
+	}
+}
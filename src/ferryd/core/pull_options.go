@@ -0,0 +1,120 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package core
+
+import (
+	"fmt"
+
+	"github.com/getsolus/ferryd/src/libeopkg"
+)
+
+// PullConflictPolicy decides what PullFrom does when a package is newer in the
+// target than in the source, i.e. a hotfix that went straight to stable
+type PullConflictPolicy string
+
+const (
+	// PullConflictFail aborts the whole pull, and is the default
+	PullConflictFail PullConflictPolicy = "fail"
+
+	// PullConflictSkip leaves the newer target package alone and reports it
+	PullConflictSkip PullConflictPolicy = "skip"
+
+	// PullConflictDowngrade removes the newer target packages so that the
+	// source package is published instead
+	PullConflictDowngrade PullConflictPolicy = "downgrade"
+)
+
+// PullOptions control the behaviour of PullFrom. A nil set of options pulls
+// everything and fails on conflicts.
+type PullOptions struct {
+	Filter   *PullFilter        // Optional filter on the packages to pull
+	Conflict PullConflictPolicy // What to do when the target is newer
+}
+
+// A PullConflict is a package that is newer in the target than in the source
+type PullConflict struct {
+	Name          string `json:"name"`          // Package name
+	SourceID      string `json:"sourceID"`      // Published ID in the source
+	SourceRelease int    `json:"sourceRelease"` // Release of the source package
+	TargetID      string `json:"targetID"`      // Published ID in the target
+	TargetRelease int    `json:"targetRelease"` // Release of the target package
+}
+
+// conflictPolicy returns the policy to use, validating any explicit choice
+func (o *PullOptions) conflictPolicy() (PullConflictPolicy, error) {
+	if o == nil || o.Conflict == "" {
+		return PullConflictFail, nil
+	}
+	switch o.Conflict {
+	case PullConflictFail, PullConflictSkip, PullConflictDowngrade:
+		return o.Conflict, nil
+	default:
+		return "", fmt.Errorf("unknown conflict policy '%s'", o.Conflict)
+	}
+}
+
+// filter returns the package filter, which may be nil
+func (o *PullOptions) filter() *PullFilter {
+	if o == nil {
+		return nil
+	}
+	return o.Filter
+}
+
+// A PullFilter restricts which packages are considered by PullFrom. When any
+// allow list is set, a package must match at least one allowed source name,
+// binary name or component. A package matching any deny list is never pulled.
+type PullFilter struct {
+	AllowSources    []string // Source names to pull
+	AllowNames      []string // Binary package names to pull
+	AllowComponents []string // Components (PartOf) to pull
+	DenySources     []string // Source names to never pull
+	DenyNames       []string // Binary package names to never pull
+	DenyComponents  []string // Components (PartOf) to never pull
+}
+
+// containsString is a simple helper to find needle in the haystack
+func containsString(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches will determine if the package may be pulled. A nil filter matches
+// everything.
+func (f *PullFilter) Matches(meta *libeopkg.MetaPackage) bool {
+	if f == nil {
+		return true
+	}
+
+	if containsString(f.DenySources, meta.Source.Name) ||
+		containsString(f.DenyNames, meta.Name) ||
+		containsString(f.DenyComponents, meta.PartOf) {
+		return false
+	}
+
+	if len(f.AllowSources)+len(f.AllowNames)+len(f.AllowComponents) == 0 {
+		return true
+	}
+
+	return containsString(f.AllowSources, meta.Source.Name) ||
+		containsString(f.AllowNames, meta.Name) ||
+		containsString(f.AllowComponents, meta.PartOf)
+}
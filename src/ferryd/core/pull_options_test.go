@@ -0,0 +1,132 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package core
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/getsolus/ferryd/src/libeopkg"
+)
+
+func TestPullFilter(t *testing.T) {
+	meta := &libeopkg.MetaPackage{
+		Name:   "openssl-devel",
+		PartOf: "programming.devel",
+		Source: libeopkg.Source{Name: "openssl"},
+	}
+
+	tests := []struct {
+		filter *PullFilter
+		want   bool
+	}{
+		{nil, true},
+		{&PullFilter{}, true},
+		{&PullFilter{AllowSources: []string{"openssl"}}, true},
+		{&PullFilter{AllowSources: []string{"nano"}}, false},
+		{&PullFilter{AllowSources: []string{"nano"}, AllowNames: []string{"openssl-devel"}}, true},
+		{&PullFilter{AllowComponents: []string{"programming.devel"}}, true},
+		{&PullFilter{DenyComponents: []string{"programming.devel"}}, false},
+		{&PullFilter{AllowSources: []string{"openssl"}, DenyNames: []string{"openssl-devel"}}, false},
+	}
+
+	for i, test := range tests {
+		if got := test.filter.Matches(meta); got != test.want {
+			t.Fatalf("Filter %d: got %v, expected %v", i, got, test.want)
+		}
+	}
+}
+
+func TestSelectivePull(t *testing.T) {
+	manager := initTestRepo(t, "unstable", snapshotTestPackage)
+	defer manager.Close()
+
+	if err := manager.CreateRepo(context.Background(), "stable"); err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+
+	// Nothing matches, nothing is pulled
+	result, err := manager.PullRepo(context.Background(), "unstable", "stable", &PullOptions{Filter: &PullFilter{AllowSources: []string{"openssl"}}})
+	if err != nil {
+		t.Fatalf("Failed to pull: %v", err)
+	}
+	if len(result.Changed) != 0 {
+		t.Fatalf("Expected no changes, got: %v", result.Changed)
+	}
+
+	result, err = manager.PullRepo(context.Background(), "unstable", "stable", &PullOptions{Filter: &PullFilter{AllowSources: []string{"nano"}}})
+	if err != nil {
+		t.Fatalf("Failed to pull: %v", err)
+	}
+	if len(result.Changed) != 1 || result.Changed[0] != "nano" {
+		t.Fatalf("Expected nano to be pulled, got: %v", result.Changed)
+	}
+}
+
+func TestPullConflicts(t *testing.T) {
+	manager := initTestRepo(t, "unstable", snapshotTestPackage)
+	defer manager.Close()
+
+	// A hotfix went straight into stable
+	if err := manager.CreateRepo(context.Background(), "stable"); err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	if err := manager.AddPackages(context.Background(), "stable", []string{deltaTestPackage}, false); err != nil {
+		t.Fatalf("Failed to add packages: %v", err)
+	}
+
+	if _, err := manager.PullRepo(context.Background(), "unstable", "stable", nil); err == nil {
+		t.Fatalf("Pull should fail on conflicts by default")
+	}
+	if _, err := manager.PullRepo(context.Background(), "unstable", "stable", &PullOptions{Conflict: "bogus"}); err == nil {
+		t.Fatalf("Pull should reject unknown conflict policies")
+	}
+
+	result, err := manager.PullRepo(context.Background(), "unstable", "stable", &PullOptions{Conflict: PullConflictSkip})
+	if err != nil {
+		t.Fatalf("Failed to pull: %v", err)
+	}
+	if len(result.Changed) != 0 || len(result.Skipped) != 1 || result.Skipped[0].TargetID != deltaTestID {
+		t.Fatalf("Expected nano to be skipped: %+v", result)
+	}
+
+	opts := &PullOptions{Conflict: PullConflictDowngrade}
+	plan, err := manager.PlanPullRepo("unstable", "stable", opts)
+	if err != nil {
+		t.Fatalf("Failed to plan pull: %v", err)
+	}
+	expected := []PublishedChange{{Name: "nano", From: deltaTestID, To: snapshotTestID}}
+	if !reflect.DeepEqual(plan.Unref, []string{deltaTestID}) || !reflect.DeepEqual(plan.Published, expected) {
+		t.Fatalf("Unexpected downgrade plan: %+v", plan)
+	}
+
+	result, err = manager.PullRepo(context.Background(), "unstable", "stable", opts)
+	if err != nil {
+		t.Fatalf("Failed to pull: %v", err)
+	}
+	if len(result.Downgraded) != 1 || len(result.Changed) != 1 {
+		t.Fatalf("Expected nano to be downgraded: %+v", result)
+	}
+	pkgs, err := manager.GetPackages("stable", "nano")
+	if err != nil {
+		t.Fatalf("Failed to get packages: %v", err)
+	}
+	if len(pkgs) != 1 || pkgs[0].GetID() != snapshotTestID {
+		t.Fatalf("Downgrade did not match the plan")
+	}
+}
@@ -28,7 +28,7 @@ import (
 
 const FerrydDir = "/etc/ferryd"
 
-func purgeCloudflare(target string) error {
+func purgeCloudflare(ctx context.Context, target string) error {
 	// Check if we have an api token
 	apiKey := os.Getenv("CLOUDFLARE_API_TOKEN")
 	if len(apiKey) == 0 {
@@ -60,7 +60,6 @@ func purgeCloudflare(target string) error {
 		return err
 	}
 
-	ctx := context.Background()
 	result, err := api.PurgeCache(ctx, zoneId, cloudflare.PurgeCacheRequest{Files: files})
 	if err != nil {
 		return err
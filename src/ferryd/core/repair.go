@@ -0,0 +1,207 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getsolus/ferryd/src/libdb"
+)
+
+// repairWalkHook is called just before repair walks the pool, and is only
+// set by tests
+var repairWalkHook func()
+
+// A RepairReport records every action taken while repairing the pool and
+// the repositories
+type RepairReport struct {
+	Started       time.Time // When the repair began
+	Finished      time.Time // When the repair completed
+	RefCounts     []string  // Pool entries that had their refcount corrected
+	Relinked      []string  // Repository files restored from the pool
+	RestoredPool  []string  // Pool files restored from a repository copy
+	Purged        []string  // Pool entries removed as nothing references them
+	Quarantined   []string  // Pool files moved aside as they have no DB record
+	Unrecoverable []string  // Problems that could not be fixed automatically
+}
+
+// repair will ensure every file referenced by the repository exists on disk,
+// relinking from the pool where needed. Every ID referenced by the repository
+// is counted in refs so that the pool refcounts can be corrected afterwards.
+// The caller must hold insertMut.
+func (r *Repository) repair(ctx context.Context, db libdb.Database, pool *Pool, report *RepairReport, refs map[string]uint64) error {
+	rootBucket := db.Bucket([]byte(DatabaseBucketRepo)).Bucket([]byte(r.ID)).Bucket([]byte(DatabaseBucketPackage))
+
+	return rootBucket.ForEach(func(k, v []byte) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		entry := RepoEntry{}
+		if err := rootBucket.Decode(v, &entry); err != nil {
+			return err
+		}
+
+		for _, id := range append(append([]string{}, entry.Available...), entry.Deltas...) {
+			refs[id]++
+			if err := r.repairPackageFile(db, pool, report, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// repairPackageFile will restore whichever side of the pool <-> repository
+// hardlink has gone missing for the given ID
+func (r *Repository) repairPackageFile(db libdb.Database, pool *Pool, report *RepairReport, id string) error {
+	poolEntry, err := pool.GetEntry(db, id)
+	if err != nil {
+		report.Unrecoverable = append(report.Unrecoverable, fmt.Sprintf("%s/%s: no pool entry", r.ID, id))
+		return nil
+	}
+
+	poolPath := pool.GetMetaPoolPath(id, poolEntry.Meta)
+	pkgPath := filepath.Join(r.path, poolEntry.Meta.GetPathComponent(), id)
+	poolExists := PathExists(poolPath)
+
+	if PathExists(pkgPath) {
+		if poolExists {
+			return nil
+		}
+		// Our copy survived, so put it back into the pool
+		if err := os.MkdirAll(filepath.Dir(poolPath), 00755); err != nil {
+			return err
+		}
+		if err := LinkOrCopyFile(pkgPath, poolPath, false); err != nil {
+			return err
+		}
+		report.RestoredPool = append(report.RestoredPool, id)
+		return nil
+	}
+
+	if !poolExists {
+		report.Unrecoverable = append(report.Unrecoverable, fmt.Sprintf("%s/%s: no file in pool or repository", r.ID, id))
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(pkgPath), 00755); err != nil {
+		return err
+	}
+	if err := LinkOrCopyFile(poolPath, pkgPath, false); err != nil {
+		return err
+	}
+	report.Relinked = append(report.Relinked, fmt.Sprintf("%s/%s", r.ID, id))
+	return nil
+}
+
+// repair will set the refcount of every pool entry to the number of references
+// counted in refs, purging anything that is no longer referenced. Finally any
+// file within the pool tree that has no DB record is moved into quarantine.
+//
+// Packages are copied into the pool before their entry is stored, so refMut
+// is held for the whole pass to keep new files from being quarantined.
+func (p *Pool) repair(ctx context.Context, db libdb.Database, report *RepairReport, refs map[string]uint64) error {
+	p.refMut.Lock()
+	defer p.refMut.Unlock()
+
+	entries, err := p.GetPoolItems(db)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]bool)
+	for i, entry := range entries {
+		if err := stepProgress(ctx, "Repair pool", i, len(entries)); err != nil {
+			return err
+		}
+		count := refs[entry.Name]
+		if count == 0 {
+			if err := p.removeEntry(ctx, db, entry); err != nil {
+				return err
+			}
+			report.Purged = append(report.Purged, entry.Name)
+			continue
+		}
+
+		known[p.GetMetaPoolPath(entry.Name, entry.Meta)] = true
+
+		if entry.RefCount == count {
+			continue
+		}
+		report.RefCounts = append(report.RefCounts, fmt.Sprintf("%s: %d -> %d", entry.Name, entry.RefCount, count))
+		entry.RefCount = count
+		if err := p.putEntry(db, entry); err != nil {
+			return err
+		}
+	}
+
+	if repairWalkHook != nil {
+		repairWalkHook()
+	}
+
+	// Collect orphans first so we don't modify the tree mid-walk
+	var orphans []string
+	err = filepath.Walk(p.poolDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() && !known[path] {
+			orphans = append(orphans, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, path := range orphans {
+		rel, err := filepath.Rel(p.poolDir, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(p.quarantineDir, rel)
+		if err := os.MkdirAll(filepath.Dir(target), 00755); err != nil {
+			return err
+		}
+		if err := os.Rename(path, target); err != nil {
+			return err
+		}
+		// Only clean up parents within the usual pool/<letter>/<source> layout
+		if filepath.Dir(filepath.Dir(rel)) == "." {
+			report.Quarantined = append(report.Quarantined, rel)
+			continue
+		}
+		if err := RemovePackageParents(path); err != nil {
+			log.WithContext(ctx).WithFields(log.Fields{
+				"path":  path,
+				"error": err,
+			}).Warning("Failed to remove package parents")
+		}
+		report.Quarantined = append(report.Quarantined, rel)
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getsolus/ferryd/src/libdb"
+	"github.com/getsolus/ferryd/src/libeopkg"
+)
+
+// ReplacePackage will swap the file and metadata of an existing pool entry
+// for the given package, keeping the refcount intact. This is an operator
+// override for known-bad entries, and must be followed by relinking every
+// repository that references the ID.
+//
+// The previous metadata is returned so that callers can find the old files.
+// refMut is held throughout so that concurrent refcount changes to the entry
+// aren't lost when it is stored again.
+func (p *Pool) ReplacePackage(db libdb.Database, pkg *libeopkg.Package) (*libeopkg.MetaPackage, error) {
+	p.refMut.Lock()
+	defer p.refMut.Unlock()
+
+	entry, err := p.GetEntry(db, pkg.ID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot replace unknown pool entry %s: %v", pkg.ID, err)
+	}
+	if entry.Delta != nil {
+		return nil, fmt.Errorf("cannot replace delta package %s", pkg.ID)
+	}
+	if entry.Meta.Name != pkg.Meta.Package.Name {
+		return nil, fmt.Errorf("replacement for %s is package '%s', not '%s'", pkg.ID, pkg.Meta.Package.Name, entry.Meta.Name)
+	}
+
+	st, err := os.Stat(pkg.Path)
+	if err != nil {
+		return nil, err
+	}
+	sha, err := FileSha1sum(pkg.Path)
+	if err != nil {
+		return nil, err
+	}
+	sha256, err := FileSha256sum(pkg.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Copy into place alongside, then rename over the top so we never
+	// write through to the existing repository hardlinks.
+	oldMeta := entry.Meta
+	oldPath := p.GetMetaPoolPath(entry.Name, oldMeta)
+	pkgTarget := p.GetPackagePoolPath(pkg)
+	pkgTemp := pkgTarget + ".replace"
+	if err := os.MkdirAll(filepath.Dir(pkgTarget), 00755); err != nil {
+		return nil, err
+	}
+	if err := LinkOrCopyFile(pkg.Path, pkgTemp, true); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(pkgTemp, pkgTarget); err != nil {
+		os.Remove(pkgTemp)
+		return nil, err
+	}
+
+	// Source name changed, so the old file lives elsewhere
+	if oldPath != pkgTarget {
+		os.Remove(oldPath)
+		RemovePackageParents(oldPath)
+	}
+
+	pkg.Meta.Package.PackageHash = sha
+	pkg.Meta.Package.PackageSize = st.Size()
+	pkg.Meta.Package.PackageURI = fmt.Sprintf("%s/%s", pkg.Meta.Package.GetPathComponent(), pkg.ID)
+
+	entry.Meta = &pkg.Meta.Package
+	entry.Sha256 = sha256
+	if err := p.putEntry(db, entry); err != nil {
+		return nil, err
+	}
+	return oldMeta, nil
+}
+
+// references determines whether the given package ID is available within
+// this repository
+func (r *Repository) references(db libdb.Database, name, id string) bool {
+	entry, err := r.GetEntry(db, name)
+	if err != nil {
+		return false
+	}
+	for _, avail := range entry.Available {
+		if avail == id {
+			return true
+		}
+	}
+	return false
+}
+
+// relinkReplaced will swap our hardlink for a replaced pool entry with the
+// new file, and drop any deltas that were built against the old content.
+func (r *Repository) relinkReplaced(ctx context.Context, db libdb.Database, pool *Pool, oldMeta *libeopkg.MetaPackage, id string) error {
+	r.insertMut.Lock()
+	defer r.insertMut.Unlock()
+
+	poolEntry, err := pool.GetEntry(db, id)
+	if err != nil {
+		return err
+	}
+	entry, err := r.GetEntry(db, poolEntry.Meta.Name)
+	if err != nil {
+		return err
+	}
+
+	oldPath := filepath.Join(r.path, oldMeta.GetPathComponent(), id)
+	if err := os.Remove(oldPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	RemovePackageParents(oldPath)
+
+	pkgTarget := filepath.Join(r.path, poolEntry.Meta.GetPathComponent(), id)
+	if err := os.MkdirAll(filepath.Dir(pkgTarget), 00755); err != nil {
+		return err
+	}
+	if err := LinkOrCopyFile(pool.GetMetaPoolPath(id, poolEntry.Meta), pkgTarget, false); err != nil {
+		return err
+	}
+
+	var remainDeltas []string
+	for _, deltaID := range entry.Deltas {
+		pkgDelta, err := pool.GetEntry(db, deltaID)
+		if err != nil {
+			return err
+		}
+		if pkgDelta.Delta.FromID != id && pkgDelta.Delta.ToID != id {
+			remainDeltas = append(remainDeltas, deltaID)
+			continue
+		}
+		deltaPath := filepath.Join(r.path, pkgDelta.Meta.GetPathComponent(), deltaID)
+		if err := os.Remove(deltaPath); err != nil {
+			log.WithContext(ctx).WithFields(log.Fields{
+				"repo":  r.ID,
+				"id":    deltaID,
+				"error": err,
+			}).Warning("Failed to remove target for delta")
+		}
+		if err := pool.UnrefEntry(ctx, db, deltaID); err != nil {
+			return err
+		}
+	}
+	entry.Deltas = remainDeltas
+
+	return r.putEntry(db, entry)
+}
@@ -17,6 +17,7 @@
 package core
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -54,6 +55,13 @@ const (
 	RepoSchemaVersion = "1.0"
 )
 
+// repoAssets are the extra files in the asset path used when indexing
+var repoAssets = []string{
+	"distribution.xml",
+	"components.xml",
+	"groups.xml",
+}
+
 // The RepositoryManager maintains all repos within ferryd which are in
 // turn linked to the main pool
 type RepositoryManager struct {
@@ -64,7 +72,8 @@ type RepositoryManager struct {
 
 	repoLock *sync.Mutex
 
-	repos map[string]*Repository // Cache all repositories.
+	repos    map[string]*Repository // Cache all repositories.
+	cacheMut *sync.RWMutex          // Jobs for different repos run in parallel
 }
 
 // A Repository is a simplistic representation of an exported repository
@@ -99,6 +108,7 @@ func (r *RepositoryManager) Init(ctx *Context, db libdb.Database) error {
 	r.deltaStageBase = filepath.Join(ctx.BaseDir, DeltaStagePathComponent)
 	r.repoLock = &sync.Mutex{}
 	r.repos = make(map[string]*Repository)
+	r.cacheMut = &sync.RWMutex{}
 
 	paths := []string{
 		r.repoBase,
@@ -176,7 +186,10 @@ func (r *RepositoryManager) GetRepos(db libdb.Database) ([]*Repository, error) {
 // return an error. This is a transactional helper to make the API simpler
 func (r *RepositoryManager) GetRepo(db libdb.Database, id string) (*Repository, error) {
 	// Cache each repository.
-	if repo, ok := r.repos[id]; ok {
+	r.cacheMut.RLock()
+	repo, ok := r.repos[id]
+	r.cacheMut.RUnlock()
+	if ok {
 		return repo, nil
 	}
 
@@ -191,7 +204,13 @@ func (r *RepositoryManager) GetRepo(db libdb.Database, id string) (*Repository,
 		return nil, err
 	}
 
-	// Cache this guy for later
+	// Cache this guy for later, unless someone else beat us to it, as the
+	// locks live within the Repository
+	r.cacheMut.Lock()
+	defer r.cacheMut.Unlock()
+	if repo, ok := r.repos[id]; ok {
+		return repo, nil
+	}
 	r.repos[id] = repository
 
 	return repository, nil
@@ -229,14 +248,16 @@ func (r *RepositoryManager) CreateRepo(db libdb.Database, id string) (*Repositor
 	}
 
 	// Cache this guy for later
+	r.cacheMut.Lock()
 	r.repos[id] = repository
+	r.cacheMut.Unlock()
 
 	return repository, nil
 }
 
 // DeleteRepo will remove an existing repo from the top level repo bucket
 // and clean up any paths/files associated with the deleted repo.
-func (r *RepositoryManager) DeleteRepo(db libdb.Database, pool *Pool, id string) error {
+func (r *RepositoryManager) DeleteRepo(ctx context.Context, db libdb.Database, pool *Pool, id string) error {
 	r.repoLock.Lock()
 	defer r.repoLock.Unlock()
 
@@ -249,11 +270,21 @@ func (r *RepositoryManager) DeleteRepo(db libdb.Database, pool *Pool, id string)
 		return err
 	}
 
+	// Snapshots hold their own references, release them first
+	if err = repo.deleteSnapshots(ctx, db, pool); err != nil {
+		return err
+	}
+
+	r.cacheMut.Lock()
 	delete(r.repos, id)
+	r.cacheMut.Unlock()
+
+	repo.insertMut.Lock()
+	defer repo.insertMut.Unlock()
 
 	// Let's iterate over every one of our packages here and start up an unref
 	// cycle
-	err = db.Update(func(db libdb.Database) error {
+	err = pool.update(db, func(db libdb.Database) error {
 		repoBucket := db.Bucket([]byte(DatabaseBucketRepo))
 		rootBucket := repoBucket.Bucket([]byte(repo.ID)).Bucket([]byte(DatabaseBucketPackage))
 
@@ -264,16 +295,12 @@ func (r *RepositoryManager) DeleteRepo(db libdb.Database, pool *Pool, id string)
 				return err
 			}
 
-			// First up, find all the packages to unref
-			for _, id := range entry.Available {
-				if err := repo.removePackageInternal(db, pool, id); err != nil {
-					return err
+			// First up, find all the packages and deltas to unref
+			for _, id := range append(entry.Available, entry.Deltas...) {
+				if !repo.unlinkPackage(ctx, db, pool, id) {
+					continue
 				}
-			}
-
-			// Next up, find all the deltas to unref
-			for _, id := range entry.Deltas {
-				if err := repo.removeDeltaInternal(db, pool, id); err != nil {
+				if err := pool.unrefEntry(ctx, db, id); err != nil {
 					return err
 				}
 			}
@@ -307,7 +334,7 @@ func (r *RepositoryManager) DeleteRepo(db libdb.Database, pool *Pool, id string)
 		}
 		// Just continue, warn in the log - do what we can here
 		if err := os.RemoveAll(p); err != nil {
-			log.WithFields(log.Fields{
+			log.WithContext(ctx).WithFields(log.Fields{
 				"repo":  id,
 				"path":  p,
 				"error": err,
@@ -335,7 +362,7 @@ func (r *Repository) putEntry(db libdb.Database, entry *RepoEntry) error {
 }
 
 // RefDelta will take the existing delta from the pool and insert it into our own repository
-func (r *Repository) RefDelta(db libdb.Database, pool *Pool, deltaID string) error {
+func (r *Repository) RefDelta(ctx context.Context, db libdb.Database, pool *Pool, deltaID string) error {
 	r.insertMut.Lock()
 	defer r.insertMut.Unlock()
 
@@ -363,7 +390,7 @@ func (r *Repository) RefDelta(db libdb.Database, pool *Pool, deltaID string) err
 	// Check we don't know about this delta already
 	for _, id := range entry.Deltas {
 		if id == deltaID {
-			log.WithFields(log.Fields{
+			log.WithContext(ctx).WithFields(log.Fields{
 				"id":   id,
 				"repo": r.ID,
 			}).Info("Skipping already included delta")
@@ -394,7 +421,7 @@ func (r *Repository) RefDelta(db libdb.Database, pool *Pool, deltaID string) err
 }
 
 // AddDelta will first open and read the .delta.eopkg, before passing it back off to AddLocalDelta
-func (r *Repository) AddDelta(db libdb.Database, pool *Pool, filename string, mapping *DeltaInformation) error {
+func (r *Repository) AddDelta(ctx context.Context, db libdb.Database, pool *Pool, filename string, mapping *DeltaInformation) error {
 	pkg, err := libeopkg.Open(filename)
 	if err != nil {
 		return err
@@ -405,13 +432,13 @@ func (r *Repository) AddDelta(db libdb.Database, pool *Pool, filename string, ma
 		return err
 	}
 
-	return r.AddLocalDelta(db, pool, pkg, mapping)
+	return r.AddLocalDelta(ctx, db, pool, pkg, mapping)
 }
 
 // AddLocalDelta will attempt to add the delta to this repository, if possible
 // All ref'd deltas are retained, but not necessarily emitted unless they're
 // valid for the from-to relationship.
-func (r *Repository) AddLocalDelta(db libdb.Database, pool *Pool, pkg *libeopkg.Package, mapping *DeltaInformation) error {
+func (r *Repository) AddLocalDelta(ctx context.Context, db libdb.Database, pool *Pool, pkg *libeopkg.Package, mapping *DeltaInformation) error {
 	r.insertMut.Lock()
 	defer r.insertMut.Unlock()
 
@@ -431,7 +458,7 @@ func (r *Repository) AddLocalDelta(db libdb.Database, pool *Pool, pkg *libeopkg.
 	// Check we don't know about this delta already
 	for _, id := range entry.Deltas {
 		if id == pkg.ID {
-			log.WithFields(log.Fields{
+			log.WithContext(ctx).WithFields(log.Fields{
 				"id":   id,
 				"repo": r.ID,
 			}).Info("Skipping already included delta")
@@ -463,13 +490,24 @@ func (r *Repository) AddLocalDelta(db libdb.Database, pool *Pool, pkg *libeopkg.
 }
 
 // Internal helper to remove packages
-func (r *Repository) removePackageInternal(db libdb.Database, pool *Pool, id string) error {
+func (r *Repository) removePackageInternal(ctx context.Context, db libdb.Database, pool *Pool, id string) error {
 	r.insertMut.Lock()
 	defer r.insertMut.Unlock()
 
+	if !r.unlinkPackage(ctx, db, pool, id) {
+		return nil
+	}
+
+	// Tell the pool we no longer need this guy
+	return pool.UnrefEntry(ctx, db, id)
+}
+
+// unlinkPackage will remove the file for id from the repository tree,
+// returning false if the pool doesn't know about the package.
+func (r *Repository) unlinkPackage(ctx context.Context, db libdb.Database, pool *Pool, id string) bool {
 	poolEntry, err := pool.GetEntry(db, id)
 	if err != nil {
-		return nil
+		return false
 	}
 
 	pkgDir := filepath.Join(r.path, poolEntry.Meta.GetPathComponent())
@@ -477,7 +515,7 @@ func (r *Repository) removePackageInternal(db libdb.Database, pool *Pool, id str
 
 	// If we cant unlink the file it aint getting out of the DB either...
 	if err = os.Remove(pkgTarget); err != nil {
-		log.WithFields(log.Fields{
+		log.WithContext(ctx).WithFields(log.Fields{
 			"repo":  r.ID,
 			"id":    id,
 			"error": err,
@@ -486,21 +524,20 @@ func (r *Repository) removePackageInternal(db libdb.Database, pool *Pool, id str
 
 	// This is a "we tried but oh noes, not fatal.
 	if err = RemovePackageParents(pkgTarget); err != nil {
-		log.WithFields(log.Fields{
+		log.WithContext(ctx).WithFields(log.Fields{
 			"repo":  r.ID,
 			"id":    id,
 			"error": err,
 		}).Warning("Failed to remove parent structure for package")
 	}
 
-	// Tell the pool we no longer need this guy
-	return pool.UnrefEntry(db, id)
+	return true
 }
 
 // removeDeltaInternal has the same job as removePackageInternal, but in future should
 // be extended to remove the skip records
-func (r *Repository) removeDeltaInternal(db libdb.Database, pool *Pool, id string) error {
-	return r.removePackageInternal(db, pool, id)
+func (r *Repository) removeDeltaInternal(ctx context.Context, db libdb.Database, pool *Pool, id string) error {
+	return r.removePackageInternal(ctx, db, pool, id)
 }
 
 // UnrefPackage will remove a package from our storage, and potentially remove the
@@ -508,7 +545,7 @@ func (r *Repository) removeDeltaInternal(db libdb.Database, pool *Pool, id strin
 //
 // Additionally, we'll locate stray deltas which lead either TO or FROM the given
 // package as they'll now be useless to anyone.
-func (r *Repository) UnrefPackage(db libdb.Database, pool *Pool, pkgID string) error {
+func (r *Repository) UnrefPackage(ctx context.Context, db libdb.Database, pool *Pool, pkgID string) error {
 	newHighest := 0
 	var newHighestID string
 
@@ -529,7 +566,7 @@ func (r *Repository) UnrefPackage(db libdb.Database, pool *Pool, pkgID string) e
 	}
 
 	// Try to remove it from disk first
-	if err := r.removePackageInternal(db, pool, pkgID); err != nil {
+	if err := r.removePackageInternal(ctx, db, pool, pkgID); err != nil {
 		return err
 	}
 
@@ -545,7 +582,7 @@ func (r *Repository) UnrefPackage(db libdb.Database, pool *Pool, pkgID string) e
 
 		// We found a delta that is referencing us, we must garbage collect it now
 		if pkgDelta.Delta.FromID == pkgID || pkgDelta.Delta.ToID == pkgID {
-			if err := r.removeDeltaInternal(db, pool, pkgDelta.Name); err != nil {
+			if err := r.removeDeltaInternal(ctx, db, pool, pkgDelta.Name); err != nil {
 				return err
 			}
 		} else {
@@ -593,7 +630,7 @@ func (r *Repository) UnrefPackage(db libdb.Database, pool *Pool, pkgID string) e
 }
 
 // RefPackage will dupe a package from the pool into our own storage
-func (r *Repository) RefPackage(db libdb.Database, pool *Pool, pkgID string) error {
+func (r *Repository) RefPackage(ctx context.Context, db libdb.Database, pool *Pool, pkgID string) error {
 	r.insertMut.Lock()
 	defer r.insertMut.Unlock()
 
@@ -611,7 +648,7 @@ func (r *Repository) RefPackage(db libdb.Database, pool *Pool, pkgID string) err
 	targetDir := filepath.Join(r.path, poolEntry.Meta.GetPathComponent())
 	targetPath := filepath.Join(targetDir, pkgID)
 
-	repoEntry := r.buildSaneEntry(db, pool, poolEntry.Meta, pkgID)
+	repoEntry := r.buildSaneEntry(ctx, db, pool, poolEntry.Meta, pkgID)
 	// Already included
 	if repoEntry == nil {
 		return nil
@@ -637,7 +674,7 @@ func (r *Repository) RefPackage(db libdb.Database, pool *Pool, pkgID string) err
 
 // buildSaneEntry will either return a plain entry if none exists already, otherwise it will
 // take an existing entry and correctly set up the available/published fields
-func (r *Repository) buildSaneEntry(db libdb.Database, pool *Pool, newPkg *libeopkg.MetaPackage, newID string) *RepoEntry {
+func (r *Repository) buildSaneEntry(ctx context.Context, db libdb.Database, pool *Pool, newPkg *libeopkg.MetaPackage, newID string) *RepoEntry {
 	// Fallback in case one actually doesn't exist yet
 	repoEntry := &RepoEntry{
 		SchemaVersion: RepoSchemaVersion,
@@ -658,7 +695,7 @@ func (r *Repository) buildSaneEntry(db libdb.Database, pool *Pool, newPkg *libeo
 			if newPkg.GetRelease() > pkgAvail.Meta.GetRelease() {
 				repoEntry.Published = newID
 			} else if newPkg.GetRelease() == pkgAvail.Meta.GetRelease() && pkgAvail.Name != newID {
-				log.WithFields(log.Fields{
+				log.WithContext(ctx).WithFields(log.Fields{
 					"existing":   pkgAvail.Name,
 					"newPackage": newID,
 					"repo":       r.ID,
@@ -672,7 +709,7 @@ func (r *Repository) buildSaneEntry(db libdb.Database, pool *Pool, newPkg *libeo
 	// Check if we've already indexed it, non-fatal
 	for _, id := range repoEntry.Available {
 		if id == newID {
-			log.WithFields(log.Fields{
+			log.WithContext(ctx).WithFields(log.Fields{
 				"id":   id,
 				"repo": r.ID,
 			}).Info("Skipping already included package")
@@ -688,7 +725,7 @@ func (r *Repository) buildSaneEntry(db libdb.Database, pool *Pool, newPkg *libeo
 }
 
 // AddLocalPackage will do the real work of adding an open & loaded eopkg to the repository
-func (r *Repository) AddLocalPackage(db libdb.Database, pool *Pool, pkg *libeopkg.Package) error {
+func (r *Repository) AddLocalPackage(ctx context.Context, db libdb.Database, pool *Pool, pkg *libeopkg.Package) error {
 	r.insertMut.Lock()
 	defer r.insertMut.Unlock()
 
@@ -696,11 +733,16 @@ func (r *Repository) AddLocalPackage(db libdb.Database, pool *Pool, pkg *libeopk
 		return err
 	}
 
+	// Never accept different content for a known ID
+	if err := pool.CheckFile(db, pkg.Path); err != nil {
+		return err
+	}
+
 	pkgDir := filepath.Join(r.path, pkg.Meta.Package.GetPathComponent())
 	pkgTarget := filepath.Join(pkgDir, pkg.ID)
 
 	// Already have a package, so let's copy the existing bits over
-	repoEntry := r.buildSaneEntry(db, pool, &pkg.Meta.Package, pkg.ID)
+	repoEntry := r.buildSaneEntry(ctx, db, pool, &pkg.Meta.Package, pkg.ID)
 
 	// nil == already included
 	if repoEntry == nil {
@@ -728,7 +770,7 @@ func (r *Repository) AddLocalPackage(db libdb.Database, pool *Pool, pkg *libeopk
 
 // AddPackage will attempt to load the local package and then add it to the
 // repository via AddLocalPackage
-func (r *Repository) AddPackage(db libdb.Database, pool *Pool, filename string, anal bool) error {
+func (r *Repository) AddPackage(ctx context.Context, db libdb.Database, pool *Pool, filename string, anal bool) error {
 	pkg, err := libeopkg.Open(filename)
 	if err != nil {
 		return err
@@ -741,13 +783,13 @@ func (r *Repository) AddPackage(db libdb.Database, pool *Pool, filename string,
 
 	// Not being strict, just let it in
 	if !anal {
-		return r.AddLocalPackage(db, pool, pkg)
+		return r.AddLocalPackage(ctx, db, pool, pkg)
 	}
 
 	// Do we have this?
 	localPkg, err := r.GetEntry(db, pkg.Meta.Package.Name)
 	if err != nil {
-		return r.AddLocalPackage(db, pool, pkg)
+		return r.AddLocalPackage(ctx, db, pool, pkg)
 	}
 
 	// We have this package, so Published link must work
@@ -770,7 +812,7 @@ func (r *Repository) AddPackage(db libdb.Database, pool *Pool, filename string,
 	}
 
 	// Hey look buddy, you made it.
-	return r.AddLocalPackage(db, pool, pkg)
+	return r.AddLocalPackage(ctx, db, pool, pkg)
 }
 
 // GetPackageNames will traverse the buckets and find all package names as stored
@@ -826,7 +868,7 @@ func (r *Repository) GetPackages(db libdb.Database, pool *Pool, pkgName string)
 // staging area if it successfully produces a delta. This does not mark a delta
 // attempt as "pointless", nor does it actually *include* the delta package
 // within the repository.
-func (r *Repository) CreateDelta(db libdb.Database, oldPkg, newPkg *libeopkg.MetaPackage) (string, error) {
+func (r *Repository) CreateDelta(ctx context.Context, db libdb.Database, oldPkg, newPkg *libeopkg.MetaPackage) (string, error) {
 	if err := r.checkWrite(); err != nil {
 		return "", err
 	}
@@ -845,7 +887,7 @@ func (r *Repository) CreateDelta(db libdb.Database, oldPkg, newPkg *libeopkg.Met
 	oldPath := filepath.Join(r.path, oldPkg.PackageURI)
 	newPath := filepath.Join(r.path, newPkg.PackageURI)
 
-	if err := ProduceDelta(r.deltaPath, oldPath, newPath, fullPath); err != nil {
+	if err := ProduceDelta(ctx, r.deltaPath, oldPath, newPath, fullPath); err != nil {
 		return "", err
 	}
 
@@ -868,10 +910,9 @@ func (r *Repository) HasDelta(db libdb.Database, pkgName, deltaPath string) (boo
 
 // pullAssets will pull the various asset files in prior to indexing
 func (r *Repository) pullAssets(sourceRepo *Repository) error {
-	copyPaths := []string{
-		filepath.Join(sourceRepo.assetPath, "distribution.xml"),
-		filepath.Join(sourceRepo.assetPath, "components.xml"),
-		filepath.Join(sourceRepo.assetPath, "groups.xml"),
+	var copyPaths []string
+	for _, asset := range repoAssets {
+		copyPaths = append(copyPaths, filepath.Join(sourceRepo.assetPath, asset))
 	}
 
 	// In case anyone is being cranky ..
@@ -896,7 +937,7 @@ func (r *Repository) pullAssets(sourceRepo *Repository) error {
 
 // CloneFrom will attempt to clone everything from the target repository into
 // ourselves
-func (r *Repository) CloneFrom(db libdb.Database, pool *Pool, sourceRepo *Repository, fullClone bool) error {
+func (r *Repository) CloneFrom(ctx context.Context, db libdb.Database, pool *Pool, sourceRepo *Repository, fullClone bool) error {
 	// First things first, instigate a write lock on the target
 	sourceRepo.insertMut.Lock()
 	defer sourceRepo.insertMut.Unlock()
@@ -939,15 +980,21 @@ func (r *Repository) CloneFrom(db libdb.Database, pool *Pool, sourceRepo *Reposi
 	// Now we'll insert all the new IDs. We can't really transaction this as
 	// we're going to rely on on the refcount cycle and updating published/available
 	// depending on tip or ALL
-	for _, id := range copyIDs {
-		if err := r.RefPackage(db, pool, id); err != nil {
+	for i, id := range copyIDs {
+		if err := stepProgress(ctx, "Clone packages", i, len(copyIDs)); err != nil {
+			return err
+		}
+		if err := r.RefPackage(ctx, db, pool, id); err != nil {
 			return err
 		}
 	}
 
 	// We can only copy deltas across on full clones.
-	for _, id := range deltaIDs {
-		if err := r.RefDelta(db, pool, id); err != nil {
+	for i, id := range deltaIDs {
+		if err := stepProgress(ctx, "Clone deltas", i, len(deltaIDs)); err != nil {
+			return err
+		}
+		if err := r.RefDelta(ctx, db, pool, id); err != nil {
 			return err
 		}
 	}
@@ -977,46 +1024,119 @@ func (r *Repository) CloneFrom(db libdb.Database, pool *Pool, sourceRepo *Reposi
 // Drift can be corrected by nuking a repository and performing a full clone from the
 // source to have identical mirrors again. This should be performed rarely and only
 // during periods of maintenance due to this method violating atomic indexes.
-func (r *Repository) PullFrom(db libdb.Database, pool *Pool, sourceRepo *Repository) ([]string, error) {
+//
+// Options may be given to only pull a subset of the packages, i.e. to ship a
+// targeted security update, and to decide what happens when a package is newer
+// in this repository than in the source. Deltas already in the pool for the
+// newly published packages are carried across as well.
+//
+// The pull stops between packages once ctx is done, leaving the packages
+// pulled so far in place.
+func (r *Repository) PullFrom(ctx context.Context, db libdb.Database, pool *Pool, sourceRepo *Repository, opts *PullOptions) (*PullResult, error) {
 	// First things first, instigate a write lock on the source
 	sourceRepo.insertMut.Lock()
 	defer sourceRepo.insertMut.Unlock()
 
-	var copyIDs []string
-	var changedNames []string
-
-	rootBucket := db.Bucket([]byte(DatabaseBucketRepo)).Bucket([]byte(sourceRepo.ID)).Bucket([]byte(DatabaseBucketPackage))
-
 	if err := r.checkWrite(); err != nil {
 		return nil, err
 	}
 
+	// Validate everything before syncing the assets
+	set, err := r.pullChanges(db, pool, sourceRepo, opts)
+	if err != nil {
+		return nil, err
+	}
+
 	// Before doing anything, sync the assets
 	if err := r.pullAssets(sourceRepo); err != nil {
 		return nil, err
 	}
 
+	// Forced downgrades drop the newer packages first so that the refs
+	// below end up published
+	for i, id := range set.unrefIDs {
+		if err := stepProgress(ctx, "Downgrade packages", i, len(set.unrefIDs)); err != nil {
+			return nil, err
+		}
+		if err := r.UnrefPackage(ctx, db, pool, id); err != nil {
+			return nil, err
+		}
+	}
+
+	// Now we'll insert all the new IDs. We can't really transaction this as
+	// we're going to rely on on the refcount cycle and updating published/available
+	// depending on tip or ALL
+	for i, id := range set.copyIDs {
+		if err := stepProgress(ctx, "Pull packages", i, len(set.copyIDs)); err != nil {
+			return nil, err
+		}
+		if err := r.RefPackage(ctx, db, pool, id); err != nil {
+			return nil, err
+		}
+	}
+
+	// Bring along any deltas we already have for the new tips, so users
+	// aren't stuck with full downloads until the delta jobs catch up
+	result := &PullResult{
+		Changed:    set.changedNames,
+		Skipped:    set.skipped,
+		Downgraded: set.downgraded,
+	}
+	if err := r.pullDeltas(ctx, db, pool, set.copyIDs, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// A pullSet is the set of changes PullFrom needs to make
+type pullSet struct {
+	copyIDs      []string       // Package IDs to ref from the source
+	unrefIDs     []string       // Newer package IDs to drop for a downgrade
+	changedNames []string       // Names that will be published differently
+	skipped      []PullConflict // Conflicts left alone
+	downgraded   []PullConflict // Conflicts resolved by downgrading
+}
+
+// pullChanges will determine which package IDs PullFrom needs to copy from the
+// sourceRepo, along with the names of the packages that will change.
+// Packages not matching the filter are ignored entirely.
+func (r *Repository) pullChanges(db libdb.Database, pool *Pool, sourceRepo *Repository, opts *PullOptions) (*pullSet, error) {
+	policy, err := opts.conflictPolicy()
+	if err != nil {
+		return nil, err
+	}
+	filter := opts.filter()
+	set := &pullSet{}
+
+	rootBucket := db.Bucket([]byte(DatabaseBucketRepo)).Bucket([]byte(sourceRepo.ID)).Bucket([]byte(DatabaseBucketPackage))
+
 	// Grab every package
-	err := rootBucket.ForEach(func(k, v []byte) error {
+	err = rootBucket.ForEach(func(k, v []byte) error {
 		entry := RepoEntry{}
 		if err := rootBucket.Decode(v, &entry); err != nil {
 			return err
 		}
 
+		tipVer, err := pool.GetEntry(db, entry.Published)
+		if err != nil {
+			return err
+		}
+
+		if !filter.Matches(tipVer.Meta) {
+			return nil
+		}
+
 		localEntry, _ := r.GetEntry(db, entry.Name)
 
 		// We haven't got this, copy the published version
 		if localEntry == nil {
-			copyIDs = append(copyIDs, entry.Published)
-			changedNames = append(changedNames, entry.Name)
+			set.copyIDs = append(set.copyIDs, entry.Published)
+			set.changedNames = append(set.changedNames, entry.Name)
 			return nil
 		}
 
 		// We have got this, so is it newer than ours?
-		tipVer, err := pool.GetEntry(db, entry.Published)
-		if err != nil {
-			return err
-		}
 		ourTip, err := pool.GetEntry(db, localEntry.Published)
 		if err != nil {
 			return err
@@ -1024,31 +1144,64 @@ func (r *Repository) PullFrom(db libdb.Database, pool *Pool, sourceRepo *Reposit
 
 		// Their tip is newer than ours, copy it
 		if tipVer.Meta.GetRelease() > ourTip.Meta.GetRelease() {
-			copyIDs = append(copyIDs, entry.Published)
-			changedNames = append(changedNames, entry.Name)
+			set.copyIDs = append(set.copyIDs, entry.Published)
+			set.changedNames = append(set.changedNames, entry.Name)
 		}
 
-		// Is something completely bork?
-		if tipVer.Meta.GetRelease() < ourTip.Meta.GetRelease() {
+		if tipVer.Meta.GetRelease() >= ourTip.Meta.GetRelease() {
+			return nil
+		}
+
+		// Something is newer in the target, i.e. a hotfix
+		conflict := PullConflict{
+			Name:          entry.Name,
+			SourceID:      entry.Published,
+			SourceRelease: tipVer.Meta.GetRelease(),
+			TargetID:      localEntry.Published,
+			TargetRelease: ourTip.Meta.GetRelease(),
+		}
+
+		switch policy {
+		case PullConflictSkip:
+			set.skipped = append(set.skipped, conflict)
+			return nil
+		case PullConflictDowngrade:
+			return r.pullDowngrade(db, pool, localEntry, tipVer, conflict, set)
+		default:
 			return fmt.Errorf("inconsistent target repository, %v is NEWER in target not SOURCE", localEntry.Name)
 		}
-		return nil
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	// Now we'll insert all the new IDs. We can't really transaction this as
-	// we're going to rely on on the refcount cycle and updating published/available
-	// depending on tip or ALL
-	for _, id := range copyIDs {
-		if err := r.RefPackage(db, pool, id); err != nil {
-			return nil, err
+	return set, nil
+}
+
+// pullDowngrade will record the changes needed to publish the source package
+// in place of every newer package in the local entry
+func (r *Repository) pullDowngrade(db libdb.Database, pool *Pool, localEntry *RepoEntry, tipVer *PoolEntry, conflict PullConflict, set *pullSet) error {
+	have := false
+	for _, id := range localEntry.Available {
+		if id == conflict.SourceID {
+			have = true
+			continue
+		}
+		avail, err := pool.GetEntry(db, id)
+		if err != nil {
+			return err
+		}
+		if avail.Meta.GetRelease() > tipVer.Meta.GetRelease() {
+			set.unrefIDs = append(set.unrefIDs, id)
 		}
 	}
-
-	return changedNames, nil
+	if !have {
+		set.copyIDs = append(set.copyIDs, conflict.SourceID)
+	}
+	set.changedNames = append(set.changedNames, conflict.Name)
+	set.downgraded = append(set.downgraded, conflict)
+	return nil
 }
 
 // RemoveSource will remove all packages that have a matching source name and
@@ -1057,45 +1210,12 @@ func (r *Repository) PullFrom(db libdb.Database, pool *Pool, sourceRepo *Reposit
 //
 // Distributions tend to split packages across a common identifier/release
 // and this method will allow us to remove "bad actors" from the index.
-func (r *Repository) RemoveSource(db libdb.Database, pool *Pool, sourceID string, release int) error {
-	var deleteIDs []string
-
-	rootBucket := db.Bucket([]byte(DatabaseBucketRepo)).Bucket([]byte(r.ID)).Bucket([]byte(DatabaseBucketPackage))
-
+func (r *Repository) RemoveSource(ctx context.Context, db libdb.Database, pool *Pool, sourceID string, release int) error {
 	if err := r.checkWrite(); err != nil {
 		return err
 	}
 
-	// Grab every package
-	err := rootBucket.ForEach(func(k, v []byte) error {
-		entry := RepoEntry{}
-		if err := rootBucket.Decode(v, &entry); err != nil {
-			return err
-		}
-
-		// Now we must grab every "available" package for this bucket entry
-		for _, id := range entry.Available {
-			poolEntry, err := pool.GetEntry(db, id)
-			if err != nil {
-				return err
-			}
-
-			if poolEntry.Meta.Source.Name != sourceID {
-				continue
-			}
-
-			// if release is -1 we remove all matching source
-			if release > 0 && poolEntry.Meta.GetRelease() != release {
-				continue
-			}
-
-			// We've got a match.
-			deleteIDs = append(deleteIDs, id)
-		}
-
-		return nil
-	})
-
+	deleteIDs, err := r.sourceIDs(db, pool, sourceID, release)
 	if err != nil {
 		return err
 	}
@@ -1107,8 +1227,11 @@ func (r *Repository) RemoveSource(db libdb.Database, pool *Pool, sourceID string
 
 	// Now we'll remove all the defunct IDs. We can't really transaction this as
 	// we're going to rely on on the refcount cycle.
-	for _, id := range deleteIDs {
-		if err = r.UnrefPackage(db, pool, id); err != nil {
+	for i, id := range deleteIDs {
+		if err = stepProgress(ctx, "Remove packages", i, len(deleteIDs)); err != nil {
+			return err
+		}
+		if err = r.UnrefPackage(ctx, db, pool, id); err != nil {
 			return err
 		}
 	}
@@ -1116,16 +1239,12 @@ func (r *Repository) RemoveSource(db libdb.Database, pool *Pool, sourceID string
 	return nil
 }
 
-// CopySourceFrom will find all records within sourceRepo that have both the
-// specified sourceID and release number.
-func (r *Repository) CopySourceFrom(db libdb.Database, pool *Pool, sourceRepo *Repository, sourceID string, release int) error {
-	var copyIDs []string
+// sourceIDs will find every available package ID in this repository with a
+// matching source name and release number. A release of -1 matches all.
+func (r *Repository) sourceIDs(db libdb.Database, pool *Pool, sourceID string, release int) ([]string, error) {
+	var ids []string
 
-	rootBucket := db.Bucket([]byte(DatabaseBucketRepo)).Bucket([]byte(sourceRepo.ID)).Bucket([]byte(DatabaseBucketPackage))
-
-	if err := r.checkWrite(); err != nil {
-		return err
-	}
+	rootBucket := db.Bucket([]byte(DatabaseBucketRepo)).Bucket([]byte(r.ID)).Bucket([]byte(DatabaseBucketPackage))
 
 	// Grab every package
 	err := rootBucket.ForEach(func(k, v []byte) error {
@@ -1145,18 +1264,33 @@ func (r *Repository) CopySourceFrom(db libdb.Database, pool *Pool, sourceRepo *R
 				continue
 			}
 
-			// if release is -1 we copy all matching source
+			// if release is -1 we match all of the source
 			if release > 0 && poolEntry.Meta.GetRelease() != release {
 				continue
 			}
 
 			// We've got a match.
-			copyIDs = append(copyIDs, id)
+			ids = append(ids, id)
 		}
 
 		return nil
 	})
 
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// CopySourceFrom will find all records within sourceRepo that have both the
+// specified sourceID and release number.
+func (r *Repository) CopySourceFrom(ctx context.Context, db libdb.Database, pool *Pool, sourceRepo *Repository, sourceID string, release int) error {
+	if err := r.checkWrite(); err != nil {
+		return err
+	}
+
+	copyIDs, err := sourceRepo.sourceIDs(db, pool, sourceID, release)
 	if err != nil {
 		return err
 	}
@@ -1167,8 +1301,11 @@ func (r *Repository) CopySourceFrom(db libdb.Database, pool *Pool, sourceRepo *R
 	}
 
 	// Now to insert all of those IDs
-	for _, id := range copyIDs {
-		if err = r.RefPackage(db, pool, id); err != nil {
+	for i, id := range copyIDs {
+		if err = stepProgress(ctx, "Copy packages", i, len(copyIDs)); err != nil {
+			return err
+		}
+		if err = r.RefPackage(ctx, db, pool, id); err != nil {
 			return err
 		}
 	}
@@ -1183,7 +1320,7 @@ func (r *Repository) CopySourceFrom(db libdb.Database, pool *Pool, sourceRepo *R
 // to remove from the repository. However, we also need to apply certain
 // modifications to ensure child packages (-dbginfo) are also nuked along
 // with them.
-func (r *Repository) TrimObsolete(db libdb.Database, pool *Pool) error {
+func (r *Repository) TrimObsolete(ctx context.Context, db libdb.Database, pool *Pool) error {
 	r.indexMut.Lock()
 	defer r.indexMut.Unlock()
 
@@ -1191,17 +1328,42 @@ func (r *Repository) TrimObsolete(db libdb.Database, pool *Pool) error {
 		return err
 	}
 
-	if err := r.initDistribution(); err != nil {
+	removalIDs, err := r.obsoleteIDs(ctx, db, pool)
+	if err != nil {
 		return err
 	}
 
+	// Now attempt to unref every one of the packages marked as obsolete
+	for i, id := range removalIDs {
+		if err := stepProgress(ctx, "Remove obsolete packages", i, len(removalIDs)); err != nil {
+			return err
+		}
+		log.WithContext(ctx).WithFields(log.Fields{
+			"repo": r.ID,
+			"id":   id,
+		}).Info("Removing obsolete package")
+		if err := r.UnrefPackage(ctx, db, pool, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// obsoleteIDs will find every package ID that TrimObsolete needs to remove.
+// The caller must hold the indexMut.
+func (r *Repository) obsoleteIDs(ctx context.Context, db libdb.Database, pool *Pool) ([]string, error) {
+	if err := r.initDistribution(ctx); err != nil {
+		return nil, err
+	}
+
 	// All the guys who we're sending to the big bitsink in the sky
 	var removalIDs []string
 
 	// Scream loudly that someones being an eejit and trying to obsolete
 	// packages without a distribution.xml defined.
 	if r.dist == nil {
-		return errors.New("cannot mark obsoletes without distribution.xml")
+		return nil, errors.New("cannot mark obsoletes without distribution.xml")
 	}
 
 	rootBucket := db.Bucket([]byte(DatabaseBucketRepo)).Bucket([]byte(r.ID)).Bucket([]byte(DatabaseBucketPackage))
@@ -1230,7 +1392,7 @@ func (r *Repository) TrimObsolete(db libdb.Database, pool *Pool) error {
 			if r.dist != nil && r.dist.IsObsolete(nom) {
 				if nom != entry.Name {
 					// Scream really loudly, but remove it because its "just" dbginfo.
-					log.WithFields(log.Fields{
+					log.WithContext(ctx).WithFields(log.Fields{
 						"repo": r.ID,
 						"name": poolEntry.Meta.Name,
 					}).Error("Abandoned obsolete package. Removing!")
@@ -1243,17 +1405,36 @@ func (r *Repository) TrimObsolete(db libdb.Database, pool *Pool) error {
 		return nil
 	})
 
+	if err != nil {
+		return nil, err
+	}
+
+	return removalIDs, nil
+}
+
+// TrimPackages will trim back the packages in each package entry to a maximum
+// amount of packages, which helps to combat the issue of rapidly inserting
+// many builds into a repo, i.e. removing old backversions
+func (r *Repository) TrimPackages(ctx context.Context, db libdb.Database, pool *Pool, maxKeep int) error {
+	if err := r.checkWrite(); err != nil {
+		return err
+	}
+
+	removalIDs, err := r.trimIDs(db, pool, maxKeep)
 	if err != nil {
 		return err
 	}
 
 	// Now attempt to unref every one of the packages marked as obsolete
-	for _, id := range removalIDs {
-		log.WithFields(log.Fields{
+	for i, id := range removalIDs {
+		if err := stepProgress(ctx, "Trim packages", i, len(removalIDs)); err != nil {
+			return err
+		}
+		log.WithContext(ctx).WithFields(log.Fields{
 			"repo": r.ID,
 			"id":   id,
-		}).Info("Removing obsolete package")
-		if err := r.UnrefPackage(db, pool, id); err != nil {
+		}).Info("Trimming old package")
+		if err := r.UnrefPackage(ctx, db, pool, id); err != nil {
 			return err
 		}
 	}
@@ -1261,17 +1442,11 @@ func (r *Repository) TrimObsolete(db libdb.Database, pool *Pool) error {
 	return nil
 }
 
-// TrimPackages will trim back the packages in each package entry to a maximum
-// amount of packages, which helps to combat the issue of rapidly inserting
-// many builds into a repo, i.e. removing old backversions
-func (r *Repository) TrimPackages(db libdb.Database, pool *Pool, maxKeep int) error {
-	if err := r.checkWrite(); err != nil {
-		return err
-	}
-
+// trimIDs will find every package ID that TrimPackages needs to remove
+func (r *Repository) trimIDs(db libdb.Database, pool *Pool, maxKeep int) ([]string, error) {
 	// Check for valid maxKeep
 	if maxKeep < 1 {
-		return fmt.Errorf("maxKeep of %d is too small. It Must be greater than or equal to 1", maxKeep)
+		return nil, fmt.Errorf("maxKeep of %d is too small. It Must be greater than or equal to 1", maxKeep)
 	}
 	// All the guys who we're sending to the big bitsink in the sky
 	var removalIDs []string
@@ -1315,19 +1490,8 @@ func (r *Repository) TrimPackages(db libdb.Database, pool *Pool, maxKeep int) er
 	})
 
 	if err != nil {
-		return err
-	}
-
-	// Now attempt to unref every one of the packages marked as obsolete
-	for _, id := range removalIDs {
-		log.WithFields(log.Fields{
-			"repo": r.ID,
-			"id":   id,
-		}).Info("Trimming old package")
-		if err := r.UnrefPackage(db, pool, id); err != nil {
-			return err
-		}
+		return nil, err
 	}
 
-	return nil
+	return removalIDs, nil
 }
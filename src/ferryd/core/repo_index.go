@@ -17,6 +17,7 @@
 package core
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
 	"os"
@@ -32,12 +33,12 @@ import (
 
 // initDistribution will look for the distribution.xml file which will define
 // the all-important Obsoletes set
-func (r *Repository) initDistribution() error {
+func (r *Repository) initDistribution(ctx context.Context) error {
 	r.dist = nil
 
 	dpath := filepath.Join(r.assetPath, "distribution.xml")
 	if !PathExists(dpath) {
-		log.WithFields(log.Fields{
+		log.WithContext(ctx).WithFields(log.Fields{
 			"repo": r.ID,
 		}).Warning("No distribution.xml defined")
 		return nil
@@ -52,7 +53,7 @@ func (r *Repository) initDistribution() error {
 
 // emitDistribution is responsible for loading the distribution.xml file from
 // the assets store and merging it into the final index
-func (r *Repository) emitDistribution(encoder *xml.Encoder) error {
+func (r *Repository) emitDistribution(ctx context.Context, encoder *xml.Encoder) error {
 	elem := xml.StartElement{
 		Name: xml.Name{
 			Local: "Distribution",
@@ -63,10 +64,10 @@ func (r *Repository) emitDistribution(encoder *xml.Encoder) error {
 
 // emitComponents is responsible for loading the components.xml file from
 // the assets store and merging it into the final index
-func (r *Repository) emitComponents(encoder *xml.Encoder) error {
+func (r *Repository) emitComponents(ctx context.Context, encoder *xml.Encoder) error {
 	dpath := filepath.Join(r.assetPath, "components.xml")
 	if !PathExists(dpath) {
-		log.WithFields(log.Fields{
+		log.WithContext(ctx).WithFields(log.Fields{
 			"repo": r.ID,
 		}).Warning("No components.xml defined")
 		return nil
@@ -94,10 +95,10 @@ func (r *Repository) emitComponents(encoder *xml.Encoder) error {
 
 // emitGroups is responsible for loading the groups.xml file from
 // the assets store and merging it into the final index
-func (r *Repository) emitGroups(encoder *xml.Encoder) error {
+func (r *Repository) emitGroups(ctx context.Context, encoder *xml.Encoder) error {
 	dpath := filepath.Join(r.assetPath, "groups.xml")
 	if !PathExists(dpath) {
-		log.WithFields(log.Fields{
+		log.WithContext(ctx).WithFields(log.Fields{
 			"repo": r.ID,
 		}).Warning("No groups.xml defined")
 		return nil
@@ -165,7 +166,7 @@ func (r *Repository) pushDeltaPackages(db libdb.Database, pool *Pool, entry *Poo
 	return nil
 }
 
-func (r *Repository) emitIndexPackage(db libdb.Database, pool *Pool, pkg string, encoder *xml.Encoder, entry *PoolEntry) error {
+func (r *Repository) emitIndexPackage(ctx context.Context, db libdb.Database, pool *Pool, pkg string, encoder *xml.Encoder, entry *PoolEntry) error {
 	// Wrap every output item as Package
 	elem := xml.StartElement{
 		Name: xml.Name{
@@ -183,7 +184,7 @@ func (r *Repository) emitIndexPackage(db libdb.Database, pool *Pool, pkg string,
 	// dbginfo trick, warn in the console
 	if r.dist != nil && r.dist.IsObsolete(nom) {
 		if nom != entry.Name {
-			log.WithFields(log.Fields{
+			log.WithContext(ctx).WithFields(log.Fields{
 				"repo": r.ID,
 				"id":   pkg,
 			}).Error("Abandoned obsolete package, please run 'trim obsolete'")
@@ -196,7 +197,7 @@ func (r *Repository) emitIndexPackage(db libdb.Database, pool *Pool, pkg string,
 	if entry.Meta.RuntimeDependencies != nil && r.dist != nil {
 		for _, p := range *entry.Meta.RuntimeDependencies {
 			if r.dist.IsObsolete(p.Name) {
-				log.WithFields(log.Fields{
+				log.WithContext(ctx).WithFields(log.Fields{
 					"repo":       r.ID,
 					"package":    entry.Name,
 					"dependency": p.Name,
@@ -215,7 +216,7 @@ func (r *Repository) emitIndexPackage(db libdb.Database, pool *Pool, pkg string,
 
 // emitIndex does the heavy lifting of writing to the given file descriptor,
 // i.e. serialising the DB repo out to the index file
-func (r *Repository) emitIndex(db libdb.Database, pool *Pool, file *os.File) error {
+func (r *Repository) emitIndex(ctx context.Context, db libdb.Database, pool *Pool, file *os.File) error {
 	var pkgIds []string
 	rootBucket := db.Bucket([]byte(DatabaseBucketRepo)).Bucket([]byte(r.ID)).Bucket([]byte(DatabaseBucketPackage))
 
@@ -249,7 +250,7 @@ func (r *Repository) emitIndex(db libdb.Database, pool *Pool, file *os.File) err
 	}
 
 	// Ensure distribution is at the head
-	if err := r.emitDistribution(encoder); err != nil {
+	if err := r.emitDistribution(ctx, encoder); err != nil {
 		return err
 	}
 
@@ -258,18 +259,18 @@ func (r *Repository) emitIndex(db libdb.Database, pool *Pool, file *os.File) err
 		if err != nil {
 			return err
 		}
-		if err = r.emitIndexPackage(db, pool, pkg, encoder, entry); err != nil {
+		if err = r.emitIndexPackage(ctx, db, pool, pkg, encoder, entry); err != nil {
 			return err
 		}
 	}
 
 	// Stick in the components
-	if err := r.emitComponents(encoder); err != nil {
+	if err := r.emitComponents(ctx, encoder); err != nil {
 		return err
 	}
 
 	// Stick in the groups ..
-	if err := r.emitGroups(encoder); err != nil {
+	if err := r.emitGroups(ctx, encoder); err != nil {
 		return err
 	}
 
@@ -282,8 +283,9 @@ func (r *Repository) emitIndex(db libdb.Database, pool *Pool, file *os.File) err
 }
 
 // Index will attempt to write the eopkg index out to disk
-// This only requires a read-only database view
-func (r *Repository) Index(db libdb.Database, pool *Pool) error {
+// This only requires a read-only database view. Compression and the CDN purge
+// are abandoned if the context is done first, leaving the old index in place.
+func (r *Repository) Index(ctx context.Context, db libdb.Database, pool *Pool) error {
 	r.indexMut.Lock()
 	defer r.indexMut.Unlock()
 	var errAbort error
@@ -300,7 +302,7 @@ func (r *Repository) Index(db libdb.Database, pool *Pool) error {
 	defer func() {
 		if errAbort != nil {
 			for k := range mapping {
-				log.WithFields(log.Fields{
+				log.WithContext(ctx).WithFields(log.Fields{
 					"id":    r.ID,
 					"path":  k,
 					"error": errAbort,
@@ -310,10 +312,30 @@ func (r *Repository) Index(db libdb.Database, pool *Pool) error {
 		}
 	}()
 
-	if err := r.initDistribution(); err != nil {
+	if err := r.initDistribution(ctx); err != nil {
 		return err
 	}
 
+	// Never publish an index that users can't install from
+	if r.IsInstallGated() {
+		report, err := r.checkInstallable(ctx, db, pool)
+		if err != nil {
+			return err
+		}
+		for _, issue := range report.Issues {
+			log.WithContext(ctx).WithFields(log.Fields{
+				"repo":       r.ID,
+				"package":    issue.Package,
+				"dependency": issue.Dependency,
+				"kind":       issue.Kind,
+				"detail":     issue.Detail,
+			}).Error("Uninstallable package blocks index")
+		}
+		if len(report.Issues) > 0 {
+			return fmt.Errorf("%w: %d unresolved dependencies in '%s'", ErrNotInstallable, len(report.Issues), r.ID)
+		}
+	}
+
 	// Create index file
 	f, err := os.Create(indexPath)
 	if err != nil {
@@ -322,7 +344,7 @@ func (r *Repository) Index(db libdb.Database, pool *Pool) error {
 	}
 
 	// Write the index file
-	errAbort = r.emitIndex(db, pool, f)
+	errAbort = r.emitIndex(ctx, db, pool, f)
 	f.Close()
 	if errAbort != nil {
 		return errAbort
@@ -352,7 +374,8 @@ func (r *Repository) Index(db libdb.Database, pool *Pool) error {
 	indexPathXzFinal := filepath.Join(r.path, "eopkg-index.xml.xz")
 	mapping[indexPathXz] = indexPathXzFinal
 
-	if errAbort = libeopkg.XzFile(indexPath, true); errAbort != nil {
+	reportProgress(ctx, "Compress index", 0, 0)
+	if errAbort = libeopkg.XzFile(ctx, indexPath, true); errAbort != nil {
 		return errAbort
 	}
 
@@ -381,7 +404,7 @@ func (r *Repository) Index(db libdb.Database, pool *Pool) error {
 	indexPathZstFinal := filepath.Join(r.path, "eopkg-index.xml.zst")
 	mapping[indexPathZst] = indexPathZstFinal
 
-	if errAbort = libeopkg.ZstdFile(indexPath, true); errAbort != nil {
+	if errAbort = libeopkg.ZstdFile(ctx, indexPath, true); errAbort != nil {
 		return errAbort
 	}
 
@@ -411,7 +434,8 @@ func (r *Repository) Index(db libdb.Database, pool *Pool) error {
 		}
 	}
 
-	errAbort = purgeCloudflare(r.ID)
+	reportProgress(ctx, "Purge CDN", 0, 0)
+	errAbort = purgeCloudflare(ctx, r.ID)
 	if errAbort != nil {
 		return errAbort
 	}
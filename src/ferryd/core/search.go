@@ -0,0 +1,159 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package core
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/getsolus/ferryd/src/libdb"
+	"github.com/getsolus/ferryd/src/libeopkg"
+)
+
+// A SearchQuery describes the packages to find. Every non-empty field must
+// match for a package to be returned.
+type SearchQuery struct {
+	Repos     []string // Limit the search to these repositories, all when empty
+	Name      string   // Glob pattern for the package name, i.e. "openssl*"
+	Source    string   // Exact source name
+	Release   int      // Exact release number
+	Packager  string   // Case insensitive substring of the packager name or email
+	License   string   // Exact license identifier
+	Component string   // Exact component (PartOf)
+	PkgConfig string   // Exact pkgconfig name, 32-bit or native
+	Published bool     // Only match the published package for each name
+}
+
+// A SearchResult is a single package matching a SearchQuery
+type SearchResult struct {
+	Repo      string // Repository containing the package
+	Name      string // Package name
+	ID        string // Package ID
+	Version   string // Version of the package
+	Release   int    // Release of the package
+	Published bool   // Whether this is the published package for the name
+}
+
+// matchPackager will determine if the needle is found in the packager details
+func matchPackager(packager libeopkg.Packager, needle string) bool {
+	needle = strings.ToLower(needle)
+	return strings.Contains(strings.ToLower(packager.Name), needle) || strings.Contains(strings.ToLower(packager.Email), needle)
+}
+
+// matchPkgConfig will determine if the package provides the pkgconfig name
+func matchPkgConfig(provides *libeopkg.Provides, name string) bool {
+	if provides == nil {
+		return false
+	}
+	for _, pc := range provides.PkgConfig {
+		if pc == name {
+			return true
+		}
+	}
+	for _, pc := range provides.PkgConfig32 {
+		if pc == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches will determine if the package metadata satisfies the query
+func (q *SearchQuery) Matches(meta *libeopkg.MetaPackage) bool {
+	if q.Name != "" {
+		if ok, _ := path.Match(q.Name, meta.Name); !ok {
+			return false
+		}
+	}
+	if q.Source != "" && meta.Source.Name != q.Source {
+		return false
+	}
+	if q.Release != 0 && meta.GetRelease() != q.Release {
+		return false
+	}
+	if q.Packager != "" && !matchPackager(meta.Source.Packager, q.Packager) {
+		return false
+	}
+	if q.License != "" {
+		found := false
+		for _, license := range meta.License {
+			if license == q.License {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if q.Component != "" && meta.PartOf != q.Component {
+		return false
+	}
+	if q.PkgConfig != "" && !matchPkgConfig(meta.Provides, q.PkgConfig) {
+		return false
+	}
+	return true
+}
+
+// Search will find every package within the repository matching the query
+func (r *Repository) Search(db libdb.Database, pool *Pool, query *SearchQuery) ([]SearchResult, error) {
+	entries, err := r.getEntries(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []SearchResult
+	for _, entry := range entries {
+		// Cheap rejection before we hit the pool
+		if query.Name != "" {
+			if ok, _ := path.Match(query.Name, entry.Name); !ok {
+				continue
+			}
+		}
+		for _, id := range entry.Available {
+			published := id == entry.Published
+			if query.Published && !published {
+				continue
+			}
+			poolEntry, err := pool.GetEntry(db, id)
+			if err != nil {
+				return nil, err
+			}
+			if !query.Matches(poolEntry.Meta) {
+				continue
+			}
+			ret = append(ret, SearchResult{
+				Repo:      r.ID,
+				Name:      entry.Name,
+				ID:        id,
+				Version:   poolEntry.Meta.GetVersion(),
+				Release:   poolEntry.Meta.GetRelease(),
+				Published: published,
+			})
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Name != ret[j].Name {
+			return ret[i].Name < ret[j].Name
+		}
+		return ret[i].Release > ret[j].Release
+	})
+
+	return ret, nil
+}
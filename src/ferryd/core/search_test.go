@@ -0,0 +1,73 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package core
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	manager := initTestRepo(t, "unstable", integrityTestPackage, snapshotTestPackage)
+	defer manager.Close()
+
+	if err := manager.CreateRepo(context.Background(), "stable"); err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	if err := manager.AddPackages(context.Background(), "stable", []string{integrityTestPackage}, false); err != nil {
+		t.Fatalf("Failed to add packages: %v", err)
+	}
+
+	results, err := manager.Search(&SearchQuery{Name: "na*", Source: "nano"})
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got: %+v", results)
+	}
+	if results[0].Repo != "stable" || results[1].Repo != "unstable" || results[1].Release != 75 || !results[1].Published {
+		t.Fatalf("Unexpected ordering of results: %+v", results)
+	}
+
+	results, err = manager.Search(&SearchQuery{Name: "nano", Release: 63})
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected release 63 in both repositories, got: %+v", results)
+	}
+
+	results, err = manager.Search(&SearchQuery{Repos: []string{"unstable"}, Published: true})
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != snapshotTestID {
+		t.Fatalf("Expected only the published package, got: %+v", results)
+	}
+
+	results, err = manager.Search(&SearchQuery{Packager: "no such packager"})
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("Expected no results, got: %+v", results)
+	}
+
+	if _, err = manager.Search(&SearchQuery{Repos: []string{"missing"}}); err == nil {
+		t.Fatalf("Should not be able to search a missing repository")
+	}
+}
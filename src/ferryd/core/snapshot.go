@@ -0,0 +1,378 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getsolus/ferryd/src/libdb"
+)
+
+const (
+	// DatabaseBucketSnapshot is the path to the bucket holding the snapshots
+	// of every repository
+	DatabaseBucketSnapshot = "snapshot"
+
+	// snapshotKeySeparator divides the repository ID and snapshot name within
+	// a snapshot key. Repository IDs are also directory names, so can't hold it.
+	snapshotKeySeparator = "\x00"
+
+	// SnapshotSchemaVersion is the current schema version for a RepoSnapshot
+	SnapshotSchemaVersion = "1.0"
+)
+
+// A RepoSnapshot is an immutable copy of every RepoEntry within a repository,
+// along with its assets. Each package held by the snapshot is referenced in
+// the pool so that it cannot be freed while the snapshot exists.
+type RepoSnapshot struct {
+	SchemaVersion string            // Version used when this snapshot was created
+	Name          string            // Name of the snapshot (unique per repository)
+	Created       time.Time         // When the snapshot was taken
+	Entries       []RepoEntry       // Full set of entries at the time of the snapshot
+	Assets        map[string][]byte // Asset files keyed by basename
+}
+
+// ids returns every pool ID referenced by the snapshot
+func (s *RepoSnapshot) ids() []string {
+	var ret []string
+	seen := make(map[string]bool)
+	for _, entry := range s.Entries {
+		for _, id := range append(append([]string{}, entry.Available...), entry.Deltas...) {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			ret = append(ret, id)
+		}
+	}
+	return ret
+}
+
+// snapshotBucket returns the bucket holding the snapshots of every repository.
+// Buckets are only key prefixes, so nesting them within the repository bucket
+// would let a repository see the snapshots of another with a longer ID.
+func snapshotBucket(db libdb.Database) libdb.Database {
+	return db.Bucket([]byte(DatabaseBucketSnapshot))
+}
+
+// snapshotPrefix returns the prefix of the keys of our snapshots
+func (r *Repository) snapshotPrefix() []byte {
+	return []byte(r.ID + snapshotKeySeparator)
+}
+
+// snapshotKey returns the key of the named snapshot
+func (r *Repository) snapshotKey(name string) []byte {
+	return append(r.snapshotPrefix(), name...)
+}
+
+// GetSnapshot will return the named snapshot if it exists
+func (r *Repository) GetSnapshot(db libdb.Database, name string) (*RepoSnapshot, error) {
+	snapshot := &RepoSnapshot{}
+	if err := snapshotBucket(db).GetObject(r.snapshotKey(name), snapshot); err != nil {
+		return nil, fmt.Errorf("The specified snapshot '%s' does not exist in '%s'", name, r.ID)
+	}
+	return snapshot, nil
+}
+
+// GetSnapshots will return every snapshot for this repository
+func (r *Repository) GetSnapshots(db libdb.Database) ([]*RepoSnapshot, error) {
+	var ret []*RepoSnapshot
+	prefix := r.snapshotPrefix()
+	err := snapshotBucket(db).View(func(db libdb.ReadOnlyView) error {
+		return db.ForEach(func(key, value []byte) error {
+			if !bytes.HasPrefix(key, prefix) {
+				return nil
+			}
+			snapshot := &RepoSnapshot{}
+			if err := db.Decode(value, snapshot); err != nil {
+				return err
+			}
+			ret = append(ret, snapshot)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// getEntries will return a copy of every entry in the repository
+func (r *Repository) getEntries(db libdb.Database) ([]RepoEntry, error) {
+	var ret []RepoEntry
+	rootBucket := db.Bucket([]byte(DatabaseBucketRepo)).Bucket([]byte(r.ID)).Bucket([]byte(DatabaseBucketPackage))
+	err := rootBucket.ForEach(func(k, v []byte) error {
+		entry := RepoEntry{}
+		if err := rootBucket.Decode(v, &entry); err != nil {
+			return err
+		}
+		ret = append(ret, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// CreateSnapshot will record the current state of the repository under the
+// given name, taking a pool reference for every package.
+func (r *Repository) CreateSnapshot(db libdb.Database, pool *Pool, name string) error {
+	r.insertMut.Lock()
+	defer r.insertMut.Unlock()
+
+	if name == "" {
+		return fmt.Errorf("snapshot name cannot be empty")
+	}
+	if has, _ := snapshotBucket(db).HasObject(r.snapshotKey(name)); has {
+		return fmt.Errorf("The specified snapshot '%s' already exists in '%s'", name, r.ID)
+	}
+
+	entries, err := r.getEntries(db)
+	if err != nil {
+		return err
+	}
+
+	snapshot := &RepoSnapshot{
+		SchemaVersion: SnapshotSchemaVersion,
+		Name:          name,
+		Created:       time.Now().UTC(),
+		Entries:       entries,
+		Assets:        make(map[string][]byte),
+	}
+
+	for _, asset := range repoAssets {
+		assetPath := filepath.Join(r.assetPath, asset)
+		if !PathExists(assetPath) {
+			continue
+		}
+		data, err := os.ReadFile(assetPath)
+		if err != nil {
+			return err
+		}
+		snapshot.Assets[asset] = data
+	}
+
+	// Each ID is only listed once, so the stale reads within the batch are fine
+	return pool.update(db, func(db libdb.Database) error {
+		for _, id := range snapshot.ids() {
+			if err := pool.refEntry(db, id); err != nil {
+				return err
+			}
+		}
+		return snapshotBucket(db).PutObject(r.snapshotKey(name), snapshot)
+	})
+}
+
+// DeleteSnapshot will remove the named snapshot, releasing its pool references
+func (r *Repository) DeleteSnapshot(ctx context.Context, db libdb.Database, pool *Pool, name string) error {
+	snapshot, err := r.GetSnapshot(db, name)
+	if err != nil {
+		return err
+	}
+
+	return pool.update(db, func(db libdb.Database) error {
+		for _, id := range snapshot.ids() {
+			if err := pool.unrefEntry(ctx, db, id); err != nil {
+				return err
+			}
+		}
+		return snapshotBucket(db).DeleteObject(r.snapshotKey(name))
+	})
+}
+
+// deleteSnapshots will remove every snapshot for this repository. Each one
+// is deleted in its own transaction as they may share IDs.
+func (r *Repository) deleteSnapshots(ctx context.Context, db libdb.Database, pool *Pool) error {
+	snapshots, err := r.GetSnapshots(db)
+	if err != nil {
+		return err
+	}
+	for _, snapshot := range snapshots {
+		if err := r.DeleteSnapshot(ctx, db, pool, snapshot.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RestoreSnapshot will reset the repository to the exact state held by the
+// named snapshot. All database changes are applied in a single transaction,
+// so the repository is never left in a partially restored state. The caller
+// must reindex the repository afterwards. If ctx is done before the changes
+// are committed, none of them are made.
+func (r *Repository) RestoreSnapshot(ctx context.Context, db libdb.Database, pool *Pool, name string) error {
+	r.insertMut.Lock()
+	defer r.insertMut.Unlock()
+
+	if err := r.checkWrite(); err != nil {
+		return err
+	}
+
+	snapshot, err := r.GetSnapshot(db, name)
+	if err != nil {
+		return err
+	}
+
+	current, err := r.getEntries(db)
+	if err != nil {
+		return err
+	}
+	currentSnapshot := &RepoSnapshot{Entries: current}
+
+	// Work out the net change per ID
+	wanted := make(map[string]bool)
+	for _, id := range snapshot.ids() {
+		wanted[id] = true
+	}
+	have := make(map[string]bool)
+	for _, id := range currentSnapshot.ids() {
+		have[id] = true
+	}
+	var refIDs, unrefIDs []string
+	for id := range wanted {
+		if !have[id] {
+			refIDs = append(refIDs, id)
+		}
+	}
+	for id := range have {
+		if !wanted[id] {
+			unrefIDs = append(unrefIDs, id)
+		}
+	}
+
+	// Link everything we're about to gain before touching the DB. The
+	// snapshot holds a reference, so the pool file must still exist.
+	for i, id := range refIDs {
+		if err := stepProgress(ctx, "Link packages", i, len(refIDs)); err != nil {
+			return err
+		}
+		entry, err := pool.GetEntry(db, id)
+		if err != nil {
+			return err
+		}
+		targetPath := filepath.Join(r.path, entry.Meta.GetPathComponent(), id)
+		if PathExists(targetPath) {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(targetPath), 00755); err != nil {
+			return err
+		}
+		if err := LinkOrCopyFile(pool.GetMetaPoolPath(id, entry.Meta), targetPath, false); err != nil {
+			return err
+		}
+	}
+
+	// Learn our paths now as the pool entries may be gone after the unref
+	var removals []string
+	for _, id := range unrefIDs {
+		entry, err := pool.GetEntry(db, id)
+		if err != nil {
+			return err
+		}
+		removals = append(removals, filepath.Join(r.path, entry.Meta.GetPathComponent(), id))
+	}
+
+	keep := make(map[string]bool)
+	for _, entry := range snapshot.Entries {
+		keep[entry.Name] = true
+	}
+
+	err = pool.update(db, func(db libdb.Database) error {
+		rootBucket := db.Bucket([]byte(DatabaseBucketRepo)).Bucket([]byte(r.ID)).Bucket([]byte(DatabaseBucketPackage))
+		for _, entry := range current {
+			if keep[entry.Name] {
+				continue
+			}
+			if err := rootBucket.DeleteObject([]byte(entry.Name)); err != nil {
+				return err
+			}
+		}
+		for i := range snapshot.Entries {
+			if err := r.putEntry(db, &snapshot.Entries[i]); err != nil {
+				return err
+			}
+		}
+		for _, id := range refIDs {
+			if err := pool.refEntry(db, id); err != nil {
+				return err
+			}
+		}
+		for _, id := range unrefIDs {
+			if err := pool.unrefEntry(ctx, db, id); err != nil {
+				return err
+			}
+		}
+		// Last chance to back out, the batch is discarded on error
+		return ctx.Err()
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, p := range removals {
+		if err := os.Remove(p); err != nil {
+			log.WithContext(ctx).WithFields(log.Fields{
+				"repo":  r.ID,
+				"path":  p,
+				"error": err,
+			}).Warning("Failed to remove target for package")
+			continue
+		}
+		RemovePackageParents(p)
+	}
+
+	// Finally put the assets back exactly as they were
+	for _, asset := range repoAssets {
+		assetPath := filepath.Join(r.assetPath, asset)
+		data, ok := snapshot.Assets[asset]
+		if !ok {
+			if PathExists(assetPath) {
+				if err := os.Remove(assetPath); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := os.WriteFile(assetPath, data, 00644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// countSnapshotRefs will count every pool reference held by our snapshots
+func (r *Repository) countSnapshotRefs(db libdb.Database, refs map[string]uint64) error {
+	snapshots, err := r.GetSnapshots(db)
+	if err != nil {
+		return err
+	}
+	for _, snapshot := range snapshots {
+		for _, id := range snapshot.ids() {
+			refs[id]++
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package core
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	snapshotTestPackage = "../../libeopkg/testdata/delta/nano-2.8.5-75-1-x86_64.eopkg"
+	snapshotTestID      = "nano-2.8.5-75-1-x86_64.eopkg"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	manager := initTestRepo(t, "unstable", integrityTestPackage)
+	defer manager.Close()
+
+	if err := manager.CreateSnapshot("unstable", "before"); err != nil {
+		t.Fatalf("Failed to create snapshot: %v", err)
+	}
+	if err := manager.CreateSnapshot("unstable", "before"); err == nil {
+		t.Fatalf("Snapshots should be immutable")
+	}
+
+	// Now mess with the repository
+	if err := manager.AddPackages(context.Background(), "unstable", []string{snapshotTestPackage}, false); err != nil {
+		t.Fatalf("Failed to add package: %v", err)
+	}
+
+	if err := manager.RestoreSnapshot(context.Background(), "unstable", "before"); err != nil {
+		t.Fatalf("Failed to restore snapshot: %v", err)
+	}
+
+	pkgs, err := manager.GetPackages("unstable", "nano")
+	if err != nil {
+		t.Fatalf("Failed to get packages: %v", err)
+	}
+	if len(pkgs) != 1 || pkgs[0].GetRelease() != 63 {
+		t.Fatalf("Repository was not restored, got %d packages", len(pkgs))
+	}
+
+	repo, err := manager.GetRepo("unstable")
+	if err != nil {
+		t.Fatalf("Failed to get repository: %v", err)
+	}
+	if PathExists(filepath.Join(repo.path, "n", "nano", snapshotTestID)) {
+		t.Fatalf("Restored repository still has the newer package on disk")
+	}
+	if _, err = manager.GetPoolEntry(snapshotTestID); err == nil {
+		t.Fatalf("Unreferenced package should have left the pool")
+	}
+
+	report, err := manager.CheckIntegrity(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to check integrity: %v", err)
+	}
+	if len(report.Issues) != 0 {
+		t.Fatalf("Expected a clean report after restore, got: %v", report.Issues)
+	}
+
+	entry, err := manager.pool.GetEntry(manager.db, integrityTestID)
+	if err != nil {
+		t.Fatalf("Failed to get pool entry: %v", err)
+	}
+	if entry.RefCount != 2 {
+		t.Fatalf("Snapshot should hold a reference, refcount is %d", entry.RefCount)
+	}
+
+	if err = manager.DeleteSnapshot(context.Background(), "unstable", "before"); err != nil {
+		t.Fatalf("Failed to delete snapshot: %v", err)
+	}
+	snapshots, err := manager.GetSnapshots("unstable")
+	if err != nil {
+		t.Fatalf("Failed to list snapshots: %v", err)
+	}
+	if len(snapshots) != 0 {
+		t.Fatalf("Expected no snapshots, got %d", len(snapshots))
+	}
+}
+
+func TestSnapshotDeleteRepo(t *testing.T) {
+	manager := initTestRepo(t, "unstable", integrityTestPackage)
+	defer manager.Close()
+
+	for _, name := range []string{"one", "two"} {
+		if err := manager.CreateSnapshot("unstable", name); err != nil {
+			t.Fatalf("Failed to create snapshot: %v", err)
+		}
+	}
+
+	if err := manager.DeleteRepo(context.Background(), "unstable"); err != nil {
+		t.Fatalf("Failed to delete repository: %v", err)
+	}
+
+	items, err := manager.GetPoolItems()
+	if err != nil {
+		t.Fatalf("Failed to get pool items: %v", err)
+	}
+	if len(items) != 0 {
+		t.Fatalf("Pool should be empty after deleting the repository, got %d", len(items))
+	}
+}
+
+func TestSnapshotSimilarRepoIDs(t *testing.T) {
+	manager := initTestRepo(t, "unstable", integrityTestPackage)
+	defer manager.Close()
+
+	// Keys of the longer repository begin with those of the shorter
+	if err := manager.CloneRepo(context.Background(), "unstable", "unstable-snapshot", false); err != nil {
+		t.Fatalf("Failed to clone repository: %v", err)
+	}
+	if err := manager.CreateSnapshot("unstable-snapshot", "before"); err != nil {
+		t.Fatalf("Failed to create snapshot: %v", err)
+	}
+
+	snapshots, err := manager.GetSnapshots("unstable")
+	if err != nil {
+		t.Fatalf("Failed to list snapshots: %v", err)
+	}
+	if len(snapshots) != 0 {
+		t.Fatalf("Snapshots of another repository were listed: %d", len(snapshots))
+	}
+	if err = manager.DeleteRepo(context.Background(), "unstable"); err != nil {
+		t.Fatalf("Failed to delete repository: %v", err)
+	}
+	if snapshots, err = manager.GetSnapshots("unstable-snapshot"); err != nil || len(snapshots) != 1 {
+		t.Fatalf("Deleting a repository must keep the snapshots of others: %d (%v)", len(snapshots), err)
+	}
+}
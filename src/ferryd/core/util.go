@@ -17,6 +17,7 @@
 package core
 
 import (
+	"context"
 	"crypto/sha1"
 	"crypto/sha256"
 	"encoding/hex"
@@ -156,13 +157,13 @@ func PathExists(path string) bool {
 
 // ProduceDelta will attempt to batch the delta production between the
 // two listed file paths and then copy it into the final targetPath
-func ProduceDelta(tmpDir, oldPackage, newPackage, targetPath string) error {
+func ProduceDelta(ctx context.Context, tmpDir, oldPackage, newPackage, targetPath string) error {
 	del, err := libeopkg.NewDeltaProducer(tmpDir, oldPackage, newPackage)
 	if err != nil {
 		return err
 	}
 	defer del.Close()
-	path, err := del.Commit()
+	path, err := del.Commit(ctx)
 	if err != nil {
 		return err
 	}
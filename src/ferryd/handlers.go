@@ -19,12 +19,15 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"runtime"
+	"strconv"
 
 	"github.com/julienschmidt/httprouter"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/getsolus/ferryd/src/ferryd/core"
 	"github.com/getsolus/ferryd/src/ferryd/jobs"
 	"github.com/getsolus/ferryd/src/libferry"
 )
@@ -62,6 +65,33 @@ func (s *Server) sendStockError(err error, w http.ResponseWriter, r *http.Reques
 	w.Write(buf.Bytes())
 }
 
+// mutating guards a handler that makes changes, refusing the request while
+// in maintenance unless the client overrides it
+func (s *Server) mutating(handle httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		pause := s.jproc.PauseState()
+		if !pause.Maintenance || r.URL.Query().Get(libferry.OverrideMaintenance) != "" {
+			handle(w, r, p)
+			return
+		}
+		msg := "ferryd is in maintenance"
+		if pause.Reason != "" {
+			msg += ": " + pause.Reason
+		}
+		response := libferry.Response{
+			Error:       true,
+			ErrorString: msg + " (override to make changes anyway)",
+		}
+		buf := bytes.Buffer{}
+		if err := json.NewEncoder(&buf).Encode(&response); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write(buf.Bytes())
+	}
+}
+
 // GetStatus will return the current status of the ferryd instance
 func (s *Server) GetStatus(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	ret := libferry.StatusRequest{
@@ -69,8 +99,8 @@ func (s *Server) GetStatus(w http.ResponseWriter, r *http.Request, _ httprouter.
 		Version:     libferry.Version,
 	}
 
-	// Stuff the active jobs in
-	jo, err := s.store.ActiveJobs()
+	// Stuff the active jobs in, with their progress
+	jo, err := s.jproc.ActiveJobs()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -91,6 +121,9 @@ func (s *Server) GetStatus(w http.ResponseWriter, r *http.Request, _ httprouter.
 	}
 	ret.CompletedJobs = cj
 
+	ret.ScheduledJobs = s.jproc.ScheduledJobs()
+	ret.Paused = s.jproc.PauseState()
+
 	buf := bytes.Buffer{}
 	if err := json.NewEncoder(&buf).Encode(&ret); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -99,6 +132,160 @@ func (s *Server) GetStatus(w http.ResponseWriter, r *http.Request, _ httprouter.
 	w.Write(buf.Bytes())
 }
 
+// pushJob will schedule the job and respond with the ID it was assigned
+func (s *Server) pushJob(job *jobs.JobEntry, w http.ResponseWriter, r *http.Request) {
+	id, err := s.jproc.PushJob(job)
+	if err != nil {
+		s.sendStockError(err, w, r)
+		return
+	}
+	buf := bytes.Buffer{}
+	if err := json.NewEncoder(&buf).Encode(&libferry.JobResponse{ID: id}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(buf.Bytes())
+}
+
+// jobID will parse the job ID from the request, responding with an error if
+// it isn't valid
+func (s *Server) jobID(w http.ResponseWriter, r *http.Request, p httprouter.Params) (uint64, bool) {
+	id, err := strconv.ParseUint(p.ByName("id"), 10, 64)
+	if err != nil {
+		s.sendStockError(fmt.Errorf("invalid job ID: %v", err), w, r)
+		return 0, false
+	}
+	return id, true
+}
+
+// CancelJob will remove a job from the queue before it starts
+func (s *Server) CancelJob(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	id, ok := s.jobID(w, r, p)
+	if !ok {
+		return
+	}
+	log.WithFields(log.Fields{"id": id}).Info("Job cancellation requested")
+	if err := s.jproc.CancelJob(id); err != nil {
+		s.sendStockError(err, w, r)
+	}
+}
+
+// Pause will stop jobs being started from one or both queues
+func (s *Server) Pause(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req := libferry.PauseRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.WithFields(log.Fields{"queue": req.Queue}).Info("Queue pause requested")
+	if err := s.jproc.Pause(req.Queue); err != nil {
+		s.sendStockError(err, w, r)
+	}
+}
+
+// Resume will allow jobs to be started from one or both queues again
+func (s *Server) Resume(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req := libferry.PauseRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.WithFields(log.Fields{"queue": req.Queue}).Info("Queue resume requested")
+	if err := s.jproc.Resume(req.Queue); err != nil {
+		s.sendStockError(err, w, r)
+	}
+}
+
+// SetMaintenance will enter or leave maintenance
+func (s *Server) SetMaintenance(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req := libferry.MaintenanceRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := s.jproc.SetMaintenance(req.Enabled, req.Reason); err != nil {
+		s.sendStockError(err, w, r)
+	}
+}
+
+// RequeueJob will push a failed job again, responding with the new job ID
+func (s *Server) RequeueJob(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	id, ok := s.jobID(w, r, p)
+	if !ok {
+		return
+	}
+	log.WithFields(log.Fields{"id": id}).Info("Job requeue requested")
+	newID, err := s.jproc.RequeueJob(id)
+	if err != nil {
+		s.sendStockError(err, w, r)
+		return
+	}
+	buf := bytes.Buffer{}
+	if err := json.NewEncoder(&buf).Encode(&libferry.JobResponse{ID: newID}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(buf.Bytes())
+}
+
+// GetJob will respond with the state of a single job
+func (s *Server) GetJob(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	id, ok := s.jobID(w, r, p)
+	if !ok {
+		return
+	}
+	job, err := s.jproc.GetJob(id)
+	if err != nil {
+		s.sendStockError(err, w, r)
+		return
+	}
+	buf := bytes.Buffer{}
+	if err := json.NewEncoder(&buf).Encode(&libferry.JobRequest{Job: job}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(buf.Bytes())
+}
+
+// GetJobLog will return the messages logged by the given job
+func (s *Server) GetJobLog(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	id, ok := s.jobID(w, r, p)
+	if !ok {
+		return
+	}
+	entries, err := s.jproc.JobLog(id)
+	if err != nil {
+		s.sendStockError(err, w, r)
+		return
+	}
+	buf := bytes.Buffer{}
+	if err := json.NewEncoder(&buf).Encode(&libferry.JobLogRequest{Log: entries}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(buf.Bytes())
+}
+
+// History will respond with every finished job matching the query
+func (s *Server) History(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req := libferry.HistoryRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	jobs, err := s.store.History(&req)
+	if err != nil {
+		s.sendStockError(err, w, r)
+		return
+	}
+	buf := bytes.Buffer{}
+	if err := json.NewEncoder(&buf).Encode(&libferry.HistoryResultsRequest{Jobs: jobs}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(buf.Bytes())
+}
+
 // GetRepos will attempt to serialise our known repositories into a response
 func (s *Server) GetRepos(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	req := libferry.RepoListingRequest{}
@@ -140,13 +327,37 @@ func (s *Server) GetPoolItems(w http.ResponseWriter, r *http.Request, _ httprout
 	w.Write(buf.Bytes())
 }
 
+// GetSnapshots will handle responding with the snapshots for a repository
+func (s *Server) GetSnapshots(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	id := p.ByName("id")
+	req := libferry.SnapshotListingRequest{}
+	snapshots, err := s.manager.GetSnapshots(id)
+	if err != nil {
+		s.sendStockError(err, w, r)
+		return
+	}
+	for _, snapshot := range snapshots {
+		req.Snapshots = append(req.Snapshots, libferry.Snapshot{
+			Name:     snapshot.Name,
+			Created:  snapshot.Created,
+			Packages: len(snapshot.Entries),
+		})
+	}
+	buf := bytes.Buffer{}
+	if err := json.NewEncoder(&buf).Encode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(buf.Bytes())
+}
+
 // CreateRepo will handle remote requests for repository creation
 func (s *Server) CreateRepo(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 	id := p.ByName("id")
 	log.WithFields(log.Fields{
 		"id": id,
 	}).Info("Repository creation requested")
-	s.jproc.PushJob(jobs.NewCreateRepoJob(id))
+	s.pushJob(jobs.NewCreateRepoJob(id), w, r)
 }
 
 // DeleteRepo will handle remote requests for repository deletion
@@ -155,7 +366,7 @@ func (s *Server) DeleteRepo(w http.ResponseWriter, r *http.Request, p httprouter
 	log.WithFields(log.Fields{
 		"id": id,
 	}).Info("Repository deletion requested")
-	s.jproc.PushJob(jobs.NewDeleteRepoJob(id))
+	s.pushJob(jobs.NewDeleteRepoJob(id), w, r)
 }
 
 // DeltaRepo will handle remote requests for repository deltaing
@@ -164,7 +375,7 @@ func (s *Server) DeltaRepo(w http.ResponseWriter, r *http.Request, p httprouter.
 	log.WithFields(log.Fields{
 		"id": id,
 	}).Info("Repository delta requested")
-	s.jproc.PushJob(jobs.NewDeltaRepoJob(id))
+	s.pushJob(jobs.NewDeltaRepoJob(id), w, r)
 }
 
 // IndexRepo will handle remote requests for repository indexing
@@ -173,7 +384,7 @@ func (s *Server) IndexRepo(w http.ResponseWriter, r *http.Request, p httprouter.
 	log.WithFields(log.Fields{
 		"id": id,
 	}).Info("Repository indexing requested")
-	s.jproc.PushJob(jobs.NewIndexRepoJob(id))
+	s.pushJob(jobs.NewIndexRepoJob(id), w, r)
 }
 
 // ImportPackages will bulk-import the packages in the request
@@ -192,7 +403,23 @@ func (s *Server) ImportPackages(w http.ResponseWriter, r *http.Request, p httpro
 		"npackages": len(req.Path),
 	}).Info("Repository bulk import requested")
 
-	s.jproc.PushJob(jobs.NewBulkAddJob(id, req.Path))
+	s.pushJob(jobs.NewBulkAddJob(id, req.Path), w, r)
+}
+
+// ReplacePackages will proxy a job to replace existing pool entries
+func (s *Server) ReplacePackages(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req := libferry.ReplaceRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"npackages": len(req.Path),
+	}).Warning("Package replacement requested")
+
+	s.pushJob(jobs.NewReplacePackageJob(req.Path), w, r)
 }
 
 // CloneRepo will proxy a job to clone an existing repository
@@ -212,7 +439,7 @@ func (s *Server) CloneRepo(w http.ResponseWriter, r *http.Request, p httprouter.
 		"fullClone": req.CopyAll,
 	}).Info("Repository clone requested")
 
-	s.jproc.PushJob(jobs.NewCloneRepoJob(id, req.CloneName, req.CopyAll))
+	s.pushJob(jobs.NewCloneRepoJob(id, req.CloneName, req.CopyAll), w, r)
 }
 
 // PullRepo will proxy a job to pull an existing repository
@@ -231,7 +458,36 @@ func (s *Server) PullRepo(w http.ResponseWriter, r *http.Request, p httprouter.P
 		"target": target,
 	}).Info("Repository pull requested")
 
-	s.jproc.PushJob(jobs.NewPullRepoJob(req.Source, target))
+	// Jobs queued ahead may change what is pulled, so the changes are only
+	// known from the result of the job
+	s.pushJob(jobs.NewPullRepoJob(req.Source, target, pullOptions(&req)), w, r)
+}
+
+// pullOptions converts the client representation of the pull options, and
+// returns nil when no options were given
+func pullOptions(req *libferry.PullRepoRequest) *core.PullOptions {
+	if req.Filter == nil && req.Conflict == "" {
+		return nil
+	}
+	return &core.PullOptions{
+		Filter:   pullFilter(req.Filter),
+		Conflict: core.PullConflictPolicy(req.Conflict),
+	}
+}
+
+// pullFilter converts the client representation of a pull filter
+func pullFilter(filter *libferry.PullFilter) *core.PullFilter {
+	if filter == nil {
+		return nil
+	}
+	return &core.PullFilter{
+		AllowSources:    filter.AllowSources,
+		AllowNames:      filter.AllowNames,
+		AllowComponents: filter.AllowComponents,
+		DenySources:     filter.DenySources,
+		DenyNames:       filter.DenyNames,
+		DenyComponents:  filter.DenyComponents,
+	}
 }
 
 // RemoveSource will proxy a job to remove an existing set of packages by source name + relno
@@ -251,7 +507,7 @@ func (s *Server) RemoveSource(w http.ResponseWriter, r *http.Request, p httprout
 		"repo":    target,
 	}).Info("Source removal requested")
 
-	s.jproc.PushJob(jobs.NewRemoveSourceJob(target, req.Source, req.Release))
+	s.pushJob(jobs.NewRemoveSourceJob(target, req.Source, req.Release), w, r)
 }
 
 // CopySource will proxy a job to copy a package by source&relno into target
@@ -273,7 +529,7 @@ func (s *Server) CopySource(w http.ResponseWriter, r *http.Request, p httprouter
 		"skipIndex":  req.SkipIndex,
 	}).Info("Source copy requested")
 
-	s.jproc.PushJob(jobs.NewCopySourceJob(sourceRepo, req.Target, req.Source, req.Release, req.SkipIndex))
+	s.pushJob(jobs.NewCopySourceJob(sourceRepo, req.Target, req.Source, req.Release, req.SkipIndex), w, r)
 }
 
 // TrimPackages will proxy a job to remove excess fat from a repo
@@ -292,7 +548,7 @@ func (s *Server) TrimPackages(w http.ResponseWriter, r *http.Request, p httprout
 		"maxKeep": req.MaxKeep,
 	}).Info("Package trim requested")
 
-	s.jproc.PushJob(jobs.NewTrimPackagesJob(target, req.MaxKeep))
+	s.pushJob(jobs.NewTrimPackagesJob(target, req.MaxKeep), w, r)
 }
 
 // TrimObsolete will proxy a job to remove obsolete packages from a repo
@@ -301,7 +557,7 @@ func (s *Server) TrimObsolete(w http.ResponseWriter, r *http.Request, p httprout
 	log.WithFields(log.Fields{
 		"id": id,
 	}).Info("Obsoletes trim requested")
-	s.jproc.PushJob(jobs.NewTrimObsoleteJob(id))
+	s.pushJob(jobs.NewTrimObsoleteJob(id), w, r)
 }
 
 // ResetCompleted will ask the job store to remove completed jobs. This is blocking.
@@ -324,7 +580,7 @@ func (s *Server) FreezeRepo(w http.ResponseWriter, r *http.Request, p httprouter
 
 	log.WithFields(log.Fields{"repo": target}).Info("Repository freeze requested")
 
-	s.jproc.PushJob(jobs.NewFreezeRepoJob(target))
+	s.pushJob(jobs.NewFreezeRepoJob(target), w, r)
 }
 
 // UnfreezeRepo will proxy a job to unfreeze an existing repository
@@ -333,5 +589,375 @@ func (s *Server) UnfreezeRepo(w http.ResponseWriter, r *http.Request, p httprout
 
 	log.WithFields(log.Fields{"repo": target}).Info("Repository unfreeze requested")
 
-	s.jproc.PushJob(jobs.NewUnfreezeRepoJob(target))
+	s.pushJob(jobs.NewUnfreezeRepoJob(target), w, r)
+}
+
+// CheckIntegrity will proxy a job to verify the pool and all repositories
+func (s *Server) CheckIntegrity(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	log.Info("Integrity check requested")
+	s.pushJob(jobs.NewCheckIntegrityJob(), w, r)
+}
+
+// RepairIntegrity will proxy a job to repair the pool and all repositories
+func (s *Server) RepairIntegrity(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	log.Info("Integrity repair requested")
+	s.pushJob(jobs.NewRepairIntegrityJob(), w, r)
+}
+
+// GetIntegrityReport will respond with the report from the last integrity check
+func (s *Server) GetIntegrityReport(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	report, err := s.manager.GetIntegrityReport()
+	if err != nil {
+		s.sendStockError(fmt.Errorf("no integrity report available: %v", err), w, r)
+		return
+	}
+	req := libferry.IntegrityReportRequest{
+		Started:     report.Started,
+		Finished:    report.Finished,
+		PoolEntries: report.PoolEntries,
+		RepoEntries: report.RepoEntries,
+	}
+	for _, issue := range report.Issues {
+		req.Issues = append(req.Issues, libferry.IntegrityIssue{
+			Kind:   string(issue.Kind),
+			Repo:   issue.Repo,
+			ID:     issue.ID,
+			Detail: issue.Detail,
+		})
+	}
+	buf := bytes.Buffer{}
+	if err := json.NewEncoder(&buf).Encode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(buf.Bytes())
+}
+
+// CheckInstallable will proxy a job to find unresolved dependencies in a repository
+func (s *Server) CheckInstallable(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	id := p.ByName("id")
+	log.WithFields(log.Fields{"repo": id}).Info("Installability check requested")
+	s.pushJob(jobs.NewCheckInstallableJob(id), w, r)
+}
+
+// GetInstallReport will respond with the report from the last installability
+// check of a repository
+func (s *Server) GetInstallReport(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	report, err := s.manager.GetInstallReport(p.ByName("id"))
+	if err != nil {
+		s.sendStockError(fmt.Errorf("no installability report available: %v", err), w, r)
+		return
+	}
+	req := libferry.InstallReportRequest{
+		Repo:     report.Repo,
+		Started:  report.Started,
+		Finished: report.Finished,
+		Packages: report.Packages,
+	}
+	for _, issue := range report.Issues {
+		req.Issues = append(req.Issues, libferry.InstallIssue{
+			Kind:       string(issue.Kind),
+			Package:    issue.Package,
+			ID:         issue.ID,
+			Dependency: issue.Dependency,
+			Detail:     issue.Detail,
+		})
+	}
+	buf := bytes.Buffer{}
+	if err := json.NewEncoder(&buf).Encode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(buf.Bytes())
+}
+
+// EnableInstallGate will proxy a job to gate indexing of a repository on installability
+func (s *Server) EnableInstallGate(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	target := p.ByName("id")
+
+	log.WithFields(log.Fields{"repo": target}).Info("Install gate requested")
+
+	s.pushJob(jobs.NewSetInstallGateJob(target, true), w, r)
+}
+
+// DisableInstallGate will proxy a job to stop gating indexing of a repository
+func (s *Server) DisableInstallGate(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	target := p.ByName("id")
+
+	log.WithFields(log.Fields{"repo": target}).Info("Install gate removal requested")
+
+	s.pushJob(jobs.NewSetInstallGateJob(target, false), w, r)
+}
+
+// CreateSnapshot will proxy a job to snapshot an existing repository
+func (s *Server) CreateSnapshot(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	id := p.ByName("id")
+	name := p.ByName("name")
+
+	log.WithFields(log.Fields{
+		"repo":     id,
+		"snapshot": name,
+	}).Info("Repository snapshot requested")
+
+	s.pushJob(jobs.NewCreateSnapshotJob(id, name), w, r)
+}
+
+// DeleteSnapshot will proxy a job to remove a repository snapshot
+func (s *Server) DeleteSnapshot(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	id := p.ByName("id")
+	name := p.ByName("name")
+
+	log.WithFields(log.Fields{
+		"repo":     id,
+		"snapshot": name,
+	}).Info("Snapshot deletion requested")
+
+	s.pushJob(jobs.NewDeleteSnapshotJob(id, name), w, r)
+}
+
+// RestoreSnapshot will proxy a job to roll a repository back to a snapshot
+func (s *Server) RestoreSnapshot(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	id := p.ByName("id")
+	name := p.ByName("name")
+
+	log.WithFields(log.Fields{
+		"repo":     id,
+		"snapshot": name,
+	}).Info("Snapshot restore requested")
+
+	s.pushJob(jobs.NewRestoreSnapshotJob(id, name), w, r)
+}
+
+// sendPlan will respond with the given plan, or the error encountered while
+// computing it.
+func (s *Server) sendPlan(plan *core.ChangePlan, err error, w http.ResponseWriter, r *http.Request) {
+	if err != nil {
+		s.sendStockError(err, w, r)
+		return
+	}
+	req := libferry.ChangePlanRequest{
+		Repo:    plan.Repo,
+		Ref:     plan.Ref,
+		Unref:   plan.Unref,
+		Deltas:  plan.Deltas,
+		Skipped: plan.Skipped,
+	}
+	for _, change := range plan.Published {
+		req.Published = append(req.Published, libferry.PublishedChange{
+			Name: change.Name,
+			From: change.From,
+			To:   change.To,
+		})
+	}
+	buf := bytes.Buffer{}
+	if err := json.NewEncoder(&buf).Encode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(buf.Bytes())
+}
+
+// PlanPullRepo will respond with the changes a pull would make
+func (s *Server) PlanPullRepo(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	req := libferry.PullRepoRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	plan, err := s.manager.PlanPullRepo(req.Source, p.ByName("id"), pullOptions(&req))
+	s.sendPlan(plan, err, w, r)
+}
+
+// PlanRemoveSource will respond with the changes a source removal would make
+func (s *Server) PlanRemoveSource(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	req := libferry.RemoveSourceRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	plan, err := s.manager.PlanRemoveSource(p.ByName("id"), req.Source, req.Release)
+	s.sendPlan(plan, err, w, r)
+}
+
+// PlanCopySource will respond with the changes a source copy would make
+func (s *Server) PlanCopySource(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	req := libferry.CopySourceRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	plan, err := s.manager.PlanCopySource(p.ByName("id"), req.Target, req.Source, req.Release)
+	s.sendPlan(plan, err, w, r)
+}
+
+// PlanTrimPackages will respond with the changes a package trim would make
+func (s *Server) PlanTrimPackages(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	req := libferry.TrimPackagesRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	plan, err := s.manager.PlanTrimPackages(p.ByName("id"), req.MaxKeep)
+	s.sendPlan(plan, err, w, r)
+}
+
+// PlanTrimObsolete will respond with the changes an obsoletes trim would make
+func (s *Server) PlanTrimObsolete(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	plan, err := s.manager.PlanTrimObsolete(r.Context(), p.ByName("id"))
+	s.sendPlan(plan, err, w, r)
+}
+
+// diffPackage converts a core.DiffPackage into the libferry representation
+func diffPackage(pkg core.DiffPackage) libferry.DiffPackage {
+	return libferry.DiffPackage{
+		Name:    pkg.Name,
+		ID:      pkg.ID,
+		Version: pkg.Version,
+		Release: pkg.Release,
+	}
+}
+
+// DiffRepos will respond with the differences between two repositories
+func (s *Server) DiffRepos(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	diff, err := s.manager.DiffRepos(p.ByName("source"), p.ByName("target"))
+	if err != nil {
+		s.sendStockError(err, w, r)
+		return
+	}
+	req := libferry.RepoDiffRequest{
+		Source: diff.Source,
+		Target: diff.Target,
+	}
+	for _, pkg := range diff.OnlySource {
+		req.OnlySource = append(req.OnlySource, diffPackage(pkg))
+	}
+	for _, pkg := range diff.OnlyTarget {
+		req.OnlyTarget = append(req.OnlyTarget, diffPackage(pkg))
+	}
+	for _, change := range diff.Changed {
+		req.Changed = append(req.Changed, libferry.DiffChange{
+			Name:   change.Name,
+			Source: diffPackage(change.Source),
+			Target: diffPackage(change.Target),
+		})
+	}
+	buf := bytes.Buffer{}
+	if err := json.NewEncoder(&buf).Encode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(buf.Bytes())
+}
+
+// GetChangelog will respond with the updates that are new in the source
+// repository when compared to the target repository
+func (s *Server) GetChangelog(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	changelog, err := s.manager.GetChangelog(p.ByName("source"), p.ByName("target"))
+	if err != nil {
+		s.sendStockError(err, w, r)
+		return
+	}
+	req := libferry.ChangelogRequest{
+		Source:   changelog.Source,
+		Target:   changelog.Target,
+		Markdown: changelog.Markdown(),
+	}
+	for _, entry := range changelog.Entries {
+		item := libferry.ChangelogEntry{
+			Source:      entry.Source,
+			Packages:    entry.Packages,
+			FromVersion: entry.FromVersion,
+			FromRelease: entry.FromRelease,
+			ToVersion:   entry.ToVersion,
+			ToRelease:   entry.ToRelease,
+		}
+		for _, update := range entry.Updates {
+			item.Updates = append(item.Updates, libferry.ChangelogUpdate(update))
+		}
+		req.Entries = append(req.Entries, item)
+	}
+	buf := bytes.Buffer{}
+	if err := json.NewEncoder(&buf).Encode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(buf.Bytes())
+}
+
+// Search will respond with every package matching the query
+func (s *Server) Search(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req := libferry.SearchRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	results, err := s.manager.Search(&core.SearchQuery{
+		Repos:     req.Repos,
+		Name:      req.Name,
+		Source:    req.Source,
+		Release:   req.Release,
+		Packager:  req.Packager,
+		License:   req.License,
+		Component: req.Component,
+		PkgConfig: req.PkgConfig,
+		Published: req.Published,
+	})
+	if err != nil {
+		s.sendStockError(err, w, r)
+		return
+	}
+	resp := libferry.SearchResultsRequest{}
+	for _, result := range results {
+		resp.Results = append(resp.Results, libferry.SearchResult(result))
+	}
+	buf := bytes.Buffer{}
+	if err := json.NewEncoder(&buf).Encode(&resp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(buf.Bytes())
+}
+
+// GetDependencyGraph will respond with the runtime dependency graph of a repository
+func (s *Server) GetDependencyGraph(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	graph, err := s.manager.GetDependencyGraph(p.ByName("id"))
+	if err != nil {
+		s.sendStockError(err, w, r)
+		return
+	}
+	req := libferry.DependencyGraphRequest{
+		Repo:    graph.Repo,
+		Missing: graph.Missing,
+		DOT:     graph.DOT(),
+	}
+	for _, node := range graph.Nodes {
+		req.Nodes = append(req.Nodes, libferry.DependencyNode(node))
+	}
+	buf := bytes.Buffer{}
+	if err := json.NewEncoder(&buf).Encode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(buf.Bytes())
+}
+
+// GetReverseDeps will respond with the packages depending on the named package
+func (s *Server) GetReverseDeps(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	graph, err := s.manager.GetDependencyGraph(p.ByName("id"))
+	if err != nil {
+		s.sendStockError(err, w, r)
+		return
+	}
+	req := libferry.ReverseDepsRequest{
+		Repo:       graph.Repo,
+		Name:       p.ByName("name"),
+		Direct:     graph.ReverseDependencies(p.ByName("name"), false),
+		Transitive: graph.ReverseDependencies(p.ByName("name"), true),
+	}
+	buf := bytes.Buffer{}
+	if err := json.NewEncoder(&buf).Encode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(buf.Bytes())
 }
@@ -0,0 +1,200 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getsolus/ferryd/src/ferryd/core"
+	"github.com/getsolus/ferryd/src/libferry"
+)
+
+// MaxJobLog is how many log messages are kept for each job, after which the
+// oldest messages are dropped
+const MaxJobLog = 1000
+
+// jobActivity holds what a running job has reported about itself
+type jobActivity struct {
+	mut      sync.Mutex
+	progress *libferry.JobProgress
+	log      []libferry.JobLogEntry
+}
+
+// activityKey is used to store the jobActivity within the job's context
+type activityKey struct{}
+
+// activityFrom returns the activity of the job running with ctx, if any
+func activityFrom(ctx context.Context) *jobActivity {
+	a, _ := ctx.Value(activityKey{}).(*jobActivity)
+	return a
+}
+
+// setProgress will replace the current progress of the job
+func (a *jobActivity) setProgress(step string, done, total int) {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+	a.progress = &libferry.JobProgress{Step: step, Done: done, Total: total}
+}
+
+// getProgress returns a copy of the current progress, or nil if none has
+// been reported
+func (a *jobActivity) getProgress() *libferry.JobProgress {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+	if a.progress == nil {
+		return nil
+	}
+	progress := *a.progress
+	return &progress
+}
+
+// addLog will keep hold of a log message
+func (a *jobActivity) addLog(entry libferry.JobLogEntry) {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+	if len(a.log) >= MaxJobLog {
+		a.log = a.log[1:]
+	}
+	a.log = append(a.log, entry)
+}
+
+// getLog returns a copy of the log messages kept so far
+func (a *jobActivity) getLog() []libferry.JobLogEntry {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+	return append([]libferry.JobLogEntry(nil), a.log...)
+}
+
+// jobLogHook captures messages logged with the context of a running job, so
+// that they can be looked up by job ID
+type jobLogHook struct{}
+
+// installLogHook ensures jobLogHook is only added to the logger once
+var installLogHook sync.Once
+
+// Levels returns all levels, as it is the logger level that decides what
+// will be kept
+func (jobLogHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+// Fire will add the entry to the log of the job it was logged for
+func (jobLogHook) Fire(entry *log.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	a := activityFrom(entry.Context)
+	if a == nil {
+		return nil
+	}
+	fields := make(map[string]string, len(entry.Data))
+	for k, v := range entry.Data {
+		fields[k] = fmt.Sprint(v)
+	}
+	a.addLog(libferry.JobLogEntry{
+		Time:    entry.Time.UTC(),
+		Level:   entry.Level.String(),
+		Message: entry.Message,
+		Fields:  fields,
+	})
+	return nil
+}
+
+// startActivity returns a context to run the job with, which will capture its
+// progress and log messages. Retried jobs carry on from their last attempt,
+// and jobs interrupted by shutdown from the log kept with them.
+func (j *Processor) startActivity(ctx context.Context, job *JobEntry) context.Context {
+	j.activityMut.Lock()
+	defer j.activityMut.Unlock()
+
+	a, ok := j.activity[job.ID]
+	if !ok {
+		a = &jobActivity{log: job.Log}
+		j.activity[job.ID] = a
+	}
+	a.setProgress("Starting", 0, 0)
+
+	ctx = context.WithValue(ctx, activityKey{}, a)
+	return core.WithProgress(ctx, a.setProgress)
+}
+
+// endActivity will stash the log of a finished job for its completion record,
+// and forget about the job unless it is going to be retried
+func (j *Processor) endActivity(job *JobEntry, retry bool) {
+	j.activityMut.Lock()
+	defer j.activityMut.Unlock()
+
+	a, ok := j.activity[job.ID]
+	if !ok {
+		return
+	}
+	job.log = a.getLog()
+	if !retry {
+		delete(j.activity, job.ID)
+	}
+}
+
+// getActivity returns the activity of a running job, or nil
+func (j *Processor) getActivity(id uint64) *jobActivity {
+	j.activityMut.Lock()
+	defer j.activityMut.Unlock()
+	return j.activity[id]
+}
+
+// SetProgress is used by jobs to report how far they've got through a step
+// of their work, given the context they were executed with
+func (j *Processor) SetProgress(ctx context.Context, step string, done, total int) {
+	if a := activityFrom(ctx); a != nil {
+		a.setProgress(step, done, total)
+	}
+}
+
+// ActiveJobs will return the queued and running jobs, with the progress
+// reported by those that are running
+func (j *Processor) ActiveJobs() ([]*libferry.Job, error) {
+	jobs, err := j.store.ActiveJobs()
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		if a := j.getActivity(job.ID); a != nil && job.State == libferry.JobRunning {
+			job.Progress = a.getProgress()
+		}
+	}
+	return jobs, nil
+}
+
+// JobLog will return the messages logged by a job, whether it is running or
+// has completed
+func (j *Processor) JobLog(id uint64) ([]libferry.JobLogEntry, error) {
+	if a := j.getActivity(id); a != nil {
+		return a.getLog(), nil
+	}
+	entries, err := j.store.JobLog(id)
+	if err != ErrUnknownJob {
+		return entries, err
+	}
+	// Nothing logged while queued
+	if _, err := j.store.GetJob(id); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
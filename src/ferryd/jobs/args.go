@@ -0,0 +1,292 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getsolus/ferryd/src/ferryd/core"
+	"github.com/getsolus/ferryd/src/libdb"
+)
+
+// Each job type keeps its arguments in a struct of its own, stored as JSON in
+// JobEntry.Args along with the version of that struct. New fields may be added
+// freely, as older entries simply decode with them unset. When a change would
+// make older entries decode wrongly, bump the version of the job type in
+// argsVersions and convert entries from the previous version in argsUpgrades.
+// Queued entries are upgraded when the store is opened.
+
+// argsVersions are the current versions of the arguments of each job type.
+// Job types not listed are at version 1.
+var argsVersions = map[JobType]int{}
+
+// An argsUpgrade converts the arguments of a job to the next version
+type argsUpgrade func(args json.RawMessage) (json.RawMessage, error)
+
+// argsUpgrades convert the arguments of a job type from the keyed version to
+// the next
+var argsUpgrades = map[JobType]map[int]argsUpgrade{}
+
+// RepoArgs are the arguments of jobs that only act on a repository
+type RepoArgs struct {
+	Repo string `json:"repo"`
+}
+
+// argsVersion returns the current version of the arguments of the job type
+func argsVersion(t JobType) int {
+	if v, ok := argsVersions[t]; ok {
+		return v
+	}
+	return 1
+}
+
+// encodeArgs will encode the arguments of a new job. They're plain structs, so
+// they can't fail to marshal.
+func encodeArgs(args interface{}) json.RawMessage {
+	blob, err := json.Marshal(args)
+	if err != nil {
+		panic(fmt.Sprintf("unencodable job arguments: %v", err))
+	}
+	return blob
+}
+
+// decodeArgs will decode the arguments of the job, upgrading them first if
+// they were stored by an older ferryd
+func (j *JobEntry) decodeArgs(args interface{}) error {
+	if err := j.upgradeArgs(); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(j.Args, args); err != nil {
+		return fmt.Errorf("job has invalid arguments: %v", err)
+	}
+	return nil
+}
+
+// upgradeArgs will bring the arguments of the job up to the current version.
+// New jobs have no version until they are pushed, and are already current.
+func (j *JobEntry) upgradeArgs() error {
+	if j.Args == nil && j.Params != nil {
+		convert, ok := legacyArgs[j.Type]
+		if !ok {
+			return fmt.Errorf("job has invalid parameters")
+		}
+		args, err := convert(j.Params)
+		if err != nil {
+			return err
+		}
+		j.Args = encodeArgs(args)
+		j.ArgsVersion = 1
+		j.Params = nil
+	}
+
+	current := argsVersion(j.Type)
+	if j.ArgsVersion == 0 {
+		j.ArgsVersion = current
+	}
+	for j.ArgsVersion < current {
+		upgrade, ok := argsUpgrades[j.Type][j.ArgsVersion]
+		if !ok {
+			return fmt.Errorf("no upgrade for %s arguments from version %d", j.Type, j.ArgsVersion)
+		}
+		args, err := upgrade(j.Args)
+		if err != nil {
+			return fmt.Errorf("failed to upgrade %s arguments from version %d: %v", j.Type, j.ArgsVersion, err)
+		}
+		j.Args = args
+		j.ArgsVersion++
+	}
+	if j.ArgsVersion > current {
+		return fmt.Errorf("%s arguments are version %d, newer than the supported %d", j.Type, j.ArgsVersion, current)
+	}
+	return nil
+}
+
+// migrateArgs will upgrade the arguments of every queued job, so that they
+// can be coalesced and run by this version of ferryd. Jobs that can't be
+// upgraded are left to fail when they're run.
+func (s *JobStore) migrateArgs() error {
+	s.modMut.Lock()
+	defer s.modMut.Unlock()
+
+	for _, bk := range [][]byte{BucketSequentialJobs, BucketAsyncJobs} {
+		bucket := s.db.Bucket(bk)
+
+		upgraded := make(map[string]*JobEntry)
+		err := bucket.ForEach(func(id, value []byte) error {
+			j := &JobEntry{}
+			if err := bucket.Decode(value, j); err != nil {
+				return err
+			}
+			if j.Params == nil && j.ArgsVersion == argsVersion(j.Type) {
+				return nil
+			}
+			if err := j.upgradeArgs(); err != nil {
+				log.WithFields(log.Fields{
+					"id":    j.ID,
+					"type":  j.Type,
+					"error": err,
+				}).Error("Failed to upgrade job arguments")
+				return nil
+			}
+			upgraded[string(id)] = j
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(upgraded) == 0 {
+			continue
+		}
+
+		err = s.db.Update(func(db libdb.Database) error {
+			for id, j := range upgraded {
+				if err := db.Bucket(bk).PutObject([]byte(id), j); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		log.WithFields(log.Fields{
+			"jobs": len(upgraded),
+		}).Info("Upgraded arguments of queued jobs")
+	}
+	return nil
+}
+
+// legacyArgs convert the positional string parameters of jobs queued before
+// arguments were typed
+var legacyArgs = map[JobType]func(p []string) (interface{}, error){
+	BulkAdd: func(p []string) (interface{}, error) {
+		if len(p) < 2 {
+			return nil, fmt.Errorf("job has invalid parameters")
+		}
+		return &BulkAddArgs{Repo: p[0], Packages: p[1:]}, nil
+	},
+	CheckInstallable: legacyRepoArgs,
+	CloneRepo: func(p []string) (interface{}, error) {
+		if len(p) != 3 {
+			return nil, fmt.Errorf("job has invalid parameters")
+		}
+		return &CloneRepoArgs{Repo: p[0], NewClone: p[1], Full: p[2] == "full"}, nil
+	},
+	CopySource: func(p []string) (interface{}, error) {
+		if len(p) != 5 {
+			return nil, fmt.Errorf("job has invalid parameters")
+		}
+		rel, err := strconv.ParseInt(p[3], 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		si, err := strconv.ParseBool(p[4])
+		if err != nil {
+			return nil, err
+		}
+		return &CopySourceArgs{Repo: p[0], Target: p[1], Source: p[2], Release: int(rel), SkipIndex: si}, nil
+	},
+	CreateRepo:      legacyRepoArgs,
+	CreateSnapshot:  legacySnapshotArgs,
+	DeleteRepo:      legacyRepoArgs,
+	DeleteSnapshot:  legacySnapshotArgs,
+	Delta:           legacyDeltaArgs,
+	DeltaIndex:      legacyDeltaArgs,
+	DeltaRepo:       legacyRepoArgs,
+	FreezeRepo:      legacyRepoArgs,
+	IndexRepo:       legacyRepoArgs,
+	RestoreSnapshot: legacySnapshotArgs,
+	TrimObsolete:    legacyRepoArgs,
+	UnfreezeRepo:    legacyRepoArgs,
+	PullRepo: func(p []string) (interface{}, error) {
+		if len(p) != 2 && len(p) != 3 {
+			return nil, fmt.Errorf("job has invalid parameters")
+		}
+		args := &PullRepoArgs{Source: p[0], Target: p[1]}
+		if len(p) == 3 {
+			args.Options = &core.PullOptions{}
+			if err := json.Unmarshal([]byte(p[2]), args.Options); err != nil {
+				return nil, fmt.Errorf("job has invalid options: %v", err)
+			}
+		}
+		return args, nil
+	},
+	RemoveSource: func(p []string) (interface{}, error) {
+		if len(p) != 3 {
+			return nil, fmt.Errorf("job has invalid parameters")
+		}
+		rel, err := strconv.ParseInt(p[2], 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		return &RemoveSourceArgs{Repo: p[0], Source: p[1], Release: int(rel)}, nil
+	},
+	ReplacePackage: func(p []string) (interface{}, error) {
+		return &ReplacePackageArgs{Packages: p}, nil
+	},
+	SetInstallGate: func(p []string) (interface{}, error) {
+		if len(p) != 2 {
+			return nil, fmt.Errorf("job has invalid parameters")
+		}
+		enabled, err := strconv.ParseBool(p[1])
+		if err != nil {
+			return nil, err
+		}
+		return &InstallGateArgs{Repo: p[0], Enabled: enabled}, nil
+	},
+	TransitProcess: func(p []string) (interface{}, error) {
+		if len(p) != 1 {
+			return nil, fmt.Errorf("job has invalid parameters")
+		}
+		return &TransitArgs{Path: p[0]}, nil
+	},
+	TrimPackages: func(p []string) (interface{}, error) {
+		if len(p) != 2 {
+			return nil, fmt.Errorf("job has invalid parameters")
+		}
+		keep, err := strconv.ParseInt(p[1], 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		return &TrimPackagesArgs{Repo: p[0], Keep: int(keep)}, nil
+	},
+}
+
+func legacyRepoArgs(p []string) (interface{}, error) {
+	if len(p) != 1 {
+		return nil, fmt.Errorf("job has invalid parameters")
+	}
+	return &RepoArgs{Repo: p[0]}, nil
+}
+
+func legacySnapshotArgs(p []string) (interface{}, error) {
+	if len(p) != 2 {
+		return nil, fmt.Errorf("job has invalid parameters")
+	}
+	return &SnapshotArgs{Repo: p[0], Name: p[1]}, nil
+}
+
+func legacyDeltaArgs(p []string) (interface{}, error) {
+	if len(p) != 2 {
+		return nil, fmt.Errorf("job has invalid parameters")
+	}
+	return &DeltaArgs{Repo: p[0], Package: p[1]}, nil
+}
@@ -17,6 +17,7 @@
 package jobs
 
 import (
+	"context"
 	"fmt"
 
 	log "github.com/sirupsen/logrus"
@@ -31,32 +32,43 @@ type BulkAddJobHandler struct {
 	packagePaths []string
 }
 
+// BulkAddArgs are the arguments of a BulkAdd job
+type BulkAddArgs struct {
+	Repo     string   `json:"repo"`
+	Packages []string `json:"packages"`
+}
+
 // NewBulkAddJob will return a job suitable for adding to the job processor
 func NewBulkAddJob(id string, pkgs []string) *JobEntry {
 	return &JobEntry{
 		sequential: true,
 		Type:       BulkAdd,
-		Params:     append([]string{id}, pkgs...),
+		Args:       encodeArgs(&BulkAddArgs{Repo: id, Packages: pkgs}),
+		Repos:      []string{id},
 	}
 }
 
 // NewBulkAddJobHandler will create a job handler for the input job and ensure it validates
 func NewBulkAddJobHandler(j *JobEntry) (*BulkAddJobHandler, error) {
-	if len(j.Params) < 2 {
+	args := &BulkAddArgs{}
+	if err := j.decodeArgs(args); err != nil {
+		return nil, err
+	}
+	if len(args.Packages) < 1 {
 		return nil, fmt.Errorf("job has invalid parameters")
 	}
 	return &BulkAddJobHandler{
-		repoID:       j.Params[0],
-		packagePaths: j.Params[1:],
+		repoID:       args.Repo,
+		packagePaths: args.Packages,
 	}, nil
 }
 
 // Execute will attempt the mass-import of packages passed to the job
-func (j *BulkAddJobHandler) Execute(_ *Processor, manager *core.Manager) error {
-	if err := manager.AddPackages(j.repoID, j.packagePaths, false); err != nil {
+func (j *BulkAddJobHandler) Execute(ctx context.Context, _ *Processor, manager *core.Manager) error {
+	if err := manager.AddPackages(ctx, j.repoID, j.packagePaths, false); err != nil {
 		return err
 	}
-	log.WithFields(log.Fields{"repo": j.repoID}).Info("Added packages to repository")
+	log.WithContext(ctx).WithFields(log.Fields{"repo": j.repoID}).Info("Added packages to repository")
 	return nil
 }
 
@@ -0,0 +1,135 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getsolus/ferryd/src/ferryd/core"
+)
+
+// CheckInstallableJobHandler is responsible for finding unresolved dependencies
+// in a repository, and should only ever be used in sequential queues.
+type CheckInstallableJobHandler struct {
+	repoID string
+}
+
+// NewCheckInstallableJob will return a job suitable for adding to the job processor
+func NewCheckInstallableJob(repoID string) *JobEntry {
+	return &JobEntry{
+		sequential: true,
+		Type:       CheckInstallable,
+		Args:       encodeArgs(&RepoArgs{Repo: repoID}),
+		Repos:      []string{repoID},
+	}
+}
+
+// NewCheckInstallableJobHandler will create a job handler for the input job and ensure it validates
+func NewCheckInstallableJobHandler(j *JobEntry) (*CheckInstallableJobHandler, error) {
+	args := &RepoArgs{}
+	if err := j.decodeArgs(args); err != nil {
+		return nil, err
+	}
+	return &CheckInstallableJobHandler{repoID: args.Repo}, nil
+}
+
+// Execute will check the installability of the repository, failing the job
+// if any problems were found so that they show up in the status.
+func (j *CheckInstallableJobHandler) Execute(ctx context.Context, _ *Processor, manager *core.Manager) error {
+	report, err := manager.CheckInstallable(ctx, j.repoID)
+	if err != nil {
+		return err
+	}
+
+	for _, issue := range report.Issues {
+		log.WithContext(ctx).WithFields(log.Fields{
+			"repo":       j.repoID,
+			"kind":       issue.Kind,
+			"package":    issue.Package,
+			"dependency": issue.Dependency,
+			"detail":     issue.Detail,
+		}).Warning("Installability check found a problem")
+	}
+
+	if len(report.Issues) > 0 {
+		return fmt.Errorf("installability check found %d problems", len(report.Issues))
+	}
+
+	log.WithContext(ctx).WithFields(log.Fields{
+		"repo":     j.repoID,
+		"packages": report.Packages,
+	}).Info("Installability check passed")
+	return nil
+}
+
+// Describe returns a human readable description for this job
+func (j *CheckInstallableJobHandler) Describe() string {
+	return fmt.Sprintf("Check installability of '%s'", j.repoID)
+}
+
+// SetInstallGateJobHandler is responsible for toggling the installability gate
+type SetInstallGateJobHandler struct {
+	repoID  string
+	enabled bool
+}
+
+// InstallGateArgs are the arguments of a SetInstallGate job
+type InstallGateArgs struct {
+	Repo    string `json:"repo"`
+	Enabled bool   `json:"enabled"`
+}
+
+// NewSetInstallGateJob will return a job suitable for adding to the job processor
+func NewSetInstallGateJob(repoID string, enabled bool) *JobEntry {
+	return &JobEntry{
+		sequential: true,
+		Type:       SetInstallGate,
+		Args:       encodeArgs(&InstallGateArgs{Repo: repoID, Enabled: enabled}),
+		Repos:      []string{repoID},
+	}
+}
+
+// NewSetInstallGateJobHandler will create a job handler for the input job and ensure it validates
+func NewSetInstallGateJobHandler(j *JobEntry) (*SetInstallGateJobHandler, error) {
+	args := &InstallGateArgs{}
+	if err := j.decodeArgs(args); err != nil {
+		return nil, err
+	}
+	return &SetInstallGateJobHandler{repoID: args.Repo, enabled: args.Enabled}, nil
+}
+
+// Execute will attempt to toggle the gate
+func (j *SetInstallGateJobHandler) Execute(ctx context.Context, _ *Processor, manager *core.Manager) error {
+	if err := manager.SetInstallGate(j.repoID, j.enabled); err != nil {
+		log.WithContext(ctx).WithFields(log.Fields{"repo": j.repoID, "error": err}).
+			Warning("Failed to set install gate")
+		return err
+	}
+
+	return nil
+}
+
+// Describe returns a human readable description for this job
+func (j *SetInstallGateJobHandler) Describe() string {
+	if j.enabled {
+		return fmt.Sprintf("Enable install gate on '%s'", j.repoID)
+	}
+	return fmt.Sprintf("Disable install gate on '%s'", j.repoID)
+}
@@ -0,0 +1,79 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getsolus/ferryd/src/ferryd/core"
+)
+
+// CheckIntegrityJobHandler is responsible for verifying the pool and all
+// repositories, and should only ever be used in sequential queues.
+type CheckIntegrityJobHandler struct{}
+
+// NewCheckIntegrityJob will return a job suitable for adding to the job processor
+func NewCheckIntegrityJob() *JobEntry {
+	return &JobEntry{
+		sequential: true,
+		Type:       CheckIntegrity,
+	}
+}
+
+// NewCheckIntegrityJobHandler will create a job handler for the input job and ensure it validates
+func NewCheckIntegrityJobHandler(j *JobEntry) (*CheckIntegrityJobHandler, error) {
+	if len(j.Args) != 0 {
+		return nil, fmt.Errorf("job has invalid parameters")
+	}
+	return &CheckIntegrityJobHandler{}, nil
+}
+
+// Execute will check the integrity of the pool and repositories, failing
+// the job if any problems were found so that they show up in the status.
+func (j *CheckIntegrityJobHandler) Execute(ctx context.Context, _ *Processor, manager *core.Manager) error {
+	report, err := manager.CheckIntegrity(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, issue := range report.Issues {
+		log.WithContext(ctx).WithFields(log.Fields{
+			"kind":   issue.Kind,
+			"repo":   issue.Repo,
+			"id":     issue.ID,
+			"detail": issue.Detail,
+		}).Warning("Integrity check found a problem")
+	}
+
+	if len(report.Issues) > 0 {
+		return fmt.Errorf("integrity check found %d problems", len(report.Issues))
+	}
+
+	log.WithContext(ctx).WithFields(log.Fields{
+		"poolEntries": report.PoolEntries,
+		"repoEntries": report.RepoEntries,
+	}).Info("Integrity check passed")
+	return nil
+}
+
+// Describe returns a human readable description for this job
+func (j *CheckIntegrityJobHandler) Describe() string {
+	return "Check integrity of pool and repositories"
+}
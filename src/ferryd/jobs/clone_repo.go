@@ -17,6 +17,7 @@
 package jobs
 
 import (
+	"context"
 	"fmt"
 
 	log "github.com/sirupsen/logrus"
@@ -28,45 +29,45 @@ import (
 type CloneRepoJobHandler struct {
 	repoID    string
 	newClone  string
-	cloneMode string
+	fullClone bool
+}
+
+// CloneRepoArgs are the arguments of a CloneRepo job
+type CloneRepoArgs struct {
+	Repo     string `json:"repo"`
+	NewClone string `json:"newClone"`
+	Full     bool   `json:"full"` // Clone every package rather than just the tip
 }
 
 // NewCloneRepoJob will return a job suitable for adding to the job processor
 func NewCloneRepoJob(repoID, newClone string, cloneAll bool) *JobEntry {
-	mode := "tip"
-	if cloneAll {
-		mode = "full"
-	}
 	return &JobEntry{
 		sequential: true,
 		Type:       CloneRepo,
-		Params:     []string{repoID, newClone, mode},
+		Args:       encodeArgs(&CloneRepoArgs{Repo: repoID, NewClone: newClone, Full: cloneAll}),
+		Repos:      []string{repoID, newClone},
 	}
 }
 
 // NewCloneRepoJobHandler will create a job handler for the input job and ensure it validates
 func NewCloneRepoJobHandler(j *JobEntry) (*CloneRepoJobHandler, error) {
-	if len(j.Params) != 3 {
-		return nil, fmt.Errorf("job has invalid parameters")
+	args := &CloneRepoArgs{}
+	if err := j.decodeArgs(args); err != nil {
+		return nil, err
 	}
 	return &CloneRepoJobHandler{
-		repoID:    j.Params[0],
-		newClone:  j.Params[1],
-		cloneMode: j.Params[2],
+		repoID:    args.Repo,
+		newClone:  args.NewClone,
+		fullClone: args.Full,
 	}, nil
 }
 
 // Execute attempt to clone the repoID to newClone, optionally at full depth
-func (j *CloneRepoJobHandler) Execute(_ *Processor, manager *core.Manager) error {
-	fullClone := false
-	if j.cloneMode == "full" {
-		fullClone = true
-	}
-
-	if err := manager.CloneRepo(j.repoID, j.newClone, fullClone); err != nil {
+func (j *CloneRepoJobHandler) Execute(ctx context.Context, _ *Processor, manager *core.Manager) error {
+	if err := manager.CloneRepo(ctx, j.repoID, j.newClone, j.fullClone); err != nil {
 		return err
 	}
-	log.WithFields(log.Fields{"repo": j.repoID}).Info("Cloned repository")
+	log.WithContext(ctx).WithFields(log.Fields{"repo": j.repoID}).Info("Cloned repository")
 	return nil
 }
 
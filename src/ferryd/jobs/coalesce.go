@@ -0,0 +1,90 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package jobs
+
+import (
+	"time"
+)
+
+// coalesceKey returns the key under which equivalent pending jobs are merged,
+// or an empty string if jobs of this type must always run. Reindexing or
+// producing deltas twice in a row gains nothing, so a pending job for the same
+// repository (and package) takes the place of the new one.
+func (j *JobEntry) coalesceKey() string {
+	switch j.Type {
+	case IndexRepo:
+		return string(IndexRepo) + "\x00" + string(j.Args)
+	case Delta, DeltaIndex:
+		// Reindexing is requested by upgrading a pending Delta job
+		return string(Delta) + "\x00" + string(j.Args)
+	default:
+		return ""
+	}
+}
+
+// coalesce merges the newly pushed job n into the pending job j
+func (j *JobEntry) coalesce(n *JobEntry) {
+	if n.Type == DeltaIndex {
+		j.Type = DeltaIndex
+	}
+	if n.Priority > j.Priority {
+		j.Priority = n.Priority
+	}
+
+	// A debounced job waits for further changes, but no longer than
+	// indexMaxDelay since it was first queued. Any job wanted now can't wait.
+	switch {
+	case n.NotBefore.IsZero():
+		j.NotBefore = time.Time{}
+	case !j.NotBefore.IsZero():
+		j.NotBefore = n.NotBefore
+		if limit := j.Timing.Queued.Add(indexMaxDelay); j.NotBefore.After(limit) {
+			j.NotBefore = limit
+		}
+	}
+}
+
+// debounced reports whether j is waiting for further changes before it runs,
+// rather than waiting to be retried
+func (j *JobEntry) debounced() bool {
+	return !j.NotBefore.IsZero() && j.Attempt == 0
+}
+
+// pendingKey reports the coalesce key for a job that may still be merged
+// into, which is any job not yet claimed or waiting to be retried
+func (j *JobEntry) pendingKey() string {
+	if j.Claimed || (!j.NotBefore.IsZero() && !j.debounced()) {
+		return ""
+	}
+	return j.coalesceKey()
+}
+
+// sharesRepo reports whether j and o use any of the same repositories, where
+// a job without repositories uses all of them
+func (j *JobEntry) sharesRepo(o *JobEntry) bool {
+	if len(j.Repos) == 0 || len(o.Repos) == 0 {
+		return true
+	}
+	for _, a := range j.Repos {
+		for _, b := range o.Repos {
+			if a == b {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -17,8 +17,8 @@
 package jobs
 
 import (
+	"context"
 	"fmt"
-	"strconv"
 
 	log "github.com/sirupsen/logrus"
 
@@ -34,43 +34,52 @@ type CopySourceJobHandler struct {
 	skipIndex bool
 }
 
+// CopySourceArgs are the arguments of a CopySource job
+type CopySourceArgs struct {
+	Repo      string `json:"repo"`
+	Target    string `json:"target"`
+	Source    string `json:"source"`
+	Release   int    `json:"release"`
+	SkipIndex bool   `json:"skipIndex"`
+}
+
 // NewCopySourceJob will return a job suitable for adding to the job processor
 func NewCopySourceJob(repoID, target, source string, release int, skipIndex bool) *JobEntry {
 	return &JobEntry{
 		sequential: true,
 		Type:       CopySource,
-		Params:     []string{repoID, target, source, fmt.Sprintf("%d", release), fmt.Sprintf("%t", skipIndex)},
+		Args: encodeArgs(&CopySourceArgs{
+			Repo:      repoID,
+			Target:    target,
+			Source:    source,
+			Release:   release,
+			SkipIndex: skipIndex,
+		}),
+		Repos: []string{repoID, target},
 	}
 }
 
 // NewCopySourceJobHandler will create a job handler for the input job and ensure it validates
 func NewCopySourceJobHandler(j *JobEntry) (*CopySourceJobHandler, error) {
-	if len(j.Params) != 5 {
-		return nil, fmt.Errorf("job has invalid parameters")
-	}
-	rel, err := strconv.ParseInt(j.Params[3], 10, 32)
-	if err != nil {
-		return nil, err
-	}
-	si, err := strconv.ParseBool(j.Params[4])
-	if err != nil {
+	args := &CopySourceArgs{}
+	if err := j.decodeArgs(args); err != nil {
 		return nil, err
 	}
 	return &CopySourceJobHandler{
-		repoID:    j.Params[0],
-		target:    j.Params[1],
-		source:    j.Params[2],
-		release:   int(rel),
-		skipIndex: si,
+		repoID:    args.Repo,
+		target:    args.Target,
+		source:    args.Source,
+		release:   args.Release,
+		skipIndex: args.SkipIndex,
 	}, nil
 }
 
 // Execute will copy the source&rel match from the repo to the target
-func (j *CopySourceJobHandler) Execute(_ *Processor, manager *core.Manager) error {
-	if err := manager.CopySource(j.repoID, j.target, j.source, j.release, j.skipIndex); err != nil {
+func (j *CopySourceJobHandler) Execute(ctx context.Context, _ *Processor, manager *core.Manager) error {
+	if err := manager.CopySource(ctx, j.repoID, j.target, j.source, j.release, j.skipIndex); err != nil {
 		return err
 	}
-	log.WithFields(log.Fields{
+	log.WithContext(ctx).WithFields(log.Fields{
 		"from":          j.repoID,
 		"to":            j.target,
 		"source":        j.source,
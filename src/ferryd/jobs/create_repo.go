@@ -17,6 +17,7 @@
 package jobs
 
 import (
+	"context"
 	"fmt"
 
 	log "github.com/sirupsen/logrus"
@@ -35,26 +36,28 @@ func NewCreateRepoJob(id string) *JobEntry {
 	return &JobEntry{
 		sequential: true,
 		Type:       CreateRepo,
-		Params:     []string{id},
+		Args:       encodeArgs(&RepoArgs{Repo: id}),
+		Repos:      []string{id},
 	}
 }
 
 // NewCreateRepoJobHandler will create a job handler for the input job and ensure it validates
 func NewCreateRepoJobHandler(j *JobEntry) (*CreateRepoJobHandler, error) {
-	if len(j.Params) != 1 {
-		return nil, fmt.Errorf("job has invalid parameters")
+	args := &RepoArgs{}
+	if err := j.decodeArgs(args); err != nil {
+		return nil, err
 	}
 	return &CreateRepoJobHandler{
-		repoID: j.Params[0],
+		repoID: args.Repo,
 	}, nil
 }
 
 // Execute will construct a new repository if possible
-func (j *CreateRepoJobHandler) Execute(_ *Processor, manager *core.Manager) error {
-	if err := manager.CreateRepo(j.repoID); err != nil {
+func (j *CreateRepoJobHandler) Execute(ctx context.Context, _ *Processor, manager *core.Manager) error {
+	if err := manager.CreateRepo(ctx, j.repoID); err != nil {
 		return err
 	}
-	log.WithFields(log.Fields{"repo": j.repoID}).Info("Created repository")
+	log.WithContext(ctx).WithFields(log.Fields{"repo": j.repoID}).Info("Created repository")
 	return nil
 }
 
@@ -0,0 +1,224 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package jobs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronMacros are the shorthand schedules accepted in place of the five fields
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronNames may be used in place of the month and day of week numbers
+var cronNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// A CronSchedule decides when a recurring job runs, using the five fields of
+// a crontab entry: minute, hour, day of month, month and day of week.
+//
+// Each field may be "*", a number, a range "a-b", or a list of those separated
+// by commas, and any of them may be stepped with "/n". Months and days may be
+// given by their three letter names. As with cron, when both the day of month
+// and day of week are restricted the job runs on days matching either.
+//
+// The macros @hourly, @daily, @weekly, @monthly and @yearly are also accepted,
+// and all but @hourly may be followed by a time, as in "@daily at 03:00".
+type CronSchedule struct {
+	spec   string
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+	anyDay bool // Either the day of month or day of week is unrestricted
+}
+
+// ParseCron will parse the schedule from its crontab form
+func ParseCron(spec string) (*CronSchedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "@") {
+		expanded, ok := cronMacros[strings.ToLower(fields[0])]
+		if !ok {
+			return nil, fmt.Errorf("unknown schedule '%s'", fields[0])
+		}
+		at := fields[1:]
+		fields = strings.Fields(expanded)
+		switch {
+		case len(at) == 0:
+		case len(at) == 2 && at[0] == "at" && fields[1] != "*":
+			t, err := time.Parse("15:04", at[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid time '%s' in schedule", at[1])
+			}
+			fields[0] = strconv.Itoa(t.Minute())
+			fields[1] = strconv.Itoa(t.Hour())
+		default:
+			return nil, fmt.Errorf("invalid schedule '%s'", spec)
+		}
+	}
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("schedule '%s' must have 5 fields", spec)
+	}
+
+	c := &CronSchedule{spec: spec}
+	var err error
+	if c.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, err
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, err
+	}
+	if c.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, err
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, err
+	}
+	if c.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, err
+	}
+
+	// Sunday may be 0 or 7
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	c.anyDay = strings.HasPrefix(fields[2], "*") || strings.HasPrefix(fields[4], "*")
+	return c, nil
+}
+
+// parseCronField returns the set of values matched by one field, as bits
+func parseCronField(field string, min, max int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step in '%s'", field)
+			}
+			rng, step = part[:i], n
+		}
+
+		lo, hi := min, max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if lo, err = parseCronValue(bounds[0]); err != nil {
+				return 0, err
+			}
+			if hi, err = parseCronValue(bounds[1]); err != nil {
+				return 0, err
+			}
+		default:
+			v, err := parseCronValue(rng)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			// A single value only runs on, unless it is stepped
+			if step == 1 {
+				hi = v
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("'%s' is out of range %d-%d", part, min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+// parseCronValue accepts a number, or the name of a month or day
+func parseCronValue(value string) (int, error) {
+	if v, ok := cronNames[strings.ToLower(value)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value '%s' in schedule", value)
+	}
+	return v, nil
+}
+
+// String returns the schedule as it was given
+func (c *CronSchedule) String() string {
+	return c.spec
+}
+
+// dayMatches reports whether the job may run on the day of t
+func (c *CronSchedule) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.anyDay {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// Next will return the first time after the given time that the schedule is
+// due, in the location of the given time. The zero time is returned if the
+// schedule can never be due, such as on the 31st of February.
+func (c *CronSchedule) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := time.Date(after.Year(), after.Month(), after.Day(), after.Hour(), after.Minute(), 0, 0, loc).Add(time.Minute)
+
+	// Skip forward to the next candidate, making sure that we always move
+	// forward even through daylight saving changes
+	advance := func(next time.Time) {
+		if !next.After(t) {
+			next = t.Add(time.Minute)
+		}
+		t = next
+	}
+
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		y, m, d := t.Date()
+		switch {
+		case c.month&(1<<uint(m)) == 0:
+			advance(time.Date(y, m+1, 1, 0, 0, 0, 0, loc))
+		case !c.dayMatches(t):
+			advance(time.Date(y, m, d+1, 0, 0, 0, 0, loc))
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			advance(time.Date(y, m, d, t.Hour()+1, 0, 0, 0, loc))
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			advance(t.Add(time.Minute))
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,76 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// A Saturday
+	from := time.Date(2026, time.January, 31, 12, 30, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		Spec string
+		Next time.Time
+	}{
+		"every-minute": {"* * * * *", time.Date(2026, time.January, 31, 12, 31, 0, 0, time.UTC)},
+		"hourly":       {"@hourly", time.Date(2026, time.January, 31, 13, 0, 0, 0, time.UTC)},
+		"daily-at":     {"@daily at 03:00", time.Date(2026, time.February, 1, 3, 0, 0, 0, time.UTC)},
+		"later-today":  {"45 12 * * *", time.Date(2026, time.January, 31, 12, 45, 0, 0, time.UTC)},
+		"step":         {"*/20 * * * *", time.Date(2026, time.January, 31, 12, 40, 0, 0, time.UTC)},
+		"weekly":       {"@weekly", time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		"weekday":      {"0 9 * * mon-fri", time.Date(2026, time.February, 2, 9, 0, 0, 0, time.UTC)},
+		"sunday-7":     {"0 1 * * 7", time.Date(2026, time.February, 1, 1, 0, 0, 0, time.UTC)},
+		"month-end":    {"0 0 31 * *", time.Date(2026, time.March, 31, 0, 0, 0, 0, time.UTC)},
+		"dom-or-dow":   {"0 0 15 * wed", time.Date(2026, time.February, 4, 0, 0, 0, 0, time.UTC)},
+		"yearly":       {"@yearly", time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		"never":        {"0 0 31 feb *", time.Time{}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cron, err := ParseCron(test.Spec)
+			if err != nil {
+				t.Fatalf("Failed to parse '%s': %v", test.Spec, err)
+			}
+			if next := cron.Next(from); !next.Equal(test.Next) {
+				t.Fatalf("Expected '%s' to be next due at %v, got %v", test.Spec, test.Next, next)
+			}
+		})
+	}
+}
+
+func TestCronInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* * 0 * *",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"@fortnightly",
+		"@hourly at 03:00",
+		"@daily at 3pm",
+		"0 0 * * someday",
+	} {
+		if _, err := ParseCron(spec); err == nil {
+			t.Errorf("Expected '%s' to be invalid", spec)
+		}
+	}
+}
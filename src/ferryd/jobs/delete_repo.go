@@ -17,6 +17,7 @@
 package jobs
 
 import (
+	"context"
 	"fmt"
 
 	log "github.com/sirupsen/logrus"
@@ -35,26 +36,28 @@ func NewDeleteRepoJob(id string) *JobEntry {
 	return &JobEntry{
 		sequential: true,
 		Type:       DeleteRepo,
-		Params:     []string{id},
+		Args:       encodeArgs(&RepoArgs{Repo: id}),
+		Repos:      []string{id},
 	}
 }
 
 // NewDeleteRepoJobHandler will create a job handler for the input job and ensure it validates
 func NewDeleteRepoJobHandler(j *JobEntry) (*DeleteRepoJobHandler, error) {
-	if len(j.Params) != 1 {
-		return nil, fmt.Errorf("job has invalid parameters")
+	args := &RepoArgs{}
+	if err := j.decodeArgs(args); err != nil {
+		return nil, err
 	}
 	return &DeleteRepoJobHandler{
-		repoID: j.Params[0],
+		repoID: args.Repo,
 	}, nil
 }
 
 // Execute will delete an existing repository
-func (j *DeleteRepoJobHandler) Execute(_ *Processor, manager *core.Manager) error {
-	if err := manager.DeleteRepo(j.repoID); err != nil {
+func (j *DeleteRepoJobHandler) Execute(ctx context.Context, _ *Processor, manager *core.Manager) error {
+	if err := manager.DeleteRepo(ctx, j.repoID); err != nil {
 		return err
 	}
-	log.WithFields(log.Fields{"repo": j.repoID}).Info("Deleted repository")
+	log.WithContext(ctx).WithFields(log.Fields{"repo": j.repoID}).Info("Deleted repository")
 	return nil
 }
 
@@ -17,6 +17,7 @@
 package jobs
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"sort"
@@ -37,12 +38,19 @@ type DeltaJobHandler struct {
 	nDeltas     int // Track how many deltas we actually produce
 }
 
+// DeltaArgs are the arguments of the Delta and DeltaIndex jobs
+type DeltaArgs struct {
+	Repo    string `json:"repo"`
+	Package string `json:"package"`
+}
+
 // NewDeltaJob will return a job suitable for adding to the job processor
 func NewDeltaJob(repoID, packageID string) *JobEntry {
 	return &JobEntry{
 		sequential: false,
 		Type:       Delta,
-		Params:     []string{repoID, packageID},
+		Args:       encodeArgs(&DeltaArgs{Repo: repoID, Package: packageID}),
+		Repos:      []string{repoID},
 	}
 }
 
@@ -52,18 +60,20 @@ func NewDeltaIndexJob(repoID, packageID string) *JobEntry {
 	return &JobEntry{
 		sequential: false,
 		Type:       DeltaIndex,
-		Params:     []string{repoID, packageID},
+		Args:       encodeArgs(&DeltaArgs{Repo: repoID, Package: packageID}),
+		Repos:      []string{repoID},
 	}
 }
 
 // NewDeltaJobHandler will create a job handler for the input job and ensure it validates
 func NewDeltaJobHandler(j *JobEntry, indexRepo bool) (*DeltaJobHandler, error) {
-	if len(j.Params) != 2 {
-		return nil, fmt.Errorf("job has invalid parameters")
+	args := &DeltaArgs{}
+	if err := j.decodeArgs(args); err != nil {
+		return nil, err
 	}
 	return &DeltaJobHandler{
-		repoID:      j.Params[0],
-		packageName: j.Params[1],
+		repoID:      args.Repo,
+		packageName: args.Package,
 		indexRepo:   indexRepo,
 		nDeltas:     0,
 	}, nil
@@ -71,7 +81,7 @@ func NewDeltaJobHandler(j *JobEntry, indexRepo bool) (*DeltaJobHandler, error) {
 
 // executeInternal is the common code shared in the delta jobs, and is
 // split out to save duplication.
-func (j *DeltaJobHandler) executeInternal(manager *core.Manager) error {
+func (j *DeltaJobHandler) executeInternal(ctx context.Context, manager *core.Manager) error {
 	pkgs, err := manager.GetPackages(j.repoID, j.packageName)
 	if err != nil {
 		return err
@@ -79,7 +89,7 @@ func (j *DeltaJobHandler) executeInternal(manager *core.Manager) error {
 
 	// Need at least 2 packages for a delta op.
 	if len(pkgs) < 2 {
-		log.WithFields(log.Fields{
+		log.WithContext(ctx).WithFields(log.Fields{
 			"repo":    j.repoID,
 			"package": j.packageName,
 		}).Debug("No delta is possible")
@@ -91,6 +101,9 @@ func (j *DeltaJobHandler) executeInternal(manager *core.Manager) error {
 
 	// Process all potential deltas
 	for i := 0; i < len(pkgs)-1; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		old := pkgs[i]
 		fields := log.Fields{
 			"old":  old.GetID(),
@@ -126,33 +139,33 @@ func (j *DeltaJobHandler) executeInternal(manager *core.Manager) error {
 		// If so, just re-ref it for usage within the new repo
 		entry, err := manager.GetPoolEntry(deltaID)
 		if entry != nil && err == nil {
-			if err := manager.RefDelta(j.repoID, deltaID); err != nil {
+			if err := manager.RefDelta(ctx, j.repoID, deltaID); err != nil {
 				fields["error"] = err
-				log.WithFields(fields).Error("Failed to ref existing delta")
+				log.WithContext(ctx).WithFields(fields).Error("Failed to ref existing delta")
 				return err
 			}
-			log.WithFields(fields).Info("Reused existing delta")
+			log.WithContext(ctx).WithFields(fields).Info("Reused existing delta")
 			continue
 		}
 
-		deltaPath, err := manager.CreateDelta(j.repoID, old, tip)
+		deltaPath, err := manager.CreateDelta(ctx, j.repoID, old, tip)
 		if err != nil {
 			fields["error"] = err
 			if err == libeopkg.ErrDeltaPointless {
 				// Non-fatal, ask the manager to record this delta as a no-go
-				log.WithFields(fields).Info("Delta not possible, marked permanently")
+				log.WithContext(ctx).WithFields(fields).Info("Delta not possible, marked permanently")
 				if err := manager.MarkDeltaFailed(deltaID, mapping); err != nil {
 					fields["error"] = err
-					log.WithFields(fields).Error("Failed to mark delta failure")
+					log.WithContext(ctx).WithFields(fields).Error("Failed to mark delta failure")
 					return err
 				}
 				continue
 			} else if err == libeopkg.ErrMismatchedDelta {
-				log.WithFields(fields).Error("Package delta candidates do not match")
+				log.WithContext(ctx).WithFields(fields).Error("Package delta candidates do not match")
 				continue
 			} else {
 				// Genuinely an issue now
-				log.WithFields(fields).Error("Error in delta production")
+				log.WithContext(ctx).WithFields(fields).Error("Error in delta production")
 				return err
 			}
 		}
@@ -161,12 +174,12 @@ func (j *DeltaJobHandler) executeInternal(manager *core.Manager) error {
 
 		fields["path"] = deltaPath
 		// Produced a delta!
-		log.WithFields(fields).Info("Successfully producing delta package")
+		log.WithContext(ctx).WithFields(fields).Info("Successfully producing delta package")
 
 		// Let's get it included now.
-		if err = j.includeDelta(manager, mapping, deltaPath); err != nil {
+		if err = j.includeDelta(ctx, manager, mapping, deltaPath); err != nil {
 			fields["error"] = err
-			log.WithFields(fields).Error("Failed to include delta package")
+			log.WithContext(ctx).WithFields(fields).Error("Failed to include delta package")
 			return err
 		}
 	}
@@ -176,9 +189,9 @@ func (j *DeltaJobHandler) executeInternal(manager *core.Manager) error {
 
 // includeDelta will wrap up the basic functionality to get a delta package
 // imported into a target repository.
-func (j *DeltaJobHandler) includeDelta(manager *core.Manager, mapping *core.DeltaInformation, deltaPath string) error {
+func (j *DeltaJobHandler) includeDelta(ctx context.Context, manager *core.Manager, mapping *core.DeltaInformation, deltaPath string) error {
 	// Try to insert the delta
-	if err := manager.AddDelta(j.repoID, deltaPath, mapping); err != nil {
+	if err := manager.AddDelta(ctx, j.repoID, deltaPath, mapping); err != nil {
 		return err
 	}
 
@@ -187,8 +200,8 @@ func (j *DeltaJobHandler) includeDelta(manager *core.Manager, mapping *core.Delt
 }
 
 // Execute will delta the target package within the target repository.
-func (j *DeltaJobHandler) Execute(_ *Processor, manager *core.Manager) error {
-	err := j.executeInternal(manager)
+func (j *DeltaJobHandler) Execute(ctx context.Context, jproc *Processor, manager *core.Manager) error {
+	err := j.executeInternal(ctx, manager)
 	if err != nil {
 		return err
 	}
@@ -196,19 +209,11 @@ func (j *DeltaJobHandler) Execute(_ *Processor, manager *core.Manager) error {
 		return nil
 	}
 	// Ask that our repository now be reindexed because we've added deltas but
-	// only if we've successfully produced some delta packages
-	if j.nDeltas < 1 {
-		return nil
+	// only if we've successfully produced some delta packages. Many delta
+	// jobs finish together, so they share a single reindex.
+	if j.nDeltas > 0 {
+		jproc.MarkDirty(ctx, j.repoID)
 	}
-
-	if err := manager.Index(j.repoID); err != nil {
-		log.WithFields(log.Fields{
-			"repo":  j.repoID,
-			"error": err,
-		}).Error("Failed to index repository")
-		return err
-	}
-
 	return nil
 }
 
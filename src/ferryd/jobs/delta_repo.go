@@ -17,6 +17,7 @@
 package jobs
 
 import (
+	"context"
 	"fmt"
 
 	log "github.com/sirupsen/logrus"
@@ -35,17 +36,19 @@ func NewDeltaRepoJob(id string) *JobEntry {
 	return &JobEntry{
 		sequential: true,
 		Type:       DeltaRepo,
-		Params:     []string{id},
+		Args:       encodeArgs(&RepoArgs{Repo: id}),
+		Repos:      []string{id},
 	}
 }
 
 // NewDeltaRepoJobHandler will create a job handler for the input job and ensure it validates
 func NewDeltaRepoJobHandler(j *JobEntry) (*DeltaRepoJobHandler, error) {
-	if len(j.Params) != 1 {
-		return nil, fmt.Errorf("job has invalid parameters")
+	args := &RepoArgs{}
+	if err := j.decodeArgs(args); err != nil {
+		return nil, err
 	}
 	return &DeltaRepoJobHandler{
-		repoID: j.Params[0],
+		repoID: args.Repo,
 	}, nil
 }
 
@@ -56,7 +59,7 @@ func NewDeltaRepoJobHandler(j *JobEntry) (*DeltaRepoJobHandler, error) {
 //
 // This operation is ideally only used after the first import of a repository,
 // after then deltas will be produced on the fly.
-func (j *DeltaRepoJobHandler) Execute(jproc *Processor, manager *core.Manager) error {
+func (j *DeltaRepoJobHandler) Execute(ctx context.Context, jproc *Processor, manager *core.Manager) error {
 	packageNames, err := manager.GetPackageNames(j.repoID)
 	if err != nil {
 		return err
@@ -64,14 +67,15 @@ func (j *DeltaRepoJobHandler) Execute(jproc *Processor, manager *core.Manager) e
 
 	// Skip an empty repository
 	if len(packageNames) < 1 {
-		log.WithFields(log.Fields{
+		log.WithContext(ctx).WithFields(log.Fields{
 			"repo": j.repoID,
 		}).Warning("Requested delta for empty repository")
 		return nil
 	}
 
 	// Fire off parallel delta jobs for every package in this repository
-	for _, name := range packageNames {
+	for i, name := range packageNames {
+		jproc.SetProgress(ctx, "Schedule deltas", i, len(packageNames))
 		jproc.PushJob(NewDeltaJob(j.repoID, name))
 	}
 
@@ -17,6 +17,7 @@
 package jobs
 
 import (
+	"context"
 	"fmt"
 
 	log "github.com/sirupsen/logrus"
@@ -33,22 +34,24 @@ func NewFreezeRepoJob(repoID string) *JobEntry {
 	return &JobEntry{
 		sequential: true,
 		Type:       FreezeRepo,
-		Params:     []string{repoID},
+		Args:       encodeArgs(&RepoArgs{Repo: repoID}),
+		Repos:      []string{repoID},
 	}
 }
 
 // NewFreezeRepoJobHandler will create a job handler for the input job and ensure it validates
 func NewFreezeRepoJobHandler(j *JobEntry) (*FreezeRepoJobHandler, error) {
-	if len(j.Params) != 1 {
-		return nil, fmt.Errorf("job has invalid parameters")
+	args := &RepoArgs{}
+	if err := j.decodeArgs(args); err != nil {
+		return nil, err
 	}
-	return &FreezeRepoJobHandler{repoID: j.Params[0]}, nil
+	return &FreezeRepoJobHandler{repoID: args.Repo}, nil
 }
 
 // Execute will attempt to Freeze the repos
-func (j *FreezeRepoJobHandler) Execute(jproc *Processor, manager *core.Manager) error {
+func (j *FreezeRepoJobHandler) Execute(ctx context.Context, jproc *Processor, manager *core.Manager) error {
 	if err := manager.FreezeRepo(j.repoID); err != nil {
-		log.WithFields(log.Fields{"repo": j.repoID, "error": err}).
+		log.WithContext(ctx).WithFields(log.Fields{"repo": j.repoID, "error": err}).
 			Warning("Failed to freeze repository")
 		return err
 	}
@@ -70,22 +73,24 @@ func NewUnfreezeRepoJob(repoID string) *JobEntry {
 	return &JobEntry{
 		sequential: true,
 		Type:       UnfreezeRepo,
-		Params:     []string{repoID},
+		Args:       encodeArgs(&RepoArgs{Repo: repoID}),
+		Repos:      []string{repoID},
 	}
 }
 
 // NewUnfreezeRepoJobHandler will create a job handler for the input job and ensure it validates
 func NewUnfreezeRepoJobHandler(j *JobEntry) (*UnfreezeRepoJobHandler, error) {
-	if len(j.Params) != 1 {
-		return nil, fmt.Errorf("job has invalid parameters")
+	args := &RepoArgs{}
+	if err := j.decodeArgs(args); err != nil {
+		return nil, err
 	}
-	return &UnfreezeRepoJobHandler{repoID: j.Params[0]}, nil
+	return &UnfreezeRepoJobHandler{repoID: args.Repo}, nil
 }
 
 // Execute will attempt to Freeze the repos
-func (j *UnfreezeRepoJobHandler) Execute(jproc *Processor, manager *core.Manager) error {
+func (j *UnfreezeRepoJobHandler) Execute(ctx context.Context, jproc *Processor, manager *core.Manager) error {
 	if err := manager.UnfreezeRepo(j.repoID); err != nil {
-		log.WithFields(log.Fields{"repo": j.repoID, "error": err}).
+		log.WithContext(ctx).WithFields(log.Fields{"repo": j.repoID, "error": err}).
 			Warning("Failed to unfreeze repository")
 		return err
 	}
@@ -0,0 +1,301 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package jobs
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/getsolus/ferryd/src/libdb"
+	"github.com/getsolus/ferryd/src/libferry"
+)
+
+var (
+	// BucketHistory holds the completion record of every finished job still
+	// within the retention policy, keyed by job ID so that it is in order
+	BucketHistory = []byte("History")
+)
+
+// pruneInterval limits how often completing a job will prune the history
+const pruneInterval = time.Minute
+
+// A Retention policy decides how long the history of finished jobs is kept.
+// A zero field places no limit on the history.
+type Retention struct {
+	MaxAge   time.Duration // Jobs that finished longer ago than this are forgotten
+	MaxCount int           // Only this many of the newest jobs are remembered
+}
+
+// DefaultRetention is used until the daemon configures the store
+var DefaultRetention = Retention{
+	MaxAge:   90 * 24 * time.Hour,
+	MaxCount: 50000,
+}
+
+// historyKey returns the key of a job within the history
+func historyKey(id uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, id)
+	return key
+}
+
+// matchHistory reports whether the job is included by the query
+func matchHistory(j *libferry.Job, q *libferry.HistoryRequest) bool {
+	if q.Type != "" && j.Type != q.Type {
+		return false
+	}
+	if q.State != "" && j.State != q.State {
+		return false
+	}
+	if !q.Since.IsZero() && j.Timing.End.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !j.Timing.End.Before(q.Until) {
+		return false
+	}
+	if q.Repo == "" {
+		return true
+	}
+	for _, repo := range j.Repos {
+		if repo == q.Repo {
+			return true
+		}
+	}
+	return false
+}
+
+// History will return the finished jobs matching the query, newest job first
+func (s *JobStore) History(q *libferry.HistoryRequest) ([]*libferry.Job, error) {
+	s.modMut.Lock()
+	defer s.modMut.Unlock()
+
+	return s.historyInternal(func(j *libferry.Job) bool {
+		return matchHistory(j, q)
+	}, q.Limit)
+}
+
+// historyInternal will return up to limit jobs from the history accepted by
+// match, newest job first. The caller must hold modMut.
+func (s *JobStore) historyInternal(match func(j *libferry.Job) bool, limit int) ([]*libferry.Job, error) {
+	var ret []*libferry.Job
+	err := s.db.Bucket(BucketHistory).View(func(db libdb.ReadOnlyView) error {
+		return db.ForEach(func(k, v []byte) error {
+			j := &libferry.Job{}
+			if err := db.Decode(v, j); err != nil {
+				return err
+			}
+			if match(j) {
+				ret = append(ret, j)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Newest first
+	for i, j := 0, len(ret)-1; i < j; i, j = i+1, j-1 {
+		ret[i], ret[j] = ret[j], ret[i]
+	}
+	if limit > 0 && len(ret) > limit {
+		ret = ret[:limit]
+	}
+	return ret, nil
+}
+
+// SetRetention will change how long the history is kept, and immediately
+// forget anything beyond it
+func (s *JobStore) SetRetention(r Retention) error {
+	s.modMut.Lock()
+	defer s.modMut.Unlock()
+
+	s.retention = r
+	s.pruned = time.Now()
+	return s.pruneHistory()
+}
+
+// pruneHistory will forget the oldest jobs beyond the retention policy. The
+// caller must hold modMut.
+func (s *JobStore) pruneHistory() error {
+	bucket := s.db.Bucket(BucketHistory)
+
+	total := 0
+	if s.retention.MaxCount > 0 {
+		err := bucket.ForEach(func(k, v []byte) error {
+			total++
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Keys are in job order, so we only need to look until we find a job
+	// we're keeping
+	cutoff := time.Now().UTC().Add(-s.retention.MaxAge)
+	var expired [][]byte
+	err := bucket.ForEach(func(k, v []byte) error {
+		if s.retention.MaxCount > 0 && total-len(expired) > s.retention.MaxCount {
+			expired = append(expired, append([]byte(nil), k...))
+			return nil
+		}
+		if s.retention.MaxAge <= 0 {
+			return ErrBreakLoop
+		}
+		j := &libferry.Job{}
+		if err := bucket.Decode(v, j); err != nil {
+			return err
+		}
+		if !j.Timing.End.Before(cutoff) {
+			return ErrBreakLoop
+		}
+		expired = append(expired, append([]byte(nil), k...))
+		return nil
+	})
+	if err != nil && err != ErrBreakLoop {
+		return err
+	}
+
+	return s.deleteHistory(expired)
+}
+
+// deleteHistory removes the given jobs from the history, along with their
+// requeue records and logs. The caller must hold modMut.
+func (s *JobStore) deleteHistory(keys [][]byte) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return s.db.Update(func(db libdb.Database) error {
+		bucket := db.Bucket(BucketHistory)
+		for _, k := range keys {
+			for _, b := range []libdb.Database{bucket, bucket.Bucket(BucketRequeue), bucket.Bucket(BucketLogs)} {
+				if err := b.DeleteObject(k); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// resetHistory will forget every job in the history in any of the states
+func (s *JobStore) resetHistory(states ...string) error {
+	s.modMut.Lock()
+	defer s.modMut.Unlock()
+
+	var keys [][]byte
+	bucket := s.db.Bucket(BucketHistory)
+	err := bucket.ForEach(func(k, v []byte) error {
+		j := &libferry.Job{}
+		if err := bucket.Decode(v, j); err != nil {
+			return err
+		}
+		for _, state := range states {
+			if j.State == state {
+				keys = append(keys, append([]byte(nil), k...))
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return s.deleteHistory(keys)
+}
+
+// migrateHistory will move the completion records of older stores, held in
+// a ring buffer for each outcome, into the history
+func (s *JobStore) migrateHistory() error {
+	s.modMut.Lock()
+	defer s.modMut.Unlock()
+
+	for _, bk := range [][]byte{BucketSuccessJobs, BucketFailJobs} {
+		legacy := s.db.Bucket(bk)
+
+		var keys [][]byte
+		var jobs []*libferry.Job
+		err := legacy.ForEach(func(k, v []byte) error {
+			j := &libferry.Job{}
+			if err := legacy.Decode(v, j); err != nil {
+				return err
+			}
+			keys = append(keys, append([]byte(nil), k...))
+			jobs = append(jobs, j)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			continue
+		}
+
+		// Records from before job IDs were handed out need one now
+		for _, j := range jobs {
+			if j.ID != 0 {
+				continue
+			}
+			if j.ID, err = s.nextID(); err != nil {
+				return err
+			}
+		}
+
+		err = s.db.Update(func(db libdb.Database) error {
+			from := db.Bucket(bk)
+			to := db.Bucket(BucketHistory)
+			for i, j := range jobs {
+				key := historyKey(j.ID)
+				if j.State == "" {
+					j.State = libferry.JobCompleted
+					if j.Failed {
+						j.State = libferry.JobFailed
+					}
+				}
+
+				// Carry over the requeue record and log kept in the same slot
+				requeue := &requeueRecord{}
+				if err := from.Bucket(BucketRequeue).GetObject(keys[i], requeue); err == nil {
+					if err := to.Bucket(BucketRequeue).PutObject(key, requeue); err != nil {
+						return err
+					}
+				}
+				logs := &logRecord{}
+				if err := from.Bucket(BucketLogs).GetObject(keys[i], logs); err == nil {
+					if err := to.Bucket(BucketLogs).PutObject(key, logs); err != nil {
+						return err
+					}
+				}
+				for _, sub := range []libdb.Database{from.Bucket(BucketRequeue), from.Bucket(BucketLogs), from} {
+					if err := sub.DeleteObject(keys[i]); err != nil {
+						return err
+					}
+				}
+
+				if err := to.PutObject(key, j); err != nil {
+					return err
+				}
+			}
+			return from.Bucket(BucketRecord).DeleteObject(IndexRecordKey)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -17,6 +17,7 @@
 package jobs
 
 import (
+	"context"
 	"fmt"
 
 	log "github.com/sirupsen/logrus"
@@ -39,26 +40,28 @@ func NewIndexRepoJob(id string) *JobEntry {
 	return &JobEntry{
 		sequential: true,
 		Type:       IndexRepo,
-		Params:     []string{id},
+		Args:       encodeArgs(&RepoArgs{Repo: id}),
+		Repos:      []string{id},
 	}
 }
 
 // NewIndexRepoJobHandler will create a job handler for the input job and ensure it validates
 func NewIndexRepoJobHandler(j *JobEntry) (*IndexRepoJobHandler, error) {
-	if len(j.Params) != 1 {
-		return nil, fmt.Errorf("job has invalid parameters")
+	args := &RepoArgs{}
+	if err := j.decodeArgs(args); err != nil {
+		return nil, err
 	}
 	return &IndexRepoJobHandler{
-		repoID: j.Params[0],
+		repoID: args.Repo,
 	}, nil
 }
 
 // Execute will index the given repository if possible
-func (j *IndexRepoJobHandler) Execute(_ *Processor, manager *core.Manager) error {
-	if err := manager.Index(j.repoID); err != nil {
+func (j *IndexRepoJobHandler) Execute(ctx context.Context, _ *Processor, manager *core.Manager) error {
+	if err := manager.Index(ctx, j.repoID); err != nil {
 		return err
 	}
-	log.WithFields(log.Fields{"repo": j.repoID}).Info("Indexed repository")
+	log.WithContext(ctx).WithFields(log.Fields{"repo": j.repoID}).Info("Indexed repository")
 	return nil
 }
 
@@ -18,9 +18,11 @@ package jobs
 
 import (
 	"bytes"
-	"encoding/binary"
+	"context"
 	"encoding/gob"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/getsolus/ferryd/src/ferryd/core"
 	"github.com/getsolus/ferryd/src/libferry"
@@ -34,6 +36,12 @@ const (
 	// BulkAdd is a sequential job which will attempt to add all of the packages
 	BulkAdd JobType = "BulkAdd"
 
+	// CheckIntegrity is a sequential job to verify the pool and repositories
+	CheckIntegrity = "CheckIntegrity"
+
+	// CheckInstallable is a sequential job to find unresolved dependencies in a repo
+	CheckInstallable = "CheckInstallable"
+
 	// CopySource is a sequential job to copy from one repo to another
 	CopySource = "CopySource"
 
@@ -43,9 +51,15 @@ const (
 	// CreateRepo is a sequential job which will attempt to create a new repo
 	CreateRepo = "CreateRepo"
 
+	// CreateSnapshot is a sequential job to take a named snapshot of a repo
+	CreateSnapshot = "CreateSnapshot"
+
 	// DeleteRepo is a sequential job which will attempt to delete a repository
 	DeleteRepo = "DeleteRepo"
 
+	// DeleteSnapshot is a sequential job to remove a repository snapshot
+	DeleteSnapshot = "DeleteSnapshot"
+
 	// Delta is a parallel job which will attempt the construction of deltas for
 	// a given package name + repo
 	Delta = "Delta"
@@ -67,6 +81,21 @@ const (
 	// RemoveSource is a sequential job that will attempt removal of packages
 	RemoveSource = "RemoveSource"
 
+	// ReplacePackage is a sequential job to replace the content of existing
+	// pool entries across every repository
+	ReplacePackage = "ReplacePackage"
+
+	// RepairIntegrity is a sequential job to fix refcounts, missing hardlinks
+	// and orphaned pool files
+	RepairIntegrity = "RepairIntegrity"
+
+	// RestoreSnapshot is a sequential job to roll a repo back to a snapshot
+	RestoreSnapshot = "RestoreSnapshot"
+
+	// SetInstallGate is a sequential job to toggle the installability gate on
+	// indexing for a repo
+	SetInstallGate = "SetInstallGate"
+
 	// TransitProcess is a sequential job that will process the incoming uploads
 	// directory, dealing with each .tram upload
 	TransitProcess = "TransitProcess"
@@ -89,27 +118,52 @@ const (
 // of the job type
 type JobHandler interface {
 
-	// Execute will attempt to execute the given job
-	Execute(proc *Processor, m *core.Manager) error
+	// Execute will attempt to execute the given job, giving up once the
+	// context is done
+	Execute(ctx context.Context, proc *Processor, m *core.Manager) error
 
 	// Describe will return an appropriate description for the job
 	Describe() string
 }
 
+// A ResultHandler is a JobHandler that can report a result payload once it
+// has executed successfully, such as the packages changed by a pull
+type ResultHandler interface {
+	JobHandler
+
+	// Result returns a JSON encodable description of the job's outcome
+	Result() interface{}
+}
+
 // JobEntry is an entry in the JobQueue
 type JobEntry struct {
-	id         []byte // Unique ID for this job
-	sequential bool   // Private to the job implementation
-	Type       JobType
-	Claimed    bool
-	Params     []string
-	Timing     libferry.TimingInformation // Store all timing information
+	id          []byte // Key for this job within its queue
+	sequential  bool   // Private to the job implementation
+	ID          uint64 // Unique ID for this job, returned to clients
+	Type        JobType
+	Claimed     bool
+	Args        json.RawMessage            // Typed arguments of the job type, see args.go
+	ArgsVersion int                        // Version of Args, zero until the job is pushed
+	Params      []string                   // Positional arguments of jobs queued by older versions
+	Repos       []string                   // Repositories a sequential job uses, or all if empty
+	Priority    Priority                   // Higher priority jobs are claimed first
+	Timing      libferry.TimingInformation // Store all timing information
+	Attempt     int                        // How many times this job has been retried
+	NotBefore   time.Time                  // Retried and debounced jobs wait until this time
+	LastError   string                     // Error from the previous attempt, if retried
+	Log         []libferry.JobLogEntry     // Messages logged before the job was interrupted by shutdown
 
 	// Not serialised, set by the worker on claim
 	description string
 
 	// Not serialised, stored by the worker if the job fails
 	failure error
+
+	// Not serialised, stored by the worker if the job reports a result
+	result json.RawMessage
+
+	// Not serialised, stored by the worker with the messages logged by the job
+	log []libferry.JobLogEntry
 }
 
 // Serialize uses Gob encoding to convert a JobEntry to a byte slice
@@ -135,7 +189,33 @@ func Deserialize(serial []byte) (*JobEntry, error) {
 
 // GetID gets the true numerical ID for this job entry
 func (j *JobEntry) GetID() string {
-	return fmt.Sprintf("%v", binary.BigEndian.Uint64(j.id))
+	return fmt.Sprintf("%v", j.ID)
+}
+
+// status returns the client facing view of a job still in the queue
+func (j *JobEntry) status(description string) *libferry.Job {
+	state := libferry.JobQueued
+	if j.Claimed {
+		state = libferry.JobRunning
+	}
+	return &libferry.Job{
+		ID:          j.ID,
+		Type:        string(j.Type),
+		State:       state,
+		Args:        j.Args,
+		Repos:       j.Repos,
+		Priority:    j.Priority.String(),
+		Description: description,
+		Timing:      j.Timing,
+		Attempt:     j.Attempt,
+		NotBefore:   j.NotBefore,
+		Error:       j.LastError,
+	}
+}
+
+// ready reports whether the job may be claimed yet
+func (j *JobEntry) ready(now time.Time) bool {
+	return !j.Claimed && !now.Before(j.NotBefore)
 }
 
 // NewJobHandler will return a handler that is loaded only during the execution
@@ -144,22 +224,42 @@ func NewJobHandler(j *JobEntry) (JobHandler, error) {
 	switch j.Type {
 	case BulkAdd:
 		return NewBulkAddJobHandler(j)
+	case CheckIntegrity:
+		return NewCheckIntegrityJobHandler(j)
+	case CheckInstallable:
+		return NewCheckInstallableJobHandler(j)
 	case CopySource:
 		return NewCopySourceJobHandler(j)
 	case CloneRepo:
 		return NewCloneRepoJobHandler(j)
 	case CreateRepo:
 		return NewCreateRepoJobHandler(j)
+	case CreateSnapshot:
+		return NewCreateSnapshotJobHandler(j)
 	case DeleteRepo:
 		return NewDeleteRepoJobHandler(j)
+	case DeleteSnapshot:
+		return NewDeleteSnapshotJobHandler(j)
+	case Delta:
+		return NewDeltaJobHandler(j, false)
+	case DeltaIndex:
+		return NewDeltaJobHandler(j, true)
 	case DeltaRepo:
 		return NewDeltaRepoJobHandler(j)
 	case IndexRepo:
 		return NewIndexRepoJobHandler(j)
 	case RemoveSource:
 		return NewRemoveSourceJobHandler(j)
+	case ReplacePackage:
+		return NewReplacePackageJobHandler(j)
+	case RepairIntegrity:
+		return NewRepairIntegrityJobHandler(j)
 	case PullRepo:
 		return NewPullRepoJobHandler(j)
+	case RestoreSnapshot:
+		return NewRestoreSnapshotJobHandler(j)
+	case SetInstallGate:
+		return NewSetInstallGateJobHandler(j)
 	case TransitProcess:
 		return NewTransitJobHandler(j)
 	case TrimObsolete:
@@ -0,0 +1,185 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package jobs
+
+import (
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getsolus/ferryd/src/libferry"
+)
+
+var (
+	// BucketPause holds the pause state, so that it survives a restart
+	BucketPause = []byte("Pause")
+
+	// pauseKey is where the pause state is stored within its bucket
+	pauseKey = []byte("State")
+
+	// ErrUnknownQueue is returned when pausing a queue that doesn't exist
+	ErrUnknownQueue = errors.New("Unknown queue, expected sequential or async")
+)
+
+// PauseState will return the stored pause state, with nothing paused if it
+// was never stored
+func (s *JobStore) PauseState() (*libferry.PauseState, error) {
+	s.modMut.Lock()
+	defer s.modMut.Unlock()
+
+	bucket := s.db.Bucket(BucketPause)
+	has, err := bucket.HasObject(pauseKey)
+	if err != nil || !has {
+		return &libferry.PauseState{}, err
+	}
+	p := &libferry.PauseState{}
+	if err := bucket.GetObject(pauseKey, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SetPauseState will store the pause state
+func (s *JobStore) SetPauseState(p *libferry.PauseState) error {
+	s.modMut.Lock()
+	defer s.modMut.Unlock()
+
+	return s.db.Bucket(BucketPause).PutObject(pauseKey, p)
+}
+
+// PauseState will return which queues are paused, and whether we're in
+// maintenance
+func (j *Processor) PauseState() libferry.PauseState {
+	j.pauseMut.Lock()
+	defer j.pauseMut.Unlock()
+
+	return j.pause
+}
+
+// paused reports whether workers of the queue may claim jobs
+func (j *Processor) paused(sequential bool) bool {
+	j.pauseMut.Lock()
+	defer j.pauseMut.Unlock()
+
+	if sequential {
+		return j.pause.Sequential
+	}
+	return j.pause.Async
+}
+
+// Pause will stop jobs being started from the queue, or from both queues
+// when queue is empty. Running jobs are left to finish, and jobs may still be
+// pushed to a paused queue.
+func (j *Processor) Pause(queue string) error {
+	return j.setPaused(queue, true)
+}
+
+// Resume will allow jobs to be started from the queue again, or from both
+// queues when queue is empty
+func (j *Processor) Resume(queue string) error {
+	return j.setPaused(queue, false)
+}
+
+// setPaused will pause or resume the queue. While in maintenance both queues
+// stay paused, and the change only applies once maintenance is left.
+func (j *Processor) setPaused(queue string, paused bool) error {
+	return j.updatePause(func(p *libferry.PauseState) error {
+		sequential, async := &p.Sequential, &p.Async
+		if p.Maintenance {
+			sequential, async = &p.RestoreSequential, &p.RestoreAsync
+		}
+		switch queue {
+		case "":
+			*sequential, *async = paused, paused
+		case libferry.QueueSequential:
+			*sequential = paused
+		case libferry.QueueAsync:
+			*async = paused
+		default:
+			return ErrUnknownQueue
+		}
+		return nil
+	})
+}
+
+// SetMaintenance will enter or leave maintenance. Both queues are paused
+// while in maintenance, and on leaving it each queue goes back to whether it
+// was paused beforehand.
+func (j *Processor) SetMaintenance(enabled bool, reason string) error {
+	return j.updatePause(func(p *libferry.PauseState) error {
+		switch {
+		case enabled && !p.Maintenance:
+			p.RestoreSequential, p.RestoreAsync = p.Sequential, p.Async
+			p.Sequential, p.Async = true, true
+		case !enabled && p.Maintenance:
+			p.Sequential, p.Async = p.RestoreSequential, p.RestoreAsync
+			p.RestoreSequential, p.RestoreAsync = false, false
+		}
+		p.Maintenance = enabled
+		p.Reason = ""
+		if enabled {
+			p.Reason = reason
+		}
+		return nil
+	})
+}
+
+// updatePause will apply the change to the pause state and store it, waking
+// the workers of any resumed queue
+func (j *Processor) updatePause(change func(p *libferry.PauseState) error) error {
+	j.pauseMut.Lock()
+	defer j.pauseMut.Unlock()
+
+	p := j.pause
+	if err := change(&p); err != nil {
+		return err
+	}
+	switch {
+	case !p.Paused():
+		p.Since = time.Time{}
+	case !j.pause.Paused():
+		p.Since = time.Now().UTC()
+	}
+	if err := j.store.SetPauseState(&p); err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"sequential":  p.Sequential,
+		"async":       p.Async,
+		"maintenance": p.Maintenance,
+		"reason":      p.Reason,
+	}).Info("Changed queue pause state")
+
+	resumed := []struct {
+		was, now bool
+		wake     chan struct{}
+	}{
+		{j.pause.Sequential, p.Sequential, j.syncWake},
+		{j.pause.Async, p.Async, j.asyncWake},
+	}
+	j.pause = p
+	for _, q := range resumed {
+		if q.was && !q.now {
+			for i := 0; i < cap(q.wake); i++ {
+				j.wake(q.wake)
+			}
+		}
+	}
+	return nil
+}
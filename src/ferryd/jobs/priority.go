@@ -0,0 +1,62 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package jobs
+
+// A Priority decides which available job is claimed first from either queue.
+// Jobs of a higher priority may overtake queued jobs of a lower priority, and
+// jobs of the same priority keep their order. Sequential jobs never overtake a
+// job queued or running on the same repository.
+type Priority int
+
+const (
+	// PriorityLow is used for bulk maintenance that can wait, such as deltas
+	PriorityLow Priority = -1
+
+	// PriorityNormal is the default for any job type not listed in priorities
+	PriorityNormal Priority = 0
+
+	// PriorityHigh is used for jobs that make uploads available to users
+	PriorityHigh Priority = 1
+)
+
+// priorities are the default priorities for each job type
+var priorities = map[JobType]Priority{
+	TransitProcess: PriorityHigh,
+	IndexRepo:      PriorityHigh,
+	Delta:          PriorityLow,
+	DeltaIndex:     PriorityLow,
+	DeltaRepo:      PriorityLow,
+	TrimObsolete:   PriorityLow,
+	TrimPackages:   PriorityLow,
+}
+
+// String returns the name of the priority for display in clients
+func (p Priority) String() string {
+	switch {
+	case p > PriorityNormal:
+		return "high"
+	case p < PriorityNormal:
+		return "low"
+	default:
+		return "normal"
+	}
+}
+
+// defaultPriority returns the priority for new jobs of the given type
+func defaultPriority(t JobType) Priority {
+	return priorities[t]
+}
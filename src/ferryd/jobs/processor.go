@@ -17,12 +17,28 @@
 package jobs
 
 import (
+	"context"
 	"runtime"
 	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
 	"github.com/getsolus/ferryd/src/ferryd/core"
+	"github.com/getsolus/ferryd/src/libferry"
+)
+
+var (
+	// ShutdownGrace is how long Close waits for running jobs to finish before
+	// cancelling them. Cancelled jobs are run again on the next start.
+	ShutdownGrace = 30 * time.Second
+
+	// indexDelay is how long a dirty repository waits for further changes
+	// before it is reindexed
+	indexDelay = 30 * time.Second
+
+	// indexMaxDelay limits how long a busy repository may stay dirty
+	indexMaxDelay = 5 * time.Minute
 )
 
 // A Processor is responsible for the main dispatch and bulking of jobs
@@ -34,12 +50,31 @@ type Processor struct {
 	closed  bool
 	njobs   int
 	workers []*Worker
+
+	ctx    context.Context // Cancelled to interrupt running jobs on Close
+	cancel context.CancelFunc
+
+	syncWake  chan struct{} // Wakes one of the sequential workers
+	asyncWake chan struct{} // Wakes one of the async workers
+
+	activityMut *sync.Mutex
+	activity    map[uint64]*jobActivity // Progress and logs of running jobs, by ID
+
+	scheduleMut   *sync.Mutex
+	schedule      []*ScheduleEntry           // Recurring jobs
+	scheduled     map[string]*scheduleRecord // When each recurring job is due, by name
+	scheduleTimer *time.Timer                // Fires when the next recurring job is due
+
+	pauseMut *sync.Mutex
+	pause    libferry.PauseState // Queues which may not start jobs
 }
 
 // NewProcessor will return a new Processor with the specified number
 // of jobs. Note that "njobs" only refers to the number of *background jobs*,
-// the majority of operations will run sequentially
-func NewProcessor(m *core.Manager, store *JobStore, njobs int) *Processor {
+// the majority of operations will run sequentially. Sequential jobs are
+// ordered per repository, and "nrepos" sets how many repositories may have
+// a sequential job running at the same time.
+func NewProcessor(m *core.Manager, store *JobStore, njobs, nrepos int) *Processor {
 	// If we set to -1, we'll automatically set to half of the system core count
 	// because we use xz -T 2 (so twice the number of threads ..)
 	if njobs < 0 {
@@ -50,6 +85,10 @@ func NewProcessor(m *core.Manager, store *JobStore, njobs int) *Processor {
 		njobs = runtime.NumCPU()
 	}
 
+	if nrepos < 1 {
+		nrepos = 1
+	}
+
 	oldJobs := runtime.GOMAXPROCS(njobs + 5)
 	// Don't intentionally break things.
 	if oldJobs < njobs+5 {
@@ -60,18 +99,59 @@ func NewProcessor(m *core.Manager, store *JobStore, njobs int) *Processor {
 		"jobs":        njobs,
 		"oldMaxProcs": oldJobs,
 		"maxProcs":    njobs + 5,
+		"repoJobs":    nrepos,
 	}).Info("Set runtime job limits")
 
+	ctx, cancel := context.WithCancel(context.Background())
+
 	ret := &Processor{
+		ctx:     ctx,
+		cancel:  cancel,
 		manager: m,
 		store:   store,
 		wg:      &sync.WaitGroup{},
 		closed:  false,
 		njobs:   njobs,
+
+		// Buffer one wakeup per worker so that bursts of pushes wake every
+		// idle worker, while further pushes are picked up as workers drain
+		syncWake:  make(chan struct{}, nrepos),
+		asyncWake: make(chan struct{}, njobs),
+
+		activityMut: &sync.Mutex{},
+		activity:    make(map[uint64]*jobActivity),
+
+		scheduleMut: &sync.Mutex{},
+
+		pauseMut: &sync.Mutex{},
 	}
 
+	// Stay paused across restarts
+	if pause, err := store.PauseState(); err == nil {
+		ret.pause = *pause
+		if pause.Paused() {
+			log.WithFields(log.Fields{
+				"sequential":  pause.Sequential,
+				"async":       pause.Async,
+				"maintenance": pause.Maintenance,
+				"reason":      pause.Reason,
+			}).Warning("Queues are paused")
+		}
+	} else {
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Error("Failed to load pause state, queues are running")
+	}
+
+	// Capture the log messages of each job
+	installLogHook.Do(func() {
+		log.AddHook(jobLogHook{})
+	})
+
 	// Construct worker pool
-	ret.workers = append(ret.workers, NewWorkerSequential(ret))
+	for i := 0; i < nrepos; i++ {
+		ret.workers = append(ret.workers, NewWorkerSequential(ret))
+	}
 	for i := 0; i < njobs; i++ {
 		ret.workers = append(ret.workers, NewWorkerAsync(ret))
 	}
@@ -79,19 +159,35 @@ func NewProcessor(m *core.Manager, store *JobStore, njobs int) *Processor {
 	return ret
 }
 
-// Close an existing Processor, waiting for all jobs to complete
+// Close an existing Processor, waiting for all jobs to complete. Jobs still
+// running after ShutdownGrace are cancelled, and left to run again next time.
 func (j *Processor) Close() {
 	if j.closed {
 		return
 	}
 	j.closed = true
 
+	j.stopSchedule()
+
 	// Close all of our workers
 	for _, j := range j.workers {
 		j.Stop()
 	}
 
-	j.wg.Wait()
+	done := make(chan struct{})
+	go func() {
+		j.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(ShutdownGrace):
+		log.Warning("Cancelling running jobs")
+	}
+
+	j.cancel()
+	<-done
 }
 
 // Begin will start the main job processor in parallel
@@ -99,18 +195,77 @@ func (j *Processor) Begin() {
 	if j.closed {
 		return
 	}
-	j.wg.Add(j.njobs + 1)
+	j.wg.Add(len(j.workers))
 	for _, j := range j.workers {
 		go j.Start()
 	}
 }
 
 // PushJob will automatically determine which queue to push a job to and place
-// it there for immediate execution
-func (j *Processor) PushJob(job *JobEntry) {
+// it there for immediate execution, returning the ID assigned to the job
+func (j *Processor) PushJob(job *JobEntry) (uint64, error) {
+	var err error
+	var wake chan struct{}
 	if job.sequential {
-		j.store.PushSequentialJob(job)
+		err = j.store.PushSequentialJob(job)
+		wake = j.syncWake
 	} else {
-		j.store.PushAsyncJob(job)
+		err = j.store.PushAsyncJob(job)
+		wake = j.asyncWake
+	}
+	if err != nil {
+		return 0, err
+	}
+	j.wake(wake)
+	return job.ID, nil
+}
+
+// wake will rouse an idle worker, unless enough wakeups are already pending
+func (j *Processor) wake(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// CancelJob will remove a job from the queue, so long as it hasn't started
+func (j *Processor) CancelJob(id uint64) error {
+	return j.store.CancelJob(id)
+}
+
+// RequeueJob will push a failed or cancelled job again with the same
+// parameters, returning the ID of the new job
+func (j *Processor) RequeueJob(id uint64) (uint64, error) {
+	job, err := j.store.FailedJob(id)
+	if err != nil {
+		return 0, err
+	}
+	return j.PushJob(job)
+}
+
+// GetJob will return the state of a queued, running or completed job
+func (j *Processor) GetJob(id uint64) (*libferry.Job, error) {
+	job, err := j.store.GetJob(id)
+	if err != nil {
+		return nil, err
+	}
+	if a := j.getActivity(id); a != nil && job.State == libferry.JobRunning {
+		job.Progress = a.getProgress()
+	}
+	return job, nil
+}
+
+// MarkDirty will schedule the repository to be reindexed once it has seen no
+// further changes for a short while, so that a burst of changes results in a
+// single IndexRepo job. The job is queued straight away so that it survives a
+// restart, and each further change pushes back the job still waiting.
+func (j *Processor) MarkDirty(ctx context.Context, repoID string) {
+	job := NewIndexRepoJob(repoID)
+	job.NotBefore = time.Now().UTC().Add(indexDelay)
+	if _, err := j.PushJob(job); err != nil {
+		log.WithContext(ctx).WithFields(log.Fields{
+			"repo":  repoID,
+			"error": err,
+		}).Error("Failed to schedule repository index")
 	}
 }
@@ -0,0 +1,421 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package jobs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getsolus/ferryd/src/ferryd/core"
+	"github.com/getsolus/ferryd/src/libferry"
+)
+
+// initTestProcessor will start a processor against a fresh manager and store
+func initTestProcessor(tb testing.TB) *Processor {
+	dir := tb.TempDir()
+	manager, err := core.NewManager(dir)
+	if err != nil {
+		tb.Fatalf("Failed to create manager: %v", err)
+	}
+	tb.Cleanup(manager.Close)
+
+	store, err := NewStore(dir)
+	if err != nil {
+		tb.Fatalf("Failed to create job store: %v", err)
+	}
+	tb.Cleanup(store.Close)
+
+	proc := NewProcessor(manager, store, 2, 2)
+	proc.Begin()
+	tb.Cleanup(proc.Close)
+	return proc
+}
+
+// waitTestJob will block until the job has finished, failing if it failed
+func waitTestJob(tb testing.TB, proc *Processor, job *JobEntry) {
+	id, err := proc.PushJob(job)
+	if err != nil {
+		tb.Fatalf("Failed to push job: %v", err)
+	}
+	for {
+		j, err := proc.GetJob(id)
+		if err != nil {
+			tb.Fatalf("Failed to get job %d: %v", id, err)
+		}
+		if j.Failed {
+			tb.Fatalf("Job %d failed: %s", id, j.Error)
+		}
+		if j.Finished() {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPushWakesWorker(t *testing.T) {
+	proc := initTestProcessor(t)
+	waitTestJob(t, proc, NewCreateRepoJob("test"))
+
+	// Without a wakeup we'd wait at least MinWait for the timer
+	start := time.Now()
+	waitTestJob(t, proc, NewIndexRepoJob("test"))
+	if elapsed := time.Since(start); elapsed >= MinWait {
+		t.Fatalf("Job took %v to complete, worker was not woken", elapsed)
+	}
+}
+
+func TestMarkDirty(t *testing.T) {
+	proc := initTestProcessor(t)
+	indexDelay, indexMaxDelay = 50*time.Millisecond, time.Second
+	t.Cleanup(func() { indexDelay, indexMaxDelay = 30*time.Second, 5*time.Minute })
+	waitTestJob(t, proc, NewCreateRepoJob("test"))
+
+	for i := 0; i < 5; i++ {
+		proc.MarkDirty(context.Background(), "test")
+	}
+
+	// The reindex is queued at once so that it survives a restart
+	active, err := proc.store.ActiveJobs()
+	if err != nil {
+		t.Fatalf("Failed to get jobs: %v", err)
+	}
+	if len(active) != 1 || active[0].Type != IndexRepo {
+		t.Fatalf("Expected a single queued reindex, got %v", active)
+	}
+
+	// countIndexed returns how many index jobs have completed
+	countIndexed := func() int {
+		jobs, err := proc.store.CompletedJobs()
+		if err != nil {
+			t.Fatalf("Failed to get jobs: %v", err)
+		}
+		n := 0
+		for _, j := range jobs {
+			if j.Type == IndexRepo {
+				n++
+			}
+		}
+		return n
+	}
+
+	for countIndexed() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Give any stray index jobs the chance to run
+	time.Sleep(2 * indexDelay)
+	if n := countIndexed(); n != 1 {
+		t.Fatalf("Expected a single reindex, got %d", n)
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	proc := initTestProcessor(t)
+	waitTestJob(t, proc, NewCreateRepoJob("test"))
+
+	dir := t.TempDir()
+	if entries, err := LoadSchedule(filepath.Join(dir, "missing.toml")); err != nil || entries != nil {
+		t.Fatalf("Expected nothing scheduled without a configuration, got %v (%v)", entries, err)
+	}
+
+	path := filepath.Join(dir, "schedule.toml")
+	write := func(config string) {
+		if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+			t.Fatalf("Failed to write schedule: %v", err)
+		}
+	}
+	write("[[job]]\ntype = \"DeleteRepo\"\nrepo = \"test\"\nschedule = \"@daily\"\n")
+	if _, err := LoadSchedule(path); err == nil {
+		t.Fatalf("Expected DeleteRepo to be refused")
+	}
+
+	write(`
+[[job]]
+type = "IndexRepo"
+repo = "test"
+schedule = "@daily"
+
+[[job]]
+name = "trim"
+type = "TrimPackages"
+repo = "test"
+keep = 3
+schedule = "@weekly at 04:00"
+`)
+	entries, err := LoadSchedule(path)
+	if err != nil {
+		t.Fatalf("Failed to load schedule: %v", err)
+	}
+
+	// The index was due while ferryd was down
+	missed := time.Now().Add(-time.Hour)
+	err = proc.store.saveSchedule(map[string]*scheduleRecord{
+		"IndexRepo test": {Schedule: "@daily", NextRun: missed},
+		"removed":        {Schedule: "@daily", NextRun: missed},
+	})
+	if err != nil {
+		t.Fatalf("Failed to store schedule: %v", err)
+	}
+	if err := proc.Schedule(entries); err != nil {
+		t.Fatalf("Failed to schedule jobs: %v", err)
+	}
+
+	scheduled := proc.ScheduledJobs()
+	if len(scheduled) != 2 {
+		t.Fatalf("Expected 2 scheduled jobs, got %+v", scheduled)
+	}
+	for _, s := range scheduled {
+		if !s.NextRun.After(time.Now()) {
+			t.Fatalf("Scheduled job %s must next run in the future, got %v", s.Name, s.NextRun)
+		}
+		if ran := s.LastJob != 0; ran != (s.Name == "IndexRepo test") {
+			t.Fatalf("Only the missed job should have run: %+v", s)
+		}
+	}
+
+	records, err := proc.store.scheduleRecords()
+	if err != nil {
+		t.Fatalf("Failed to get schedule: %v", err)
+	}
+	if _, ok := records["removed"]; ok || len(records) != 2 {
+		t.Fatalf("Expected only the configured jobs to be stored, got %v", records)
+	}
+}
+
+func TestPause(t *testing.T) {
+	proc := initTestProcessor(t)
+	waitTestJob(t, proc, NewCreateRepoJob("test"))
+
+	if err := proc.Pause("bogus"); err != ErrUnknownQueue {
+		t.Fatalf("Expected unknown queue, got %v", err)
+	}
+	if err := proc.Pause(libferry.QueueSequential); err != nil {
+		t.Fatalf("Failed to pause: %v", err)
+	}
+
+	// Jobs are still accepted, but not started
+	id, err := proc.PushJob(NewIndexRepoJob("test"))
+	if err != nil {
+		t.Fatalf("Failed to push job: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if job, err := proc.GetJob(id); err != nil || job.State != libferry.JobQueued {
+		t.Fatalf("Expected job to stay queued while paused, got %+v (%v)", job, err)
+	}
+
+	// The state is kept for the next start
+	stored, err := proc.store.PauseState()
+	if err != nil {
+		t.Fatalf("Failed to get pause state: %v", err)
+	}
+	if !stored.Sequential || stored.Async || stored.Since.IsZero() {
+		t.Fatalf("Unexpected stored pause state: %+v", stored)
+	}
+
+	// Entering maintenance again must not forget the queues paused before it
+	for _, reason := range []string{"backup", "backup again"} {
+		if err := proc.SetMaintenance(true, reason); err != nil {
+			t.Fatalf("Failed to enter maintenance: %v", err)
+		}
+	}
+	if p := proc.PauseState(); !p.Sequential || !p.Async || !p.Maintenance || p.Reason != "backup again" {
+		t.Fatalf("Unexpected maintenance state: %+v", p)
+	}
+	if err := proc.SetMaintenance(false, ""); err != nil {
+		t.Fatalf("Failed to leave maintenance: %v", err)
+	}
+	if p := proc.PauseState(); !p.Sequential || p.Async || p.Maintenance || p.Reason != "" {
+		t.Fatalf("Expected only the sequential queue to stay paused, got %+v", p)
+	}
+
+	// Queues paused during maintenance stay paused after it
+	if err := proc.SetMaintenance(true, "backup"); err != nil {
+		t.Fatalf("Failed to enter maintenance: %v", err)
+	}
+	if err := proc.Resume(libferry.QueueSequential); err != nil {
+		t.Fatalf("Failed to resume: %v", err)
+	}
+	if p := proc.PauseState(); !p.Sequential || !p.Async || p.RestoreSequential || !p.Maintenance {
+		t.Fatalf("Expected queues to stay paused until maintenance is left, got %+v", p)
+	}
+	if err := proc.Pause(libferry.QueueSequential); err != nil {
+		t.Fatalf("Failed to pause: %v", err)
+	}
+	if err := proc.Pause(libferry.QueueAsync); err != nil {
+		t.Fatalf("Failed to pause: %v", err)
+	}
+	if err := proc.SetMaintenance(false, ""); err != nil {
+		t.Fatalf("Failed to leave maintenance: %v", err)
+	}
+	if p := proc.PauseState(); !p.Sequential || !p.Async || p.Maintenance {
+		t.Fatalf("Expected both queues to stay paused, got %+v", p)
+	}
+	if err := proc.Resume(""); err != nil {
+		t.Fatalf("Failed to resume: %v", err)
+	}
+	if p := proc.PauseState(); p.Paused() || !p.Since.IsZero() {
+		t.Fatalf("Expected everything resumed, got %+v", p)
+	}
+
+	// Resuming wakes the workers rather than waiting on the timer
+	start := time.Now()
+	for {
+		job, err := proc.GetJob(id)
+		if err != nil {
+			t.Fatalf("Failed to get job: %v", err)
+		}
+		if job.Finished() {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if elapsed := time.Since(start); elapsed >= MinWait {
+		t.Fatalf("Job took %v to start after resuming", elapsed)
+	}
+}
+
+func TestJobTimeout(t *testing.T) {
+	proc := initTestProcessor(t)
+	timeouts[CreateRepo] = time.Nanosecond
+	t.Cleanup(func() { timeouts[CreateRepo] = 10 * time.Minute })
+
+	id, err := proc.PushJob(NewCreateRepoJob("test"))
+	if err != nil {
+		t.Fatalf("Failed to push job: %v", err)
+	}
+	for {
+		j, err := proc.GetJob(id)
+		if err != nil {
+			t.Fatalf("Failed to get job %d: %v", id, err)
+		}
+		if !j.Finished() {
+			time.Sleep(time.Millisecond)
+			continue
+		}
+		if !j.Failed || !strings.HasPrefix(j.Error, ErrJobTimeout.Error()) {
+			t.Fatalf("Expected job to time out, got %+v", j)
+		}
+		return
+	}
+}
+
+func TestJobLog(t *testing.T) {
+	proc := initTestProcessor(t)
+	waitTestJob(t, proc, NewCreateRepoJob("test"))
+
+	job := NewIndexRepoJob("test")
+	waitTestJob(t, proc, job)
+
+	entries, err := proc.JobLog(job.ID)
+	if err != nil {
+		t.Fatalf("Failed to get job log: %v", err)
+	}
+	var messages []string
+	for _, e := range entries {
+		messages = append(messages, e.Message)
+		if e.Message == "Indexed repository" && e.Fields["repo"] != "test" {
+			t.Fatalf("Log entry is missing its fields: %+v", e)
+		}
+	}
+	// Messages logged by core on behalf of the job are included
+	want := []string{
+		"No distribution.xml defined",
+		"No components.xml defined",
+		"No groups.xml defined",
+		"Indexed repository",
+		"Job completed successfully",
+	}
+	if strings.Join(messages, ",") != strings.Join(want, ",") {
+		t.Fatalf("Expected log %v, got %v", want, messages)
+	}
+
+	// Each job only has its own messages
+	entries, err = proc.JobLog(job.ID - 1)
+	if err != nil || len(entries) != len(want) || entries[3].Message != "Created repository" {
+		t.Fatalf("Unexpected log for the create job: %v (%v)", entries, err)
+	}
+}
+
+func TestInterruptedJobLog(t *testing.T) {
+	dir := t.TempDir()
+	manager, err := core.NewManager(dir)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	t.Cleanup(manager.Close)
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("Failed to create job store: %v", err)
+	}
+	t.Cleanup(store.Close)
+
+	// Not started, so we can play the part of the worker
+	proc := NewProcessor(manager, store, 1, 1)
+	if err := store.PushSequentialJob(NewIndexRepoJob("test")); err != nil {
+		t.Fatalf("Failed to push job: %v", err)
+	}
+	job, err := store.ClaimSequentialJob()
+	if err != nil {
+		t.Fatalf("Failed to claim job: %v", err)
+	}
+	ctx := proc.startActivity(context.Background(), job)
+	log.WithContext(ctx).Info("Before shutdown")
+	proc.endActivity(job, false)
+	if err := store.InterruptSequentialJob(job); err != nil {
+		t.Fatalf("Failed to keep interrupted job: %v", err)
+	}
+	if a := proc.getActivity(job.ID); a != nil {
+		t.Fatalf("Interrupted job must not be left active")
+	}
+
+	// Run it again as if we'd restarted
+	if err := store.UnclaimSequential(); err != nil {
+		t.Fatalf("Failed to unclaim jobs: %v", err)
+	}
+	job, err = store.ClaimSequentialJob()
+	if err != nil {
+		t.Fatalf("Failed to claim job again: %v", err)
+	}
+	ctx = proc.startActivity(context.Background(), job)
+	log.WithContext(ctx).Info("After restart")
+	entries, err := proc.JobLog(job.ID)
+	if err != nil {
+		t.Fatalf("Failed to get job log: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Message != "Before shutdown" || entries[1].Message != "After restart" {
+		t.Fatalf("Interrupted job must keep its log, got %+v", entries)
+	}
+}
+
+// BenchmarkIndexLatency measures the time from pushing an index job until it
+// has completed, which was bound by the worker polling interval.
+func BenchmarkIndexLatency(b *testing.B) {
+	log.SetLevel(log.ErrorLevel)
+	proc := initTestProcessor(b)
+	waitTestJob(b, proc, NewCreateRepoJob("test"))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		waitTestJob(b, proc, NewIndexRepoJob("test"))
+	}
+}
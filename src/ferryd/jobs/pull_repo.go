@@ -17,6 +17,7 @@
 package jobs
 
 import (
+	"context"
 	"fmt"
 
 	log "github.com/sirupsen/logrus"
@@ -28,53 +29,93 @@ import (
 type PullRepoJobHandler struct {
 	sourceID string
 	targetID string
+	opts     *core.PullOptions
+	result   *core.PullResult
 }
 
-// NewPullRepoJob will return a job suitable for adding to the job processor
-func NewPullRepoJob(sourceID, targetID string) *JobEntry {
+// PullRepoArgs are the arguments of a PullRepo job
+type PullRepoArgs struct {
+	Source  string            `json:"source"`
+	Target  string            `json:"target"`
+	Options *core.PullOptions `json:"options,omitempty"`
+}
+
+// NewPullRepoJob will return a job suitable for adding to the job processor.
+// The options are optional.
+func NewPullRepoJob(sourceID, targetID string, opts *core.PullOptions) *JobEntry {
 	return &JobEntry{
 		sequential: true,
 		Type:       PullRepo,
-		Params:     []string{sourceID, targetID},
+		Args:       encodeArgs(&PullRepoArgs{Source: sourceID, Target: targetID, Options: opts}),
+		Repos:      []string{sourceID, targetID},
 	}
 }
 
 // NewPullRepoJobHandler will create a job handler for the input job and ensure it validates
 func NewPullRepoJobHandler(j *JobEntry) (*PullRepoJobHandler, error) {
-	if len(j.Params) != 2 {
-		return nil, fmt.Errorf("job has invalid parameters")
+	args := &PullRepoArgs{}
+	if err := j.decodeArgs(args); err != nil {
+		return nil, err
 	}
 	return &PullRepoJobHandler{
-		sourceID: j.Params[0],
-		targetID: j.Params[1],
+		sourceID: args.Source,
+		targetID: args.Target,
+		opts:     args.Options,
 	}, nil
 }
 
 // Execute will attempt to pull the repos
-func (j *PullRepoJobHandler) Execute(jproc *Processor, manager *core.Manager) error {
-	changedNames, err := manager.PullRepo(j.sourceID, j.targetID)
+func (j *PullRepoJobHandler) Execute(ctx context.Context, jproc *Processor, manager *core.Manager) error {
+	result, err := manager.PullRepo(ctx, j.sourceID, j.targetID, j.opts)
 	if err != nil {
-		log.WithFields(log.Fields{
+		log.WithContext(ctx).WithFields(log.Fields{
 			"source": j.sourceID,
 			"target": j.targetID,
 			"error":  err,
 		}).Warning("Failed to pull repository")
 		return err
 	}
+	j.result = result
+
+	for _, conflict := range result.Skipped {
+		log.WithContext(ctx).WithFields(log.Fields{
+			"target":        j.targetID,
+			"package":       conflict.Name,
+			"sourceRelease": conflict.SourceRelease,
+			"targetRelease": conflict.TargetRelease,
+		}).Warning("Skipped package newer in target")
+	}
+	for _, conflict := range result.Downgraded {
+		log.WithContext(ctx).WithFields(log.Fields{
+			"target":        j.targetID,
+			"package":       conflict.Name,
+			"sourceRelease": conflict.SourceRelease,
+			"targetRelease": conflict.TargetRelease,
+		}).Warning("Downgraded package newer in target")
+	}
 
-	log.WithFields(log.Fields{
-		"source": j.sourceID,
-		"target": j.targetID,
+	log.WithContext(ctx).WithFields(log.Fields{
+		"source":  j.sourceID,
+		"target":  j.targetID,
+		"changed": result.Changed,
+		"skipped": len(result.Skipped),
+		"deltas":  len(result.Deltas),
 	}).Info("Pulled repository")
 
 	if !deltasEnabled {
 		return nil
 	}
 
-	// Create delta job in this repository on the changed names
-	// Don't cause indexing because it causes noise
-	for _, pkg := range changedNames {
-		jproc.PushJob(NewDeltaIndexJob(j.targetID, pkg))
+	// Create delta job in this repository for anything we couldn't carry over.
+	// These share a single debounced reindex once their deltas are produced.
+	for _, pkg := range result.NeedDeltas {
+		if _, err := jproc.PushJob(NewDeltaIndexJob(j.targetID, pkg)); err != nil {
+			log.WithContext(ctx).WithFields(log.Fields{
+				"repo":    j.targetID,
+				"package": pkg,
+				"error":   err,
+			}).Error("Failed to schedule delta job")
+		}
 	}
 
 	return nil
@@ -82,5 +123,13 @@ func (j *PullRepoJobHandler) Execute(jproc *Processor, manager *core.Manager) er
 
 // Describe returns a human readable description for this job
 func (j *PullRepoJobHandler) Describe() string {
+	if j.opts != nil && j.opts.Filter != nil {
+		return fmt.Sprintf("Selectively pull repository '%s' into '%s'", j.sourceID, j.targetID)
+	}
 	return fmt.Sprintf("Pull repository '%s' into '%s'", j.sourceID, j.targetID)
 }
+
+// Result returns the packages changed by the pull
+func (j *PullRepoJobHandler) Result() interface{} {
+	return j.result
+}
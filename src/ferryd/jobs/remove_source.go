@@ -17,8 +17,8 @@
 package jobs
 
 import (
+	"context"
 	"fmt"
-	"strconv"
 
 	log "github.com/sirupsen/logrus"
 
@@ -32,37 +32,42 @@ type RemoveSourceJobHandler struct {
 	release int
 }
 
+// RemoveSourceArgs are the arguments of a RemoveSource job
+type RemoveSourceArgs struct {
+	Repo    string `json:"repo"`
+	Source  string `json:"source"`
+	Release int    `json:"release"`
+}
+
 // NewRemoveSourceJob will return a job suitable for adding to the job processor
 func NewRemoveSourceJob(repoID, source string, release int) *JobEntry {
 	return &JobEntry{
 		sequential: true,
 		Type:       RemoveSource,
-		Params:     []string{repoID, source, fmt.Sprintf("%d", release)},
+		Args:       encodeArgs(&RemoveSourceArgs{Repo: repoID, Source: source, Release: release}),
+		Repos:      []string{repoID},
 	}
 }
 
 // NewRemoveSourceJobHandler will create a job handler for the input job and ensure it validates
 func NewRemoveSourceJobHandler(j *JobEntry) (*RemoveSourceJobHandler, error) {
-	if len(j.Params) != 3 {
-		return nil, fmt.Errorf("job has invalid parameters")
-	}
-	rel, err := strconv.ParseInt(j.Params[2], 10, 32)
-	if err != nil {
+	args := &RemoveSourceArgs{}
+	if err := j.decodeArgs(args); err != nil {
 		return nil, err
 	}
 	return &RemoveSourceJobHandler{
-		repoID:  j.Params[0],
-		source:  j.Params[1],
-		release: int(rel),
+		repoID:  args.Repo,
+		source:  args.Source,
+		release: args.Release,
 	}, nil
 }
 
 // Execute will remove the source&rel match from the repo
-func (j *RemoveSourceJobHandler) Execute(_ *Processor, manager *core.Manager) error {
-	if err := manager.RemoveSource(j.repoID, j.source, j.release); err != nil {
+func (j *RemoveSourceJobHandler) Execute(ctx context.Context, _ *Processor, manager *core.Manager) error {
+	if err := manager.RemoveSource(ctx, j.repoID, j.source, j.release); err != nil {
 		return err
 	}
-	log.WithFields(log.Fields{
+	log.WithContext(ctx).WithFields(log.Fields{
 		"repo":          j.repoID,
 		"source":        j.source,
 		"releaseNumber": j.release,
@@ -0,0 +1,97 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getsolus/ferryd/src/ferryd/core"
+)
+
+// RepairIntegrityJobHandler is responsible for reconciling the pool with the
+// repositories, and should only ever be used in sequential queues.
+type RepairIntegrityJobHandler struct{}
+
+// NewRepairIntegrityJob will return a job suitable for adding to the job processor
+func NewRepairIntegrityJob() *JobEntry {
+	return &JobEntry{
+		sequential: true,
+		Type:       RepairIntegrity,
+	}
+}
+
+// NewRepairIntegrityJobHandler will create a job handler for the input job and ensure it validates
+func NewRepairIntegrityJobHandler(j *JobEntry) (*RepairIntegrityJobHandler, error) {
+	if len(j.Args) != 0 {
+		return nil, fmt.Errorf("job has invalid parameters")
+	}
+	return &RepairIntegrityJobHandler{}, nil
+}
+
+// Execute will repair the pool and repositories, logging every action taken.
+// The job fails if any problem could not be repaired automatically.
+func (j *RepairIntegrityJobHandler) Execute(ctx context.Context, _ *Processor, manager *core.Manager) error {
+	report, err := manager.RepairIntegrity(ctx)
+	if err != nil {
+		return err
+	}
+
+	actions := []struct {
+		items   []string
+		message string
+	}{
+		{report.RefCounts, "Corrected pool refcount"},
+		{report.Relinked, "Relinked repository file from pool"},
+		{report.RestoredPool, "Restored pool file from repository"},
+		{report.Purged, "Purged unreferenced pool entry"},
+		{report.Quarantined, "Quarantined orphaned pool file"},
+	}
+	for _, action := range actions {
+		for _, item := range action.items {
+			log.WithContext(ctx).WithFields(log.Fields{
+				"item": item,
+			}).Info(action.message)
+		}
+	}
+
+	for _, item := range report.Unrecoverable {
+		log.WithContext(ctx).WithFields(log.Fields{
+			"item": item,
+		}).Error("Unable to repair")
+	}
+
+	if len(report.Unrecoverable) > 0 {
+		return fmt.Errorf("integrity repair left %d problems unresolved", len(report.Unrecoverable))
+	}
+
+	log.WithContext(ctx).WithFields(log.Fields{
+		"refcounts":   len(report.RefCounts),
+		"relinked":    len(report.Relinked),
+		"restored":    len(report.RestoredPool),
+		"purged":      len(report.Purged),
+		"quarantined": len(report.Quarantined),
+	}).Info("Integrity repair complete")
+	return nil
+}
+
+// Describe returns a human readable description for this job
+func (j *RepairIntegrityJobHandler) Describe() string {
+	return "Repair pool and repositories"
+}
@@ -0,0 +1,81 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getsolus/ferryd/src/ferryd/core"
+)
+
+// ReplacePackageJobHandler is responsible for overriding the content of
+// existing pool entries, and should only ever be used in sequential queues.
+type ReplacePackageJobHandler struct {
+	packagePaths []string
+}
+
+// ReplacePackageArgs are the arguments of a ReplacePackage job
+type ReplacePackageArgs struct {
+	Packages []string `json:"packages"`
+}
+
+// NewReplacePackageJob will return a job suitable for adding to the job processor
+func NewReplacePackageJob(pkgs []string) *JobEntry {
+	return &JobEntry{
+		sequential: true,
+		Type:       ReplacePackage,
+		Args:       encodeArgs(&ReplacePackageArgs{Packages: pkgs}),
+	}
+}
+
+// NewReplacePackageJobHandler will create a job handler for the input job and ensure it validates
+func NewReplacePackageJobHandler(j *JobEntry) (*ReplacePackageJobHandler, error) {
+	args := &ReplacePackageArgs{}
+	if err := j.decodeArgs(args); err != nil {
+		return nil, err
+	}
+	if len(args.Packages) < 1 {
+		return nil, fmt.Errorf("job has invalid parameters")
+	}
+	return &ReplacePackageJobHandler{
+		packagePaths: args.Packages,
+	}, nil
+}
+
+// Execute will replace each pool entry in turn, relinking and reindexing
+// every repository that references it
+func (j *ReplacePackageJobHandler) Execute(ctx context.Context, _ *Processor, manager *core.Manager) error {
+	for _, pkg := range j.packagePaths {
+		repos, err := manager.ReplacePackage(ctx, pkg)
+		if err != nil {
+			return fmt.Errorf("failed to replace '%s': %w", pkg, err)
+		}
+		log.WithContext(ctx).WithFields(log.Fields{
+			"package": pkg,
+			"repos":   repos,
+		}).Warning("Replaced pool entry")
+	}
+	return nil
+}
+
+// Describe returns a human readable description for this job
+func (j *ReplacePackageJobHandler) Describe() string {
+	return fmt.Sprintf("Replace %v packages in the pool", len(j.packagePaths))
+}
@@ -0,0 +1,74 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package jobs
+
+import (
+	"errors"
+	"time"
+
+	"github.com/getsolus/ferryd/src/ferryd/core"
+)
+
+// A RetryPolicy allows failed jobs of a given type to be automatically retried,
+// waiting exponentially longer between each attempt
+type RetryPolicy struct {
+	Attempts int           // How many times to retry after the first failure
+	Delay    time.Duration // Wait before the first retry, doubled for each attempt
+	MaxDelay time.Duration // Upper limit on the wait between attempts
+}
+
+// retryPolicies are the job types that are retried automatically. These are
+// prone to transient failures, such as an upload still being written or a
+// failed CDN purge
+var retryPolicies = map[JobType]RetryPolicy{
+	TransitProcess: {Attempts: 5, Delay: 10 * time.Second, MaxDelay: 5 * time.Minute},
+	IndexRepo:      {Attempts: 3, Delay: 30 * time.Second, MaxDelay: 5 * time.Minute},
+}
+
+// permanentErrors will never go away by trying again
+var permanentErrors = []error{
+	core.ErrFrozen,
+	core.ErrPackageConflict,
+	core.ErrNotInstallable,
+}
+
+// delay returns how long to wait before the given retry attempt
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	delay := p.Delay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// retryDelay will report whether a failed job should be retried, and how long
+// to wait before doing so
+func (j *JobEntry) retryDelay() (time.Duration, bool) {
+	policy, ok := retryPolicies[j.Type]
+	if !ok || j.failure == nil || j.Attempt >= policy.Attempts {
+		return 0, false
+	}
+	for _, err := range permanentErrors {
+		if errors.Is(j.failure, err) {
+			return 0, false
+		}
+	}
+	return policy.delay(j.Attempt + 1), true
+}
@@ -0,0 +1,335 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package jobs
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getsolus/ferryd/src/libdb"
+	"github.com/getsolus/ferryd/src/libferry"
+)
+
+var (
+	// BucketSchedule holds when each scheduled job last ran and is next due,
+	// by name, so that runs missed while ferryd was down still happen
+	BucketSchedule = []byte("Schedule")
+
+	// scheduleCheck limits how long the scheduler sleeps, so that it notices
+	// the clock being changed
+	scheduleCheck = time.Hour
+)
+
+// A ScheduleEntry is a recurring job read from the schedule configuration:
+//
+//	[[job]]
+//	type = "TrimPackages"
+//	repo = "unstable"
+//	keep = 3
+//	schedule = "@daily at 03:00"
+type ScheduleEntry struct {
+	Name     string  `toml:"name"`     // Identifies the entry, defaulting to the type and repo
+	Schedule string  `toml:"schedule"` // When the job runs, see CronSchedule
+	Type     JobType `toml:"type"`
+	Repo     string  `toml:"repo"`
+	Keep     int     `toml:"keep"` // Releases kept by TrimPackages
+
+	cron *CronSchedule
+}
+
+// scheduleConfig is the layout of the schedule configuration file
+type scheduleConfig struct {
+	Job []*ScheduleEntry `toml:"job"`
+}
+
+// scheduleRecord is stored for each scheduled job
+type scheduleRecord struct {
+	Schedule string    // The schedule NextRun was worked out from
+	LastRun  time.Time // Zero if it has never run
+	LastJob  uint64    // The ID of the job pushed by the last run
+	NextRun  time.Time
+}
+
+// LoadSchedule will read the recurring jobs from the TOML file at path. When
+// the file doesn't exist, nothing is scheduled.
+func LoadSchedule(path string) ([]*ScheduleEntry, error) {
+	var config scheduleConfig
+	if _, err := toml.DecodeFile(path, &config); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	names := make(map[string]bool)
+	for _, e := range config.Job {
+		if err := e.init(); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		if names[e.Name] {
+			return nil, fmt.Errorf("%s: job '%s' is scheduled more than once", path, e.Name)
+		}
+		names[e.Name] = true
+	}
+	return config.Job, nil
+}
+
+// init will validate the entry and fill in its defaults
+func (e *ScheduleEntry) init() error {
+	if e.Name == "" {
+		e.Name = string(e.Type)
+		if e.Repo != "" {
+			e.Name += " " + e.Repo
+		}
+	}
+	if _, err := e.newJob(); err != nil {
+		return fmt.Errorf("job '%s': %v", e.Name, err)
+	}
+	cron, err := ParseCron(e.Schedule)
+	if err != nil {
+		return fmt.Errorf("job '%s': %v", e.Name, err)
+	}
+	e.cron = cron
+	return nil
+}
+
+// newJob will create a fresh job to push for the entry. Only maintenance jobs
+// may be scheduled.
+func (e *ScheduleEntry) newJob() (*JobEntry, error) {
+	if e.Repo == "" && e.Type != CheckIntegrity {
+		return nil, fmt.Errorf("%s requires a repo", e.Type)
+	}
+	switch e.Type {
+	case CheckIntegrity:
+		return NewCheckIntegrityJob(), nil
+	case CheckInstallable:
+		return NewCheckInstallableJob(e.Repo), nil
+	case DeltaRepo:
+		return NewDeltaRepoJob(e.Repo), nil
+	case IndexRepo:
+		return NewIndexRepoJob(e.Repo), nil
+	case TrimObsolete:
+		return NewTrimObsoleteJob(e.Repo), nil
+	case TrimPackages:
+		if e.Keep < 1 {
+			return nil, fmt.Errorf("%s requires keep to be at least 1", e.Type)
+		}
+		return NewTrimPackagesJob(e.Repo, e.Keep), nil
+	default:
+		return nil, fmt.Errorf("job type '%s' cannot be scheduled", e.Type)
+	}
+}
+
+// scheduleRecords will return the stored records of every scheduled job, by
+// name
+func (s *JobStore) scheduleRecords() (map[string]*scheduleRecord, error) {
+	s.modMut.Lock()
+	defer s.modMut.Unlock()
+
+	ret := make(map[string]*scheduleRecord)
+	bucket := s.db.Bucket(BucketSchedule)
+	err := bucket.ForEach(func(k, v []byte) error {
+		r := &scheduleRecord{}
+		if err := bucket.Decode(v, r); err != nil {
+			return err
+		}
+		ret[string(k)] = r
+		return nil
+	})
+	return ret, err
+}
+
+// saveSchedule will store the records of the scheduled jobs, forgetting any
+// job no longer scheduled
+func (s *JobStore) saveSchedule(records map[string]*scheduleRecord) error {
+	s.modMut.Lock()
+	defer s.modMut.Unlock()
+
+	var stale [][]byte
+	bucket := s.db.Bucket(BucketSchedule)
+	err := bucket.ForEach(func(k, v []byte) error {
+		if _, ok := records[string(k)]; !ok {
+			stale = append(stale, append([]byte(nil), k...))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(db libdb.Database) error {
+		bucket := db.Bucket(BucketSchedule)
+		for _, k := range stale {
+			if err := bucket.DeleteObject(k); err != nil {
+				return err
+			}
+		}
+		for name, r := range records {
+			if err := bucket.PutObject([]byte(name), r); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Schedule will replace the recurring jobs pushed by the processor. Jobs
+// which were due while ferryd wasn't running are pushed straight away, once.
+func (j *Processor) Schedule(entries []*ScheduleEntry) error {
+	j.scheduleMut.Lock()
+	defer j.scheduleMut.Unlock()
+
+	stored, err := j.store.scheduleRecords()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	records := make(map[string]*scheduleRecord)
+	for _, e := range entries {
+		r, ok := stored[e.Name]
+		if !ok || r.Schedule != e.cron.String() {
+			// New or changed, so start afresh
+			r = &scheduleRecord{Schedule: e.cron.String()}
+			if ok {
+				r.LastRun = stored[e.Name].LastRun
+				r.LastJob = stored[e.Name].LastJob
+			}
+			r.NextRun = e.cron.Next(now)
+		}
+		records[e.Name] = r
+	}
+
+	j.schedule = entries
+	j.scheduled = records
+	return j.runScheduleInternal(now)
+}
+
+// runSchedule is called by the timer to push every job that is due
+func (j *Processor) runSchedule() {
+	j.scheduleMut.Lock()
+	defer j.scheduleMut.Unlock()
+
+	if err := j.runScheduleInternal(time.Now()); err != nil {
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Error("Failed to store job schedule")
+	}
+}
+
+// runScheduleInternal will push the jobs due by now, store the schedule and
+// sleep until the next is due. The caller must hold scheduleMut.
+func (j *Processor) runScheduleInternal(now time.Time) error {
+	if j.scheduleTimer != nil {
+		j.scheduleTimer.Stop()
+		j.scheduleTimer = nil
+	}
+	if j.scheduled == nil {
+		// Stopped
+		return nil
+	}
+
+	wait := scheduleCheck
+	for _, e := range j.schedule {
+		r := j.scheduled[e.Name]
+		if r.NextRun.IsZero() {
+			continue
+		}
+		if !r.NextRun.After(now) {
+			j.pushScheduled(e, r, now)
+		}
+		if d := r.NextRun.Sub(now); d < wait {
+			wait = d
+		}
+	}
+
+	if len(j.schedule) > 0 {
+		j.scheduleTimer = time.AfterFunc(wait, j.runSchedule)
+	}
+	return j.store.saveSchedule(j.scheduled)
+}
+
+// pushScheduled will push the scheduled job and work out when it is next due
+func (j *Processor) pushScheduled(e *ScheduleEntry, r *scheduleRecord, now time.Time) {
+	fields := log.Fields{
+		"name":     e.Name,
+		"schedule": e.cron.String(),
+	}
+	if now.Sub(r.NextRun) > time.Minute {
+		fields["due"] = r.NextRun
+		log.WithFields(fields).Info("Running missed scheduled job")
+	}
+
+	job, _ := e.newJob()
+	id, err := j.PushJob(job)
+	if err != nil {
+		fields["error"] = err
+		log.WithFields(fields).Error("Failed to push scheduled job")
+	} else {
+		r.LastRun = now
+		r.LastJob = id
+	}
+	r.NextRun = e.cron.Next(now)
+}
+
+// stopSchedule will stop pushing scheduled jobs
+func (j *Processor) stopSchedule() {
+	j.scheduleMut.Lock()
+	defer j.scheduleMut.Unlock()
+
+	if j.scheduleTimer != nil {
+		j.scheduleTimer.Stop()
+		j.scheduleTimer = nil
+	}
+	j.schedule = nil
+	j.scheduled = nil
+}
+
+// ScheduledJobs will return the recurring jobs and when they are next due,
+// soonest first
+func (j *Processor) ScheduledJobs() []libferry.ScheduledJob {
+	j.scheduleMut.Lock()
+	defer j.scheduleMut.Unlock()
+
+	var ret []libferry.ScheduledJob
+	for _, e := range j.schedule {
+		r := j.scheduled[e.Name]
+		job, _ := e.newJob()
+		hnd, err := NewJobHandler(job)
+		if err != nil {
+			continue
+		}
+		ret = append(ret, libferry.ScheduledJob{
+			Name:        e.Name,
+			Type:        string(e.Type),
+			Description: hnd.Describe(),
+			Schedule:    e.cron.String(),
+			LastRun:     r.LastRun,
+			LastJob:     r.LastJob,
+			NextRun:     r.NextRun,
+		})
+	}
+	sort.SliceStable(ret, func(a, b int) bool {
+		return ret[a].NextRun.Before(ret[b].NextRun)
+	})
+	return ret
+}
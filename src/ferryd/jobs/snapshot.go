@@ -0,0 +1,167 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/getsolus/ferryd/src/ferryd/core"
+)
+
+// SnapshotArgs are the arguments of the snapshot jobs
+type SnapshotArgs struct {
+	Repo string `json:"repo"`
+	Name string `json:"name"`
+}
+
+// CreateSnapshotJobHandler is responsible for taking a named snapshot of a repository
+type CreateSnapshotJobHandler struct {
+	repoID string
+	name   string
+}
+
+// NewCreateSnapshotJob will return a job suitable for adding to the job processor
+func NewCreateSnapshotJob(repoID, name string) *JobEntry {
+	return &JobEntry{
+		sequential: true,
+		Type:       CreateSnapshot,
+		Args:       encodeArgs(&SnapshotArgs{Repo: repoID, Name: name}),
+		Repos:      []string{repoID},
+	}
+}
+
+// NewCreateSnapshotJobHandler will create a job handler for the input job and ensure it validates
+func NewCreateSnapshotJobHandler(j *JobEntry) (*CreateSnapshotJobHandler, error) {
+	args := &SnapshotArgs{}
+	if err := j.decodeArgs(args); err != nil {
+		return nil, err
+	}
+	return &CreateSnapshotJobHandler{
+		repoID: args.Repo,
+		name:   args.Name,
+	}, nil
+}
+
+// Execute will attempt to create the snapshot
+func (j *CreateSnapshotJobHandler) Execute(ctx context.Context, _ *Processor, manager *core.Manager) error {
+	if err := manager.CreateSnapshot(j.repoID, j.name); err != nil {
+		return err
+	}
+	log.WithContext(ctx).WithFields(log.Fields{
+		"repo":     j.repoID,
+		"snapshot": j.name,
+	}).Info("Created repository snapshot")
+	return nil
+}
+
+// Describe returns a human readable description for this job
+func (j *CreateSnapshotJobHandler) Describe() string {
+	return fmt.Sprintf("Create snapshot '%s' of repository '%s'", j.name, j.repoID)
+}
+
+// DeleteSnapshotJobHandler is responsible for removing a repository snapshot
+type DeleteSnapshotJobHandler struct {
+	repoID string
+	name   string
+}
+
+// NewDeleteSnapshotJob will return a job suitable for adding to the job processor
+func NewDeleteSnapshotJob(repoID, name string) *JobEntry {
+	return &JobEntry{
+		sequential: true,
+		Type:       DeleteSnapshot,
+		Args:       encodeArgs(&SnapshotArgs{Repo: repoID, Name: name}),
+		Repos:      []string{repoID},
+	}
+}
+
+// NewDeleteSnapshotJobHandler will create a job handler for the input job and ensure it validates
+func NewDeleteSnapshotJobHandler(j *JobEntry) (*DeleteSnapshotJobHandler, error) {
+	args := &SnapshotArgs{}
+	if err := j.decodeArgs(args); err != nil {
+		return nil, err
+	}
+	return &DeleteSnapshotJobHandler{
+		repoID: args.Repo,
+		name:   args.Name,
+	}, nil
+}
+
+// Execute will attempt to delete the snapshot
+func (j *DeleteSnapshotJobHandler) Execute(ctx context.Context, _ *Processor, manager *core.Manager) error {
+	if err := manager.DeleteSnapshot(ctx, j.repoID, j.name); err != nil {
+		return err
+	}
+	log.WithContext(ctx).WithFields(log.Fields{
+		"repo":     j.repoID,
+		"snapshot": j.name,
+	}).Info("Deleted repository snapshot")
+	return nil
+}
+
+// Describe returns a human readable description for this job
+func (j *DeleteSnapshotJobHandler) Describe() string {
+	return fmt.Sprintf("Delete snapshot '%s' of repository '%s'", j.name, j.repoID)
+}
+
+// RestoreSnapshotJobHandler is responsible for rolling a repository back to a snapshot
+type RestoreSnapshotJobHandler struct {
+	repoID string
+	name   string
+}
+
+// NewRestoreSnapshotJob will return a job suitable for adding to the job processor
+func NewRestoreSnapshotJob(repoID, name string) *JobEntry {
+	return &JobEntry{
+		sequential: true,
+		Type:       RestoreSnapshot,
+		Args:       encodeArgs(&SnapshotArgs{Repo: repoID, Name: name}),
+		Repos:      []string{repoID},
+	}
+}
+
+// NewRestoreSnapshotJobHandler will create a job handler for the input job and ensure it validates
+func NewRestoreSnapshotJobHandler(j *JobEntry) (*RestoreSnapshotJobHandler, error) {
+	args := &SnapshotArgs{}
+	if err := j.decodeArgs(args); err != nil {
+		return nil, err
+	}
+	return &RestoreSnapshotJobHandler{
+		repoID: args.Repo,
+		name:   args.Name,
+	}, nil
+}
+
+// Execute will attempt to restore the snapshot
+func (j *RestoreSnapshotJobHandler) Execute(ctx context.Context, _ *Processor, manager *core.Manager) error {
+	if err := manager.RestoreSnapshot(ctx, j.repoID, j.name); err != nil {
+		return err
+	}
+	log.WithContext(ctx).WithFields(log.Fields{
+		"repo":     j.repoID,
+		"snapshot": j.name,
+	}).Info("Restored repository snapshot")
+	return nil
+}
+
+// Describe returns a human readable description for this job
+func (j *RestoreSnapshotJobHandler) Describe() string {
+	return fmt.Sprintf("Restore snapshot '%s' of repository '%s'", j.name, j.repoID)
+}
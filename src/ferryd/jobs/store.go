@@ -17,6 +17,7 @@
 package jobs
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
 	"sync"
@@ -34,34 +35,65 @@ var (
 	// BucketSequentialJobs holds all sequential jobs
 	BucketSequentialJobs = []byte("Sync")
 
-	// BucketSuccessJobs contains jobs that have completed successfully
+	// BucketSuccessJobs contained jobs that have completed successfully, before
+	// the history was kept, and is only read to migrate older stores
 	BucketSuccessJobs = []byte("CompletedSuccess")
 
-	// BucketFailJobs contains jobs that completed with failure
+	// BucketFailJobs contained jobs that completed with failure, before the
+	// history was kept, and is only read to migrate older stores
 	BucketFailJobs = []byte("CompletedFailure")
 
+	// BucketSerial holds the record of the last job ID handed out
+	BucketSerial = []byte("Serial")
+
 	// ErrEmptyQueue is returned to indicate a job is not available yet
 	ErrEmptyQueue = errors.New("Queue is empty")
 
 	// ErrBreakLoop is used only to break the foreach internally.
 	ErrBreakLoop = errors.New("loop breaker")
 
-	// BucketRecord is used as a subbucket for records
+	// BucketRecord was a subbucket holding the write position of the ring
+	// buffers that completed jobs were kept in before the history
 	BucketRecord = []byte("Record")
 
+	// BucketRequeue is a subbucket of the history, holding what we need to
+	// push failed jobs again under the same key as the failure record
+	BucketRequeue = []byte("Requeue")
+
+	// BucketLogs is a subbucket of the history, holding the log messages of
+	// each job under the same key as the completion record
+	BucketLogs = []byte("Logs")
+
 	// IndexRecordKey is used in the job store to mark the next write location
 	IndexRecordKey = []byte("IndexRecord00")
+
+	// ErrUnknownJob is returned when a job ID is neither queued nor stored
+	ErrUnknownJob = errors.New("Unknown job")
+
+	// ErrJobRunning is returned when trying to cancel a job that has started
+	ErrJobRunning = errors.New("Job is already running")
+
+	// ErrJobCancelled is stored as the failure of cancelled jobs
+	ErrJobCancelled = errors.New("Job was cancelled")
+
+	// ErrNoRequeue is returned for failed jobs stored without their parameters
+	ErrNoRequeue = errors.New("Job cannot be requeued")
 )
 
 const (
-	// MaxJobsStored is the maximum amount of jobs we can store before rotating
+	// MaxJobsStored is how many of the most recent completed and failed jobs
+	// are reported in the status, the full history being available by query
 	MaxJobsStored = 100
 )
 
 // JobStore handles the storage and manipulation of incomplete jobs
 type JobStore struct {
-	db     libdb.Database
-	modMut *sync.Mutex
+	db      libdb.Database
+	modMut  *sync.Mutex
+	pending map[string][]byte // Keys of queued jobs that may be coalesced, by coalesceKey
+
+	retention Retention // How long the history is kept
+	pruned    time.Time // When the history was last pruned
 }
 
 // IndexRecord is just a simple helper to store the index record..
@@ -69,6 +101,17 @@ type IndexRecord struct {
 	Index uint64
 }
 
+// requeueRecord is stored alongside a failed job so that it can be requeued
+type requeueRecord struct {
+	Sequential bool
+	Job        *JobEntry
+}
+
+// logRecord is stored alongside a completed or failed job with its log
+type logRecord struct {
+	Entries []libferry.JobLogEntry
+}
+
 // NewStore creates a fully initialized JobStore and sets up Bolt Buckets as needed
 func NewStore(path string) (*JobStore, error) {
 	ctx, err := core.NewContext(path)
@@ -80,8 +123,11 @@ func NewStore(path string) (*JobStore, error) {
 	}
 
 	s := &JobStore{
-		db:     db,
-		modMut: &sync.Mutex{},
+		db:      db,
+		modMut:  &sync.Mutex{},
+		pending: make(map[string][]byte),
+
+		retention: DefaultRetention,
 	}
 
 	if err := s.setup(); err != nil {
@@ -102,10 +148,90 @@ func (s *JobStore) Close() {
 // setup is called during our early start to perform any relevant cleanup
 // and repairs from previous runs.
 func (s *JobStore) setup() error {
+	if err := s.seedSerial(); err != nil {
+		return err
+	}
+	if err := s.migrateHistory(); err != nil {
+		return err
+	}
 	if err := s.UnclaimSequential(); err != nil {
 		return err
 	}
-	return s.UnclaimAsync()
+	if err := s.UnclaimAsync(); err != nil {
+		return err
+	}
+	if err := s.migrateArgs(); err != nil {
+		return err
+	}
+	return s.loadPending()
+}
+
+// loadPending will find the queued jobs that new jobs may be coalesced into
+func (s *JobStore) loadPending() error {
+	s.modMut.Lock()
+	defer s.modMut.Unlock()
+
+	for _, bk := range [][]byte{BucketSequentialJobs, BucketAsyncJobs} {
+		bucket := s.db.Bucket(bk)
+		err := bucket.ForEach(func(id, value []byte) error {
+			j := &JobEntry{}
+			if err := bucket.Decode(value, j); err != nil {
+				return err
+			}
+			if key := j.pendingKey(); key != "" {
+				s.pending[key] = append([]byte(nil), id...)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropPending stops new jobs being coalesced into j, as it is no longer
+// queued. The caller must hold modMut.
+func (s *JobStore) dropPending(j *JobEntry) {
+	key := j.coalesceKey()
+	if key != "" && bytes.Equal(s.pending[key], j.id) {
+		delete(s.pending, key)
+	}
+}
+
+// seedSerial ensures the job serial starts beyond any job still queued from a
+// previous run, as older stores keyed their jobs per queue.
+func (s *JobStore) seedSerial() error {
+	s.modMut.Lock()
+	defer s.modMut.Unlock()
+
+	bucket := s.db.Bucket(BucketSerial)
+	has, err := bucket.HasObject(IndexRecordKey)
+	if err != nil || has {
+		return err
+	}
+
+	record := IndexRecord{}
+	for _, bk := range [][]byte{BucketSequentialJobs, BucketAsyncJobs} {
+		if next := binary.BigEndian.Uint64(s.db.Bucket(bk).NextSequence()); next > record.Index {
+			record.Index = next
+		}
+	}
+	return bucket.PutObject(IndexRecordKey, &record)
+}
+
+// nextID will hand out the next unique job ID. The caller must hold modMut.
+func (s *JobStore) nextID() (uint64, error) {
+	bucket := s.db.Bucket(BucketSerial)
+	record := IndexRecord{}
+	if err := bucket.GetObject(IndexRecordKey, &record); err != nil {
+		record.Index = 0
+	}
+	record.Index++
+	if err := bucket.PutObject(IndexRecordKey, &record); err != nil {
+		return 0, err
+	}
+	return record.Index, nil
 }
 
 // unclaimJobs will mark any previously claimed jobs as unclaimed again.
@@ -146,46 +272,108 @@ func (s *JobStore) UnclaimAsync() error {
 	return s.unclaimJobs([]byte(BucketAsyncJobs))
 }
 
+// repoSlots tracks the repositories held by sequential jobs, whether running
+// or queued ahead, so that each repository sees its jobs in order.
+type repoSlots struct {
+	all   bool // A job holding every repository is running or queued ahead
+	repos map[string]bool
+}
+
+// free reports whether none of the repositories used by j are held
+func (r *repoSlots) free(j *JobEntry) bool {
+	if r.all {
+		return false
+	}
+	if len(j.Repos) == 0 {
+		return len(r.repos) == 0
+	}
+	for _, id := range j.Repos {
+		if r.repos[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// take will hold the repositories used by j
+func (r *repoSlots) take(j *JobEntry) {
+	if len(j.Repos) == 0 {
+		r.all = true
+		return
+	}
+	for _, id := range j.Repos {
+		r.repos[id] = true
+	}
+}
+
 // claimJobInternal handles the similarity of the async/sync operations, grabbing
-// the first available job and stuffing it back in as a claimed job. Note that
-// in order to preserve order + sanity, we actually employ a mutex internally
-// to mutate the state of each job, and return them sequentially.
+// the highest priority available job and stuffing it back in as a claimed job.
+// Note that in order to preserve order + sanity, we actually employ a mutex
+// internally to mutate the state of each job, and return them sequentially.
 //
 // While more than one async job may be running at a time, we funnel job
 // claim/retire calls.
-func (s *JobStore) claimJobInternal(bucketID []byte) (*JobEntry, error) {
+//
+// When slots is set, a job is only available if none of its repositories are
+// used by a running job or by any job queued before it, so priority only
+// chooses between jobs whose repositories are free. Jobs waiting to be retried
+// keep hold of their repositories, while debounced jobs only hold them once
+// they are due.
+func (s *JobStore) claimJobInternal(bucketID []byte, slots *repoSlots) (*JobEntry, error) {
 	s.modMut.Lock()
 	defer s.modMut.Unlock()
 
 	var job *JobEntry
+	now := time.Now().UTC()
 
 	err := s.db.Update(func(db libdb.Database) error {
 		bucket := db.Bucket(bucketID)
 
-		// Attempt to find relevant job, break when we have it + id
+		var queued []*JobEntry
+
+		// Running jobs hold their repositories regardless of their position
 		err := bucket.ForEach(func(id, value []byte) error {
 			j := &JobEntry{}
 			if err := bucket.Decode(value, j); err != nil {
 				return err
 			}
-			if !j.Claimed {
-				j.Claimed = true
-
-				// Got the job so mark our begin time
-				j.Timing.Begin = time.Now().UTC()
-				// Got a usable job now.
-				job = j
-				job.id = make([]byte, len(id))
-				copy(job.id, id)
-				return ErrBreakLoop
+			if j.Claimed {
+				if slots != nil {
+					slots.take(j)
+				}
+				return nil
 			}
+			j.id = make([]byte, len(id))
+			copy(j.id, id)
+			queued = append(queued, j)
 			return nil
 		})
-
-		if err != ErrBreakLoop {
+		if err != nil {
 			return err
 		}
 
+		// Find the first job with the highest priority that is available
+		for _, j := range queued {
+			available := j.ready(now) && (slots == nil || slots.free(j))
+			if available && (job == nil || j.Priority > job.Priority) {
+				job = j
+			}
+			if slots != nil && (!j.debounced() || j.ready(now)) {
+				slots.take(j)
+			}
+		}
+
+		if job == nil {
+			return nil
+		}
+
+		job.Claimed = true
+		s.dropPending(job)
+
+		// Got the job so mark our begin time
+		job.Timing.Begin = time.Now().UTC()
+		job.ID = binary.BigEndian.Uint64(job.id)
+
 		// Serialise the new guy
 		return bucket.PutObject(job.id, job)
 	})
@@ -203,78 +391,106 @@ func (s *JobStore) claimJobInternal(bucketID []byte) (*JobEntry, error) {
 
 // ClaimAsyncJob gets the first available asynchronous job, if one exists
 func (s *JobStore) ClaimAsyncJob() (*JobEntry, error) {
-	return s.claimJobInternal([]byte(BucketAsyncJobs))
+	return s.claimJobInternal([]byte(BucketAsyncJobs), nil)
 }
 
-// ClaimSequentialJob gets the first available synchronous job, if one exists
+// ClaimSequentialJob gets the first available synchronous job, if one exists.
+// Jobs are run in order for each repository, but jobs for different
+// repositories may be claimed while others are still running.
 func (s *JobStore) ClaimSequentialJob() (*JobEntry, error) {
-	return s.claimJobInternal([]byte(BucketSequentialJobs))
+	return s.claimJobInternal([]byte(BucketSequentialJobs), &repoSlots{repos: make(map[string]bool)})
 }
 
-// Used to mark the completion of a job and store in the appropriate bucket
+// Used to mark the completion of a job and store it in the history. Failed
+// jobs from the sequential queue must set sequential so that they may be
+// requeued.
 func (s *JobStore) markCompletion(j *JobEntry) error {
-	var bucketID []byte
-	if j.failure != nil {
-		bucketID = BucketFailJobs
-	} else {
-		bucketID = BucketSuccessJobs
+	if j.Timing.End.IsZero() {
+		j.Timing.End = time.Now().UTC()
 	}
-
-	// We're already locked at this point so its safe to work out our next
-	// index
-	bucket := s.db.Bucket(bucketID).Bucket(BucketRecord)
-	record := IndexRecord{
-		Index: 0,
-	}
-
-	// Try to grab it, otherwise reset it
-	if err := bucket.GetObject(IndexRecordKey, &record); err != nil {
-		record.Index = 0
-	} else {
-		// Grabbed an existing record, so increment the key
-		record.Index++
-	}
-
-	// Wrap the index round if we hit too high
-	if record.Index >= MaxJobsStored {
-		record.Index = 0
+	storeJob := libferry.Job{
+		ID:          j.ID,
+		Type:        string(j.Type),
+		State:       libferry.JobCompleted,
+		Args:        j.Args,
+		Repos:       j.Repos,
+		Timing:      j.Timing,
+		Description: j.description,
+		Result:      j.result,
+		Attempt:     j.Attempt,
 	}
-
-	// Ensure we update the pointer
-	if err := bucket.PutObject(IndexRecordKey, &record); err != nil {
-		return err
+	if j.failure != nil {
+		storeJob.State = libferry.JobFailed
+		if j.failure == ErrJobCancelled {
+			storeJob.State = libferry.JobCancelled
+		}
+		storeJob.Error = j.failure.Error()
+		storeJob.Failed = true
 	}
 
-	// now stuff it into a new key object
-	nextID := make([]byte, 8)
-	binary.BigEndian.PutUint64(nextID, record.Index)
+	key := historyKey(j.ID)
+	err := s.db.Update(func(db libdb.Database) error {
+		bucket := db.Bucket(BucketHistory)
 
-	// We'll need to figure out how to truncate our buckets..
-	return s.db.Update(func(db libdb.Database) error {
-		bucket := db.Bucket(bucketID)
+		if err := bucket.PutObject(key, &storeJob); err != nil {
+			return err
+		}
 
-		storeJob := libferry.Job{
-			Timing:      j.Timing,
-			Description: j.description,
+		if len(j.log) > 0 {
+			if err := bucket.Bucket(BucketLogs).PutObject(key, &logRecord{Entries: j.log}); err != nil {
+				return err
+			}
 		}
 
-		// Mark relevant failure fields
-		if j.failure != nil {
-			storeJob.Error = j.failure.Error()
-			storeJob.Failed = true
+		if j.failure == nil {
+			return nil
 		}
 
-		return bucket.PutObject(nextID, &storeJob)
+		// Keep a clean copy around to requeue
+		requeue := &requeueRecord{
+			Sequential: j.sequential,
+			Job: &JobEntry{
+				Type:        j.Type,
+				Args:        j.Args,
+				ArgsVersion: j.ArgsVersion,
+				Params:      j.Params,
+				Repos:       j.Repos,
+			},
+		}
+		return bucket.Bucket(BucketRequeue).PutObject(key, requeue)
 	})
+	if err != nil {
+		return err
+	}
+
+	// Keep within the retention policy, without scanning on every job
+	if time.Since(s.pruned) < pruneInterval {
+		return nil
+	}
+	s.pruned = time.Now()
+	return s.pruneHistory()
 }
 
-// RetireAsyncJob removes a completed asynchronous job
-func (s *JobStore) RetireAsyncJob(j *JobEntry) error {
+// retireJobInternal removes a completed job from its queue. Failed jobs with
+// a retry policy are instead put back to wait for their next attempt.
+func (s *JobStore) retireJobInternal(j *JobEntry, bk []byte) error {
 	s.modMut.Lock()
 	defer s.modMut.Unlock()
 
+	j.sequential = bytes.Equal(bk, BucketSequentialJobs)
+
+	if delay, retry := j.retryDelay(); retry {
+		j.Attempt++
+		j.LastError = j.failure.Error()
+		j.NotBefore = time.Now().UTC().Add(delay)
+		j.Claimed = false
+		j.Timing.Begin = time.Time{}
+		j.Timing.End = time.Time{}
+		return s.db.Bucket(bk).PutObject(j.id, j)
+	}
+
 	err := s.db.Update(func(db libdb.Database) error {
-		return db.Bucket(BucketAsyncJobs).DeleteObject(j.id)
+		return db.Bucket(bk).DeleteObject(j.id)
 	})
 
 	if err != nil {
@@ -283,40 +499,200 @@ func (s *JobStore) RetireAsyncJob(j *JobEntry) error {
 	return s.markCompletion(j)
 }
 
+// RetireAsyncJob removes a completed asynchronous job
+func (s *JobStore) RetireAsyncJob(j *JobEntry) error {
+	return s.retireJobInternal(j, BucketAsyncJobs)
+}
+
 // RetireSequentialJob removes a completed synchronous job
 func (s *JobStore) RetireSequentialJob(j *JobEntry) error {
+	return s.retireJobInternal(j, BucketSequentialJobs)
+}
+
+// interruptJobInternal keeps the log of a job interrupted by shutdown with its
+// queued entry. The job stays claimed, so that it is run again on next start.
+func (s *JobStore) interruptJobInternal(j *JobEntry, bk []byte) error {
 	s.modMut.Lock()
 	defer s.modMut.Unlock()
 
-	err := s.db.Update(func(db libdb.Database) error {
-		return db.Bucket(BucketSequentialJobs).DeleteObject(j.id)
+	j.Log = j.log
+	return s.db.Update(func(db libdb.Database) error {
+		return db.Bucket(bk).PutObject(j.id, j)
 	})
+}
 
-	if err != nil {
-		return err
+// InterruptAsyncJob keeps an asynchronous job interrupted by shutdown
+func (s *JobStore) InterruptAsyncJob(j *JobEntry) error {
+	return s.interruptJobInternal(j, BucketAsyncJobs)
+}
+
+// InterruptSequentialJob keeps a synchronous job interrupted by shutdown
+func (s *JobStore) InterruptSequentialJob(j *JobEntry) error {
+	return s.interruptJobInternal(j, BucketSequentialJobs)
+}
+
+// CancelJob will remove a job from the queue before it has been claimed, and
+// store it as cancelled
+func (s *JobStore) CancelJob(id uint64) error {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, id)
+
+	s.modMut.Lock()
+	defer s.modMut.Unlock()
+
+	for _, bk := range [][]byte{BucketSequentialJobs, BucketAsyncJobs} {
+		j := &JobEntry{}
+		if err := s.db.Bucket(bk).GetObject(key, j); err != nil {
+			continue
+		}
+		if j.Claimed {
+			return ErrJobRunning
+		}
+		if err := s.db.Bucket(bk).DeleteObject(key); err != nil {
+			return err
+		}
+
+		j.id = key
+		j.ID = id
+		s.dropPending(j)
+		j.sequential = bytes.Equal(bk, BucketSequentialJobs)
+		j.failure = ErrJobCancelled
+		j.Timing.End = time.Now().UTC()
+		if hnd, err := NewJobHandler(j); err == nil {
+			j.description = hnd.Describe()
+		}
+		return s.markCompletion(j)
 	}
-	return s.markCompletion(j)
+
+	return ErrUnknownJob
+}
+
+// FailedJob will return a fresh copy of a failed or cancelled job, ready to
+// be pushed again
+func (s *JobStore) FailedJob(id uint64) (*JobEntry, error) {
+	s.modMut.Lock()
+	defer s.modMut.Unlock()
+
+	bucket := s.db.Bucket(BucketHistory)
+	key := historyKey(id)
+
+	j := &libferry.Job{}
+	if err := bucket.GetObject(key, j); err != nil || !j.Failed {
+		return nil, ErrUnknownJob
+	}
+
+	requeue := &requeueRecord{}
+	if err := bucket.Bucket(BucketRequeue).GetObject(key, requeue); err != nil {
+		return nil, ErrNoRequeue
+	}
+	requeue.Job.sequential = requeue.Sequential
+	return requeue.Job, nil
 }
 
 // pushJobInternal is identical between sync and async jobs, it
 // just needs to know which bucket to store the job in.
+//
+// If an equivalent job is still queued the new job is merged into it instead,
+// and takes on the ID of the queued job. When a job using the same repository
+// was queued in between, the queued job is dropped and the merged job is
+// queued in its place at the back, so that it still runs after that job.
 func (s *JobStore) pushJobInternal(j *JobEntry, bk []byte) error {
 	// Prep the job prior to insertion
 	j.Timing.Queued = time.Now().UTC()
 	j.Claimed = false
-
-	j.id = s.db.Bucket(bk).NextSequence()
+	if j.Priority == PriorityNormal {
+		j.Priority = defaultPriority(j.Type)
+	}
+	if err := j.upgradeArgs(); err != nil {
+		return err
+	}
 
 	s.modMut.Lock()
 	defer s.modMut.Unlock()
 
-	return s.db.Update(func(db libdb.Database) error {
-		bucket := db.Bucket(bk)
-		// Use next natural sequence in the bucket
+	key := j.coalesceKey()
+	if key != "" && s.pending[key] != nil {
+		merged, err := s.coalesceInternal(j, bk, s.pending[key])
+		if merged || err != nil {
+			return err
+		}
+	}
+
+	id, err := s.nextID()
+	if err != nil {
+		return err
+	}
+	j.ID = id
+	j.id = make([]byte, 8)
+	binary.BigEndian.PutUint64(j.id, id)
 
-		j.id = bucket.NextSequence()
-		return bucket.PutObject(j.id, j)
+	err = s.db.Update(func(db libdb.Database) error {
+		return db.Bucket(bk).PutObject(j.id, j)
 	})
+	if err != nil {
+		return err
+	}
+	if key != "" {
+		s.pending[key] = j.id
+	}
+	return nil
+}
+
+// coalesceInternal will attempt to merge j into the pending job stored under
+// id, reporting whether it did so. The caller must hold modMut.
+func (s *JobStore) coalesceInternal(j *JobEntry, bk, id []byte) (bool, error) {
+	bucket := s.db.Bucket(bk)
+	pending := &JobEntry{}
+	if err := bucket.GetObject(id, pending); err != nil || pending.pendingKey() != j.coalesceKey() {
+		return false, nil
+	}
+	between, err := s.queuedBetween(bucket, id, j)
+	if err != nil {
+		return false, err
+	}
+	pending.coalesce(j)
+
+	// Merging would run j ahead of the jobs in between, so the merged job
+	// takes the new position instead
+	if between {
+		if err := bucket.DeleteObject(id); err != nil {
+			return false, err
+		}
+		j.Type = pending.Type
+		j.Priority = pending.Priority
+		j.NotBefore = pending.NotBefore
+		j.Timing.Queued = pending.Timing.Queued
+		return false, nil
+	}
+
+	if err := bucket.PutObject(id, pending); err != nil {
+		return false, err
+	}
+	j.ID = binary.BigEndian.Uint64(id)
+	j.id = id
+	j.Type = pending.Type
+	j.Priority = pending.Priority
+	j.NotBefore = pending.NotBefore
+	j.Timing.Queued = pending.Timing.Queued
+	return true, nil
+}
+
+// queuedBetween reports whether any job sharing a repository with j has been
+// queued after the job stored under id. The caller must hold modMut.
+func (s *JobStore) queuedBetween(bucket libdb.Database, id []byte, j *JobEntry) (bool, error) {
+	between := false
+	err := bucket.ForEach(func(k, value []byte) error {
+		if between || bytes.Compare(k, id) <= 0 {
+			return nil
+		}
+		o := &JobEntry{}
+		if err := bucket.Decode(value, o); err != nil {
+			return err
+		}
+		between = o.sharesRepo(j)
+		return nil
+	})
+	return between, err
 }
 
 // PushSequentialJob will enqueue a new sequential job
@@ -357,91 +733,97 @@ func (s *JobStore) cloneCurrentJobs(ret *[]*libferry.Job, bucketID []byte) error
 				return err
 			}
 
+			j.ID = binary.BigEndian.Uint64(k)
+
 			// Now stuff the job into the ret
 			hnd, err := NewJobHandler(j)
 			if err != nil {
 				return err
 			}
 
-			r := &libferry.Job{
-				Description: hnd.Describe(),
-				Timing:      j.Timing,
-			}
-			*ret = append(*ret, r)
+			*ret = append(*ret, j.status(hnd.Describe()))
 
 			return nil
 		})
 	})
 }
 
-// CompletedJobs will return all successfully completed jobs still stored
-func (s *JobStore) CompletedJobs() ([]*libferry.Job, error) {
-	var ret []*libferry.Job
-	if err := s.clonePastJobs(&ret, BucketSuccessJobs); err != nil {
-		return nil, err
+// GetJob will return the current state of the job with the given ID, whether
+// it is still queued, running, or has been stored after completion.
+func (s *JobStore) GetJob(id uint64) (*libferry.Job, error) {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, id)
+
+	s.modMut.Lock()
+	defer s.modMut.Unlock()
+
+	// Still in one of the queues?
+	for _, bk := range [][]byte{BucketSequentialJobs, BucketAsyncJobs} {
+		j := &JobEntry{}
+		if err := s.db.Bucket(bk).GetObject(key, j); err != nil {
+			continue
+		}
+		j.ID = id
+		hnd, err := NewJobHandler(j)
+		if err != nil {
+			return nil, err
+		}
+		return j.status(hnd.Describe()), nil
 	}
-	return ret, nil
-}
 
-// FailedJobs will return all failed jobs that are still stored
-func (s *JobStore) FailedJobs() ([]*libferry.Job, error) {
-	var ret []*libferry.Job
-	if err := s.clonePastJobs(&ret, BucketFailJobs); err != nil {
-		return nil, err
+	// Look through the history
+	ret := &libferry.Job{}
+	if err := s.db.Bucket(BucketHistory).GetObject(key, ret); err != nil {
+		return nil, ErrUnknownJob
 	}
 	return ret, nil
 }
 
-// clonePastJobs will pull the libferry.Job references from the DB and return
-// clones
-func (s *JobStore) clonePastJobs(ret *[]*libferry.Job, bucketID []byte) error {
+// JobLog will return the log messages stored for a completed or failed job
+func (s *JobStore) JobLog(id uint64) ([]libferry.JobLogEntry, error) {
 	s.modMut.Lock()
 	defer s.modMut.Unlock()
 
-	return s.db.Bucket(bucketID).View(func(db libdb.ReadOnlyView) error {
-		return db.ForEach(func(k, v []byte) error {
-			j := &libferry.Job{}
-			if err := db.Decode(v, j); err != nil {
-				return err
-			}
-			*ret = append(*ret, j)
+	bucket := s.db.Bucket(BucketHistory)
+	key := historyKey(id)
+	if has, err := bucket.HasObject(key); err != nil || !has {
+		return nil, ErrUnknownJob
+	}
 
-			return nil
-		})
-	})
+	record := &logRecord{}
+	if err := bucket.Bucket(BucketLogs).GetObject(key, record); err != nil {
+		// Nothing was logged
+		return nil, nil
+	}
+	return record.Entries, nil
 }
 
-// resetInternal is a blocking call to nuke all records from a bucket, as well
-// as the special sub-bucket index key
-func (s *JobStore) resetInternal(bucketID []byte) error {
+// CompletedJobs will return the most recent successfully completed jobs
+func (s *JobStore) CompletedJobs() ([]*libferry.Job, error) {
 	s.modMut.Lock()
 	defer s.modMut.Unlock()
 
-	bucket := s.db.Bucket(bucketID).Bucket(BucketRecord)
-	hasIndex, err := bucket.HasObject(IndexRecordKey)
-	if err != nil {
-		return err
-	}
-	if hasIndex {
-		if err := bucket.DeleteObject(IndexRecordKey); err != nil {
-			return err
-		}
-	}
+	return s.historyInternal(func(j *libferry.Job) bool {
+		return !j.Failed
+	}, MaxJobsStored)
+}
 
-	// batch delete the jobs
-	return s.db.Bucket(bucketID).Update(func(db libdb.Database) error {
-		return db.ForEach(func(k, v []byte) error {
-			return db.DeleteObject(k)
-		})
-	})
+// FailedJobs will return the most recent failed or cancelled jobs
+func (s *JobStore) FailedJobs() ([]*libferry.Job, error) {
+	s.modMut.Lock()
+	defer s.modMut.Unlock()
+
+	return s.historyInternal(func(j *libferry.Job) bool {
+		return j.Failed
+	}, MaxJobsStored)
 }
 
-// ResetCompleted will remove all completion records from our store and reset the pointer
+// ResetCompleted will remove all successfully completed jobs from the history
 func (s *JobStore) ResetCompleted() error {
-	return s.resetInternal(BucketSuccessJobs)
+	return s.resetHistory(libferry.JobCompleted)
 }
 
-// ResetFailed will remove all fail records from our store and reset the pointer
+// ResetFailed will remove all failed or cancelled jobs from the history
 func (s *JobStore) ResetFailed() error {
-	return s.resetInternal(BucketFailJobs)
+	return s.resetHistory(libferry.JobFailed, libferry.JobCancelled)
 }
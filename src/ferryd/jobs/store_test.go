@@ -0,0 +1,681 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package jobs
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/getsolus/ferryd/src/ferryd/core"
+	"github.com/getsolus/ferryd/src/libdb"
+	"github.com/getsolus/ferryd/src/libferry"
+)
+
+// initTestStore will open a job store in a fresh directory
+func initTestStore(t *testing.T) *JobStore {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create job store: %v", err)
+	}
+	t.Cleanup(store.Close)
+	return store
+}
+
+// claimTestJob will claim the next sequential job, expecting it to be want,
+// or for nothing to be available when want is nil
+func claimTestJob(t *testing.T, store *JobStore, want *JobEntry) *JobEntry {
+	t.Helper()
+	job, err := store.ClaimSequentialJob()
+	if want == nil {
+		if err != ErrEmptyQueue {
+			t.Fatalf("Expected no available job, got %v (%v)", job, err)
+		}
+		return nil
+	}
+	if err != nil {
+		t.Fatalf("Failed to claim job %d: %v", want.ID, err)
+	}
+	if job.ID != want.ID {
+		t.Fatalf("Claimed job %d (%v), expected %d (%v)", job.ID, job.Repos, want.ID, want.Repos)
+	}
+	return job
+}
+
+func TestJobIDs(t *testing.T) {
+	store := initTestStore(t)
+
+	seq := NewIndexRepoJob("unstable")
+	async := NewDeltaJob("unstable", "nano")
+	if err := store.PushSequentialJob(seq); err != nil {
+		t.Fatalf("Failed to push job: %v", err)
+	}
+	if err := store.PushAsyncJob(async); err != nil {
+		t.Fatalf("Failed to push job: %v", err)
+	}
+	if seq.ID == 0 || seq.ID == async.ID {
+		t.Fatalf("Jobs in different queues must have unique IDs: %d, %d", seq.ID, async.ID)
+	}
+
+	job, err := store.GetJob(seq.ID)
+	if err != nil {
+		t.Fatalf("Failed to get job: %v", err)
+	}
+	if job.State != "queued" {
+		t.Fatalf("Expected queued job, got %s", job.State)
+	}
+
+	claimed := claimTestJob(t, store, seq)
+	claimed.result = []byte(`["nano"]`)
+	if err := store.RetireSequentialJob(claimed); err != nil {
+		t.Fatalf("Failed to retire job: %v", err)
+	}
+	if job, err = store.GetJob(seq.ID); err != nil {
+		t.Fatalf("Failed to get completed job: %v", err)
+	}
+	if job.State != "completed" || string(job.Result) != `["nano"]` {
+		t.Fatalf("Unexpected completed job: %+v", job)
+	}
+
+	// IDs are never reused, even once the queue empties
+	next := NewIndexRepoJob("unstable")
+	if err := store.PushSequentialJob(next); err != nil {
+		t.Fatalf("Failed to push job: %v", err)
+	}
+	if next.ID <= async.ID {
+		t.Fatalf("Job ID %d was reused", next.ID)
+	}
+
+	if _, err := store.GetJob(next.ID + 1); err != ErrUnknownJob {
+		t.Fatalf("Expected unknown job, got %v", err)
+	}
+}
+
+func TestSequentialPerRepo(t *testing.T) {
+	store := initTestStore(t)
+
+	indexA := NewIndexRepoJob("a")
+	trimA := NewTrimObsoleteJob("a")
+	indexB := NewIndexRepoJob("b")
+	pull := NewPullRepoJob("b", "c", nil)
+	indexC := NewIndexRepoJob("c")
+	integrity := NewCheckIntegrityJob()
+	integrity.Priority = PriorityHigh
+	indexD := NewIndexRepoJob("d")
+	for _, j := range []*JobEntry{indexA, trimA, indexB, pull, indexC, integrity, indexD} {
+		if err := store.PushSequentialJob(j); err != nil {
+			t.Fatalf("Failed to push job: %v", err)
+		}
+	}
+
+	// Different repos run in parallel, but each repo keeps its order whatever
+	// the priority of the jobs queued on it
+	runA := claimTestJob(t, store, indexA)
+	runB := claimTestJob(t, store, indexB)
+	claimTestJob(t, store, nil)
+
+	if err := store.RetireSequentialJob(runA); err != nil {
+		t.Fatalf("Failed to retire job: %v", err)
+	}
+	runA = claimTestJob(t, store, trimA)
+
+	// The pull needs both b and c, and holds c against the later index
+	if err := store.RetireSequentialJob(runB); err != nil {
+		t.Fatalf("Failed to retire job: %v", err)
+	}
+	runPull := claimTestJob(t, store, pull)
+	claimTestJob(t, store, nil)
+	if err := store.RetireSequentialJob(runPull); err != nil {
+		t.Fatalf("Failed to retire job: %v", err)
+	}
+	runC := claimTestJob(t, store, indexC)
+
+	// Jobs without repos wait for everything ahead, and block everything after
+	claimTestJob(t, store, nil)
+	for _, j := range []*JobEntry{runA, runC} {
+		if err := store.RetireSequentialJob(j); err != nil {
+			t.Fatalf("Failed to retire job: %v", err)
+		}
+	}
+	runIntegrity := claimTestJob(t, store, integrity)
+	claimTestJob(t, store, nil)
+	if err := store.RetireSequentialJob(runIntegrity); err != nil {
+		t.Fatalf("Failed to retire job: %v", err)
+	}
+	claimTestJob(t, store, indexD)
+}
+
+func TestPriority(t *testing.T) {
+	store := initTestStore(t)
+
+	trim := NewTrimPackagesJob("unstable", 3)
+	create := NewCreateRepoJob("unstable")
+	snapshot := NewCreateSnapshotJob("unstable", "before")
+	index := NewIndexRepoJob("unstable")
+	trimOther := NewTrimObsoleteJob("other")
+	indexThird := NewIndexRepoJob("third")
+	transit := NewTransitJob("other.tram")
+	for _, j := range []*JobEntry{trim, create, snapshot, index, trimOther, indexThird} {
+		if err := store.PushSequentialJob(j); err != nil {
+			t.Fatalf("Failed to push job: %v", err)
+		}
+	}
+	if trim.Priority != PriorityLow || create.Priority != PriorityNormal || index.Priority != PriorityHigh {
+		t.Fatalf("Unexpected default priorities: %v, %v, %v", trim.Priority, create.Priority, index.Priority)
+	}
+
+	// Higher priorities overtake jobs on other repositories, equal priorities keep order
+	claimTestJob(t, store, indexThird)
+	runTrim := claimTestJob(t, store, trim)
+	claimTestJob(t, store, trimOther)
+
+	// Nothing overtakes a job queued or running on the same repository
+	claimTestJob(t, store, nil)
+	if err := store.RetireSequentialJob(runTrim); err != nil {
+		t.Fatalf("Failed to retire job: %v", err)
+	}
+	runCreate := claimTestJob(t, store, create)
+	claimTestJob(t, store, nil)
+	if err := store.RetireSequentialJob(runCreate); err != nil {
+		t.Fatalf("Failed to retire job: %v", err)
+	}
+	runSnapshot := claimTestJob(t, store, snapshot)
+	if err := store.RetireSequentialJob(runSnapshot); err != nil {
+		t.Fatalf("Failed to retire job: %v", err)
+	}
+	runIndex := claimTestJob(t, store, index)
+
+	transit.Repos = []string{"unstable"}
+	if err := store.PushSequentialJob(transit); err != nil {
+		t.Fatalf("Failed to push job: %v", err)
+	}
+	claimTestJob(t, store, nil)
+	if err := store.RetireSequentialJob(runIndex); err != nil {
+		t.Fatalf("Failed to retire job: %v", err)
+	}
+	claimTestJob(t, store, transit)
+
+	// The async queue is ordered by priority alone
+	delta := NewDeltaJob("unstable", "nano")
+	deltaIndex := NewDeltaIndexJob("unstable", "nano")
+	deltaIndex.Priority = PriorityHigh
+	for _, j := range []*JobEntry{delta, deltaIndex} {
+		if err := store.PushAsyncJob(j); err != nil {
+			t.Fatalf("Failed to push job: %v", err)
+		}
+	}
+	job, err := store.ClaimAsyncJob()
+	if err != nil || job.ID != deltaIndex.ID {
+		t.Fatalf("Expected job %d, got %v (%v)", deltaIndex.ID, job, err)
+	}
+}
+
+func TestCancelRequeue(t *testing.T) {
+	store := initTestStore(t)
+
+	running := NewIndexRepoJob("unstable")
+	queued := NewTrimPackagesJob("unstable", 3)
+	for _, j := range []*JobEntry{running, queued} {
+		if err := store.PushSequentialJob(j); err != nil {
+			t.Fatalf("Failed to push job: %v", err)
+		}
+	}
+	claimTestJob(t, store, running)
+
+	if err := store.CancelJob(running.ID); err != ErrJobRunning {
+		t.Fatalf("Expected running job to not cancel, got %v", err)
+	}
+	if err := store.CancelJob(queued.ID); err != nil {
+		t.Fatalf("Failed to cancel job: %v", err)
+	}
+	job, err := store.GetJob(queued.ID)
+	if err != nil {
+		t.Fatalf("Failed to get cancelled job: %v", err)
+	}
+	if job.State != "cancelled" || !job.Finished() {
+		t.Fatalf("Unexpected cancelled job: %+v", job)
+	}
+
+	requeued, err := store.FailedJob(queued.ID)
+	if err != nil {
+		t.Fatalf("Failed to get cancelled job for requeue: %v", err)
+	}
+	args := &TrimPackagesArgs{}
+	if err := requeued.decodeArgs(args); err != nil {
+		t.Fatalf("Failed to decode requeued job arguments: %v", err)
+	}
+	if !requeued.sequential || requeued.Type != TrimPackages || args.Repo != "unstable" || args.Keep != 3 {
+		t.Fatalf("Requeued job doesn't match: %+v", requeued)
+	}
+	if err := store.PushSequentialJob(requeued); err != nil {
+		t.Fatalf("Failed to push requeued job: %v", err)
+	}
+	if requeued.ID == queued.ID {
+		t.Fatalf("Requeued job must have a new ID")
+	}
+
+	if err := store.ResetFailed(); err != nil {
+		t.Fatalf("Failed to reset failed jobs: %v", err)
+	}
+	if _, err := store.FailedJob(queued.ID); err != ErrUnknownJob {
+		t.Fatalf("Expected unknown job after reset, got %v", err)
+	}
+}
+
+// failTestJob will claim and fail the next sequential job
+func failTestJob(t *testing.T, store *JobStore, want *JobEntry, failure error) {
+	t.Helper()
+	job := claimTestJob(t, store, want)
+	job.failure = failure
+	if err := store.RetireSequentialJob(job); err != nil {
+		t.Fatalf("Failed to retire job: %v", err)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	store := initTestStore(t)
+
+	policy := retryPolicies[IndexRepo]
+	defer func() { retryPolicies[IndexRepo] = policy }()
+	retryPolicies[IndexRepo] = RetryPolicy{Attempts: 1}
+
+	index := NewIndexRepoJob("unstable")
+	if err := store.PushSequentialJob(index); err != nil {
+		t.Fatalf("Failed to push job: %v", err)
+	}
+
+	// First failure goes straight back into the queue under the same ID
+	failTestJob(t, store, index, errors.New("purge failed"))
+	job, err := store.GetJob(index.ID)
+	if err != nil {
+		t.Fatalf("Failed to get job: %v", err)
+	}
+	if job.State != "queued" || job.Attempt != 1 || job.Error != "purge failed" {
+		t.Fatalf("Unexpected retried job: %+v", job)
+	}
+
+	// Out of attempts now
+	failTestJob(t, store, index, errors.New("purge failed again"))
+	if job, err = store.GetJob(index.ID); err != nil {
+		t.Fatalf("Failed to get job: %v", err)
+	}
+	if job.State != "failed" || job.Attempt != 1 {
+		t.Fatalf("Unexpected failed job: %+v", job)
+	}
+
+	// Permanent failures are never retried
+	frozen := NewIndexRepoJob("unstable")
+	if err := store.PushSequentialJob(frozen); err != nil {
+		t.Fatalf("Failed to push job: %v", err)
+	}
+	failTestJob(t, store, frozen, fmt.Errorf("index: %w", core.ErrFrozen))
+	if job, err = store.GetJob(frozen.ID); err != nil {
+		t.Fatalf("Failed to get job: %v", err)
+	}
+	if job.State != "failed" {
+		t.Fatalf("Permanent failure was retried: %+v", job)
+	}
+
+	// Jobs waiting on a retry hold their repository
+	retryPolicies[IndexRepo] = RetryPolicy{Attempts: 1, Delay: time.Hour, MaxDelay: time.Hour}
+	waiting := NewIndexRepoJob("unstable")
+	behind := NewTrimObsoleteJob("unstable")
+	other := NewTrimObsoleteJob("stable")
+	for _, j := range []*JobEntry{waiting, behind, other} {
+		if err := store.PushSequentialJob(j); err != nil {
+			t.Fatalf("Failed to push job: %v", err)
+		}
+	}
+	failTestJob(t, store, waiting, errors.New("locked"))
+	claimTestJob(t, store, other)
+	claimTestJob(t, store, nil)
+}
+
+func TestRetryDelay(t *testing.T) {
+	policy := RetryPolicy{Attempts: 10, Delay: time.Second, MaxDelay: 10 * time.Second}
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second} {
+		if got := policy.delay(attempt + 1); got != want {
+			t.Fatalf("Attempt %d: expected delay %v, got %v", attempt+1, want, got)
+		}
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	store := initTestStore(t)
+
+	push := func(j *JobEntry) uint64 {
+		t.Helper()
+		var err error
+		if j.sequential {
+			err = store.PushSequentialJob(j)
+		} else {
+			err = store.PushAsyncJob(j)
+		}
+		if err != nil {
+			t.Fatalf("Failed to push job: %v", err)
+		}
+		return j.ID
+	}
+
+	// Pending index jobs for the same repo are merged
+	index := push(NewIndexRepoJob("unstable"))
+	if id := push(NewIndexRepoJob("unstable")); id != index {
+		t.Fatalf("Expected job %d to be reused, got %d", index, id)
+	}
+	other := push(NewIndexRepoJob("other"))
+	if other == index {
+		t.Fatalf("Jobs for different repos must not be merged")
+	}
+
+	// Once claimed a new job is needed to pick up further changes
+	job := NewIndexRepoJob("unstable")
+	job.ID = index
+	claimTestJob(t, store, job)
+	if id := push(NewIndexRepoJob("unstable")); id == index {
+		t.Fatalf("Job %d was merged into a running job", id)
+	}
+
+	// Delta jobs are merged, and will reindex if any of them asked to
+	delta := push(NewDeltaJob("unstable", "nano"))
+	if id := push(NewDeltaIndexJob("unstable", "nano")); id != delta {
+		t.Fatalf("Expected job %d to be reused, got %d", delta, id)
+	}
+	if id := push(NewDeltaJob("unstable", "vim")); id == delta {
+		t.Fatalf("Jobs for different packages must not be merged")
+	}
+	status, err := store.GetJob(delta)
+	if err != nil {
+		t.Fatalf("Failed to get job: %v", err)
+	}
+	if status.Type != DeltaIndex {
+		t.Fatalf("Expected merged job to reindex, got %s", status.Type)
+	}
+
+	// Cancelled jobs can't be merged into
+	if err := store.CancelJob(other); err != nil {
+		t.Fatalf("Failed to cancel job: %v", err)
+	}
+	if id := push(NewIndexRepoJob("other")); id == other {
+		t.Fatalf("Job %d was merged into a cancelled job", id)
+	}
+}
+
+func TestCoalesceInterleaved(t *testing.T) {
+	store := initTestStore(t)
+
+	index := NewIndexRepoJob("a")
+	copySource := NewCopySourceJob("b", "a", "nano", 12, true)
+	for _, j := range []*JobEntry{index, copySource} {
+		if err := store.PushSequentialJob(j); err != nil {
+			t.Fatalf("Failed to push job: %v", err)
+		}
+	}
+
+	// The copy needs indexing afterwards, so the merged job moves behind it
+	reindex := NewIndexRepoJob("a")
+	if err := store.PushSequentialJob(reindex); err != nil {
+		t.Fatalf("Failed to push job: %v", err)
+	}
+	if reindex.ID <= copySource.ID {
+		t.Fatalf("Expected job %d to be queued after %d", reindex.ID, copySource.ID)
+	}
+	if _, err := store.GetJob(index.ID); err != ErrUnknownJob {
+		t.Fatalf("Expected job %d to be dropped, got %v", index.ID, err)
+	}
+
+	runCopy := claimTestJob(t, store, copySource)
+	claimTestJob(t, store, nil)
+	if err := store.RetireSequentialJob(runCopy); err != nil {
+		t.Fatalf("Failed to retire job: %v", err)
+	}
+	claimTestJob(t, store, reindex)
+
+	// Jobs on other repositories in between don't stop the merge
+	pending := NewIndexRepoJob("a")
+	merged := NewIndexRepoJob("a")
+	for _, j := range []*JobEntry{pending, NewIndexRepoJob("b"), merged} {
+		if err := store.PushSequentialJob(j); err != nil {
+			t.Fatalf("Failed to push job: %v", err)
+		}
+	}
+	if merged.ID != pending.ID {
+		t.Fatalf("Expected job %d to be reused, got %d", pending.ID, merged.ID)
+	}
+}
+
+func TestDebounce(t *testing.T) {
+	store := initTestStore(t)
+
+	index := NewIndexRepoJob("a")
+	index.NotBefore = time.Now().UTC().Add(time.Hour)
+	create := NewCreateRepoJob("a")
+	for _, j := range []*JobEntry{index, create} {
+		if err := store.PushSequentialJob(j); err != nil {
+			t.Fatalf("Failed to push job: %v", err)
+		}
+	}
+
+	// Debounced jobs don't hold their repositories until they are due
+	runCreate := claimTestJob(t, store, create)
+
+	// Further changes push the job back, but only so far
+	later := NewIndexRepoJob("a")
+	later.NotBefore = time.Now().UTC().Add(2 * time.Hour)
+	if err := store.PushSequentialJob(later); err != nil {
+		t.Fatalf("Failed to push job: %v", err)
+	}
+	if later.ID <= create.ID {
+		t.Fatalf("Expected job %d to be queued after %d", later.ID, create.ID)
+	}
+	if limit := index.Timing.Queued.Add(indexMaxDelay); !later.NotBefore.Equal(limit) {
+		t.Fatalf("Expected job to wait until %v, got %v", limit, later.NotBefore)
+	}
+	if err := store.RetireSequentialJob(runCreate); err != nil {
+		t.Fatalf("Failed to retire job: %v", err)
+	}
+	claimTestJob(t, store, nil)
+
+	// A job wanted now runs straight away
+	now := NewIndexRepoJob("a")
+	if err := store.PushSequentialJob(now); err != nil {
+		t.Fatalf("Failed to push job: %v", err)
+	}
+	if now.ID != later.ID || !now.NotBefore.IsZero() {
+		t.Fatalf("Expected job %d to be reused and due, got %d (%v)", later.ID, now.ID, now.NotBefore)
+	}
+	claimTestJob(t, store, now)
+}
+
+// finishTestJob will claim and complete the next sequential job, as though it
+// finished at the given time
+func finishTestJob(t *testing.T, store *JobStore, want *JobEntry, end time.Time) {
+	t.Helper()
+	job := claimTestJob(t, store, want)
+	job.Timing.End = end
+	if err := store.RetireSequentialJob(job); err != nil {
+		t.Fatalf("Failed to retire job: %v", err)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	store := initTestStore(t)
+	now := time.Now().UTC()
+
+	indexA := NewIndexRepoJob("a")
+	trimA := NewTrimObsoleteJob("a")
+	indexB := NewIndexRepoJob("b")
+	for _, j := range []*JobEntry{indexA, trimA, indexB} {
+		if err := store.PushSequentialJob(j); err != nil {
+			t.Fatalf("Failed to push job: %v", err)
+		}
+	}
+	finishTestJob(t, store, indexA, now.Add(-2*time.Hour))
+	finishTestJob(t, store, indexB, now.Add(-time.Hour))
+	failTestJob(t, store, trimA, errors.New("no space left"))
+
+	check := func(q *libferry.HistoryRequest, want ...*JobEntry) {
+		t.Helper()
+		js, err := store.History(q)
+		if err != nil {
+			t.Fatalf("Failed to query history: %v", err)
+		}
+		if len(js) != len(want) {
+			t.Fatalf("Expected %d jobs for %+v, got %d", len(want), q, len(js))
+		}
+		for i, j := range js {
+			if j.ID != want[i].ID {
+				t.Fatalf("Expected job %d at %d for %+v, got %d", want[i].ID, i, q, j.ID)
+			}
+		}
+	}
+	check(&libferry.HistoryRequest{}, indexB, trimA, indexA)
+	check(&libferry.HistoryRequest{Limit: 1}, indexB)
+	check(&libferry.HistoryRequest{Type: string(IndexRepo)}, indexB, indexA)
+	check(&libferry.HistoryRequest{Repo: "a"}, trimA, indexA)
+	check(&libferry.HistoryRequest{State: libferry.JobFailed}, trimA)
+	check(&libferry.HistoryRequest{Since: now.Add(-90 * time.Minute)}, indexB, trimA)
+	check(&libferry.HistoryRequest{Until: now.Add(-time.Hour)}, indexA)
+
+	job, err := store.GetJob(trimA.ID)
+	if err != nil {
+		t.Fatalf("Failed to get job: %v", err)
+	}
+	args := &RepoArgs{}
+	if err := json.Unmarshal(job.Args, args); err != nil || job.Type != string(TrimObsolete) || args.Repo != "a" {
+		t.Fatalf("Job history must keep the type and arguments: %+v", job)
+	}
+
+	// Only the failed job goes on a failed reset
+	if err := store.ResetFailed(); err != nil {
+		t.Fatalf("Failed to reset failed jobs: %v", err)
+	}
+	check(&libferry.HistoryRequest{}, indexB, indexA)
+
+	// Age and count both limit the history
+	if err := store.SetRetention(Retention{MaxAge: 90 * time.Minute}); err != nil {
+		t.Fatalf("Failed to set retention: %v", err)
+	}
+	check(&libferry.HistoryRequest{}, indexB)
+	for i := 0; i < 3; i++ {
+		j := NewIndexRepoJob("c")
+		if err := store.PushSequentialJob(j); err != nil {
+			t.Fatalf("Failed to push job: %v", err)
+		}
+		finishTestJob(t, store, j, now)
+	}
+	if err := store.SetRetention(Retention{MaxCount: 2}); err != nil {
+		t.Fatalf("Failed to set retention: %v", err)
+	}
+	js, err := store.History(&libferry.HistoryRequest{})
+	if err != nil {
+		t.Fatalf("Failed to query history: %v", err)
+	}
+	if len(js) != 2 || js[0].Repos[0] != "c" || js[1].Repos[0] != "c" {
+		t.Fatalf("Expected the 2 newest jobs to be kept, got %+v", js)
+	}
+	if _, err := store.GetJob(indexB.ID); err != ErrUnknownJob {
+		t.Fatalf("Expected pruned job to be unknown, got %v", err)
+	}
+}
+
+func TestArgsMigration(t *testing.T) {
+	store := initTestStore(t)
+
+	// Entries queued by versions of ferryd using positional parameters
+	legacy := map[uint64]*JobEntry{
+		1: {Type: IndexRepo, Params: []string{"unstable"}, Repos: []string{"unstable"}},
+		2: {Type: TrimPackages, Params: []string{"unstable", "3"}, Repos: []string{"unstable"}},
+	}
+	err := store.db.Update(func(db libdb.Database) error {
+		for id, j := range legacy {
+			j.ID = id
+			j.id = make([]byte, 8)
+			binary.BigEndian.PutUint64(j.id, id)
+			if err := db.Bucket(BucketSequentialJobs).PutObject(j.id, j); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to store legacy jobs: %v", err)
+	}
+	if err := store.migrateArgs(); err != nil {
+		t.Fatalf("Failed to migrate job arguments: %v", err)
+	}
+	if err := store.loadPending(); err != nil {
+		t.Fatalf("Failed to load pending jobs: %v", err)
+	}
+
+	// The upgraded entry must be equivalent to a new one
+	index := NewIndexRepoJob("unstable")
+	if err := store.PushSequentialJob(index); err != nil {
+		t.Fatalf("Failed to push job: %v", err)
+	}
+	if index.ID != 1 {
+		t.Fatalf("Expected new job to coalesce into the migrated one, got ID %d", index.ID)
+	}
+
+	finishTestJob(t, store, &JobEntry{ID: 1}, time.Now().UTC())
+	job := claimTestJob(t, store, &JobEntry{ID: 2})
+	if job.Params != nil || job.ArgsVersion != 1 {
+		t.Fatalf("Job arguments weren't migrated: %+v", job)
+	}
+	handler, err := NewTrimPackagesJobHandler(job)
+	if err != nil {
+		t.Fatalf("Failed to create handler for migrated job: %v", err)
+	}
+	if handler.repoID != "unstable" || handler.maxKeep != 3 {
+		t.Fatalf("Migrated job arguments don't match: %+v", handler)
+	}
+
+	// Bump the version of the transit arguments, renaming the path
+	argsVersions[TransitProcess] = 2
+	argsUpgrades[TransitProcess] = map[int]argsUpgrade{
+		1: func(args json.RawMessage) (json.RawMessage, error) {
+			old := struct {
+				File string `json:"file"`
+			}{}
+			if err := json.Unmarshal(args, &old); err != nil {
+				return nil, err
+			}
+			return encodeArgs(&TransitArgs{Path: old.File}), nil
+		},
+	}
+	defer func() {
+		delete(argsVersions, TransitProcess)
+		delete(argsUpgrades, TransitProcess)
+	}()
+
+	transit := &JobEntry{Type: TransitProcess, Args: json.RawMessage(`{"file":"nano.tram"}`), ArgsVersion: 1}
+	args := &TransitArgs{}
+	if err := transit.decodeArgs(args); err != nil {
+		t.Fatalf("Failed to upgrade job arguments: %v", err)
+	}
+	if args.Path != "nano.tram" || transit.ArgsVersion != 2 {
+		t.Fatalf("Upgraded job arguments don't match: %+v (version %d)", args, transit.ArgsVersion)
+	}
+
+	// Arguments from a newer ferryd can't be understood
+	transit.ArgsVersion = 3
+	if err := transit.decodeArgs(args); err == nil {
+		t.Fatalf("Expected arguments of a newer version to fail")
+	}
+}
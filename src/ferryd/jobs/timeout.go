@@ -0,0 +1,50 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package jobs
+
+import (
+	"errors"
+	"time"
+)
+
+// DefaultTimeout is how long a job may run if its type has no timeout of its own
+const DefaultTimeout = 2 * time.Hour
+
+// ErrJobTimeout is stored as the failure of jobs that ran out of time
+var ErrJobTimeout = errors.New("Job timed out")
+
+// timeouts limit how long each job type may run before it is cancelled. They
+// are generous, only being meant to free workers stuck on a hung subprocess
+// or network request.
+var timeouts = map[JobType]time.Duration{
+	CreateRepo:      10 * time.Minute,
+	Delta:           30 * time.Minute,
+	DeltaIndex:      30 * time.Minute,
+	DeltaRepo:       10 * time.Minute,
+	IndexRepo:       15 * time.Minute,
+	TransitProcess:  30 * time.Minute,
+	CheckIntegrity:  6 * time.Hour,
+	RepairIntegrity: 6 * time.Hour,
+}
+
+// timeout returns how long the job may run for
+func (j *JobEntry) timeout() time.Duration {
+	if timeout, ok := timeouts[j.Type]; ok {
+		return timeout
+	}
+	return DefaultTimeout
+}
@@ -17,6 +17,8 @@
 package jobs
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -32,27 +34,40 @@ type TransitJobHandler struct {
 	manifest *core.TransitManifest
 }
 
-// NewTransitJob will return a job suitable for adding to the job processor
+// TransitArgs are the arguments of a TransitProcess job
+type TransitArgs struct {
+	Path string `json:"path"` // The uploaded .tram file
+}
+
+// NewTransitJob will return a job suitable for adding to the job processor.
+// The manifest is peeked at to find the target repository, and an unreadable
+// manifest simply holds every repository until the job fails.
 func NewTransitJob(path string) *JobEntry {
+	var repos []string
+	if tram, err := core.NewTransitManifest(path); err == nil {
+		repos = []string{tram.Manifest.Target}
+	}
 	return &JobEntry{
 		sequential: true,
 		Type:       TransitProcess,
-		Params:     []string{path},
+		Args:       encodeArgs(&TransitArgs{Path: path}),
+		Repos:      repos,
 	}
 }
 
 // NewTransitJobHandler will create a job handler for the input job and ensure it validates
 func NewTransitJobHandler(j *JobEntry) (*TransitJobHandler, error) {
-	if len(j.Params) != 1 {
-		return nil, fmt.Errorf("job has invalid parameters")
+	args := &TransitArgs{}
+	if err := j.decodeArgs(args); err != nil {
+		return nil, err
 	}
 	return &TransitJobHandler{
-		path: j.Params[0],
+		path: args.Path,
 	}, nil
 }
 
 // Execute will process incoming .tram files for potential repo inclusion
-func (j *TransitJobHandler) Execute(jproc *Processor, manager *core.Manager) error {
+func (j *TransitJobHandler) Execute(ctx context.Context, jproc *Processor, manager *core.Manager) error {
 	tram, err := core.NewTransitManifest(j.path)
 	if err != nil {
 		return err
@@ -72,11 +87,14 @@ func (j *TransitJobHandler) Execute(jproc *Processor, manager *core.Manager) err
 
 	// Now try to merge into the repo
 	pkgs := tram.GetPaths()
-	if err = manager.AddPackages(repo, pkgs, true); err != nil {
+	if err = manager.AddPackages(ctx, repo, pkgs, true); err != nil {
+		if errors.Is(err, core.ErrPackageConflict) {
+			return fmt.Errorf("rejected upload '%s' for target '%s': %w", j.manifest.ID(), repo, err)
+		}
 		return err
 	}
 
-	log.WithFields(log.Fields{
+	log.WithContext(ctx).WithFields(log.Fields{
 		"target": repo,
 		"id":     j.manifest.ID(),
 	}).Info("Successfully processed manifest upload")
@@ -89,7 +107,7 @@ func (j *TransitJobHandler) Execute(jproc *Processor, manager *core.Manager) err
 			continue
 		}
 		if err := os.Remove(p); err != nil {
-			log.WithFields(log.Fields{
+			log.WithContext(ctx).WithFields(log.Fields{
 				"file":  p,
 				"id":    j.manifest.ID(),
 				"error": err,
@@ -17,6 +17,7 @@
 package jobs
 
 import (
+	"context"
 	"fmt"
 
 	log "github.com/sirupsen/logrus"
@@ -35,26 +36,28 @@ func NewTrimObsoleteJob(id string) *JobEntry {
 	return &JobEntry{
 		sequential: true,
 		Type:       TrimObsolete,
-		Params:     []string{id},
+		Args:       encodeArgs(&RepoArgs{Repo: id}),
+		Repos:      []string{id},
 	}
 }
 
 // NewTrimObsoleteJobHandler will create a job handler for the input job and ensure it validates
 func NewTrimObsoleteJobHandler(j *JobEntry) (*TrimObsoleteJobHandler, error) {
-	if len(j.Params) != 1 {
-		return nil, fmt.Errorf("job has invalid parameters")
+	args := &RepoArgs{}
+	if err := j.decodeArgs(args); err != nil {
+		return nil, err
 	}
 	return &TrimObsoleteJobHandler{
-		repoID: j.Params[0],
+		repoID: args.Repo,
 	}, nil
 }
 
 // Execute will try to remove any excessive packages marked as Obsolete
-func (j *TrimObsoleteJobHandler) Execute(_ *Processor, manager *core.Manager) error {
-	if err := manager.TrimObsolete(j.repoID); err != nil {
+func (j *TrimObsoleteJobHandler) Execute(ctx context.Context, _ *Processor, manager *core.Manager) error {
+	if err := manager.TrimObsolete(ctx, j.repoID); err != nil {
 		return err
 	}
-	log.WithFields(log.Fields{"repo": j.repoID}).Info("Trimmed obsoletes in repository")
+	log.WithContext(ctx).WithFields(log.Fields{"repo": j.repoID}).Info("Trimmed obsoletes in repository")
 	return nil
 }
 
@@ -17,8 +17,8 @@
 package jobs
 
 import (
+	"context"
 	"fmt"
-	"strconv"
 
 	log "github.com/sirupsen/logrus"
 
@@ -31,36 +31,40 @@ type TrimPackagesJobHandler struct {
 	maxKeep int
 }
 
+// TrimPackagesArgs are the arguments of a TrimPackages job
+type TrimPackagesArgs struct {
+	Repo string `json:"repo"`
+	Keep int    `json:"keep"` // How many releases of each source to keep
+}
+
 // NewTrimPackagesJob will return a job suitable for adding to the job processor
 func NewTrimPackagesJob(repoID string, maxKeep int) *JobEntry {
 	return &JobEntry{
 		sequential: true,
 		Type:       TrimPackages,
-		Params:     []string{repoID, fmt.Sprintf("%d", maxKeep)},
+		Args:       encodeArgs(&TrimPackagesArgs{Repo: repoID, Keep: maxKeep}),
+		Repos:      []string{repoID},
 	}
 }
 
 // NewTrimPackagesJobHandler will create a job handler for the input job and ensure it validates
 func NewTrimPackagesJobHandler(j *JobEntry) (*TrimPackagesJobHandler, error) {
-	if len(j.Params) != 2 {
-		return nil, fmt.Errorf("job has invalid parameters")
-	}
-	keep, err := strconv.ParseInt(j.Params[1], 10, 32)
-	if err != nil {
+	args := &TrimPackagesArgs{}
+	if err := j.decodeArgs(args); err != nil {
 		return nil, err
 	}
 	return &TrimPackagesJobHandler{
-		repoID:  j.Params[0],
-		maxKeep: int(keep),
+		repoID:  args.Repo,
+		maxKeep: args.Keep,
 	}, nil
 }
 
 // Execute will attempt removal of excessive packages in the index
-func (j *TrimPackagesJobHandler) Execute(_ *Processor, manager *core.Manager) error {
-	if err := manager.TrimPackages(j.repoID, j.maxKeep); err != nil {
+func (j *TrimPackagesJobHandler) Execute(ctx context.Context, _ *Processor, manager *core.Manager) error {
+	if err := manager.TrimPackages(ctx, j.repoID, j.maxKeep); err != nil {
 		return err
 	}
-	log.WithFields(log.Fields{
+	log.WithContext(ctx).WithFields(log.Fields{
 		"repo":    j.repoID,
 		"maxKeep": j.maxKeep,
 	}).Info("Trimmed packages in repository")
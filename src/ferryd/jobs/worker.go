@@ -17,6 +17,9 @@
 package jobs
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"math/rand"
 	"sync"
 	"time"
@@ -32,7 +35,9 @@ type JobFetcher func() (*JobEntry, error)
 // JobReaper will be provided by either the Async or Sequential retire functions
 type JobReaper func(j *JobEntry) error
 
-// MinWait is the minimum amount of time between retries for a worker
+// MinWait is the minimum amount of time between retries for a worker. Workers
+// are woken directly when jobs are pushed, so this is only a fallback for jobs
+// recovered from a previous run or pushed around the Processor.
 const MinWait = time.Second * 2
 
 // MaxJitter sets the upper limit on the random jitter used for retry times
@@ -43,14 +48,16 @@ const MaxJitter int64 = 512
 type Worker struct {
 	sequential bool
 	exit       chan int
+	wake       <-chan struct{} // Signalled when a job is pushed for us
 	timer      *time.Timer
 	wg         *sync.WaitGroup
 	manager    *core.Manager
 	store      *JobStore
 	processor  *Processor
 
-	fetcher JobFetcher // Fetch a new job
-	reaper  JobReaper  // Purge an old job
+	fetcher     JobFetcher // Fetch a new job
+	reaper      JobReaper  // Purge an old job
+	interrupter JobReaper  // Keep a job interrupted by shutdown
 }
 
 // newWorker is an internal method to initialise a worker for usage
@@ -66,7 +73,7 @@ func newWorker(processor *Processor, sequential bool) *Worker {
 		sequential: sequential,
 		wg:         processor.wg,
 		exit:       make(chan int, 1),
-		timer:      nil, // Init this when we start up
+		timer:      time.NewTimer(MinWait), // Created now so Stop can't race Start
 		manager:    processor.manager,
 		store:      processor.store,
 		processor:  processor,
@@ -76,9 +83,13 @@ func newWorker(processor *Processor, sequential bool) *Worker {
 	if sequential {
 		w.fetcher = w.store.ClaimSequentialJob
 		w.reaper = w.store.RetireSequentialJob
+		w.interrupter = w.store.InterruptSequentialJob
+		w.wake = processor.syncWake
 	} else {
 		w.fetcher = w.store.ClaimAsyncJob
 		w.reaper = w.store.RetireAsyncJob
+		w.interrupter = w.store.InterruptAsyncJob
+		w.wake = processor.asyncWake
 	}
 
 	return w
@@ -99,17 +110,16 @@ func NewWorkerSequential(processor *Processor) *Worker {
 // Stop will demand that all new requests are no longer processed
 func (w *Worker) Stop() {
 	w.exit <- 1
-	if w.timer != nil {
-		w.timer.Stop()
-	}
+	w.timer.Stop()
 }
 
-// Start will begin the main execution of this worker, and will continuously
-// poll for new jobs with an increasing increment (with a ceiling limit)
+// Start will begin the main execution of this worker. The worker sleeps until
+// it is woken by a pushed job, or the fallback timer fires, and then processes
+// jobs until the queue is empty.
 func (w *Worker) Start() {
 	defer w.wg.Done()
 
-	// Let's get our timer initialised
+	// Arm the fallback timer
 	w.setTime()
 
 	for {
@@ -118,43 +128,92 @@ func (w *Worker) Start() {
 			// Bail now, we've been told to go home
 			return
 
+		case <-w.wake:
+			if !w.drain() {
+				return
+			}
+
 		case <-w.timer.C:
-			// Try to grab a job
-			job, err := w.fetcher()
-
-			// Report the error
-			if err != nil {
-				if err != ErrEmptyQueue {
-					log.WithFields(log.Fields{
-						"error": err,
-						"async": !w.sequential,
-					}).Error("Failed to grab a work queue item")
-				}
-				w.setTime()
-				continue
+			if !w.drain() {
+				return
 			}
+		}
 
-			// Got a job, now process it
-			w.processJob(job)
+		// Back to sleep, so we must reset the timeout period
+		w.setTime()
+	}
+}
 
-			// Now we mark end time so we can calculate how long it took
-			job.Timing.End = time.Now().UTC()
+// drain will keep claiming and processing jobs until the queue is empty, so
+// that jobs pushed while we were busy don't wait on the timer. It returns
+// false if the worker was stopped in the meantime.
+func (w *Worker) drain() bool {
+	for {
+		select {
+		case <-w.exit:
+			return false
+		default:
+		}
+
+		// Leave the queue alone while paused, we're woken on resume
+		if w.processor.paused(w.sequential) {
+			return true
+		}
+
+		// Try to grab a job
+		job, err := w.fetcher()
 
-			// Mark the job as dealt with
-			err = w.reaper(job)
+		// Report the error
+		if err != nil {
+			if err != ErrEmptyQueue {
+				log.WithFields(log.Fields{
+					"error": err,
+					"async": !w.sequential,
+				}).Error("Failed to grab a work queue item")
+			}
+			return true
+		}
 
-			// Report failure in retiring the job
-			if err != nil {
+		// Got a job, now process it. If we're shutting down the job stays
+		// claimed so that it is run again on the next start, and keeps what
+		// it logged so far.
+		if !w.processJob(job) {
+			w.processor.endActivity(job, false)
+			if err := w.interrupter(job); err != nil {
 				log.WithFields(log.Fields{
 					"error": err,
 					"id":    job.GetID(),
 					"type":  job.Type,
 					"async": !w.sequential,
-				}).Error("Error in retiring job")
+				}).Error("Error in keeping interrupted job")
 			}
+			return false
+		}
+
+		// Now we mark end time so we can calculate how long it took
+		job.Timing.End = time.Now().UTC()
+
+		// Keep the log with the completion record
+		_, retry := job.retryDelay()
+		w.processor.endActivity(job, retry)
+
+		// Mark the job as dealt with
+		err = w.reaper(job)
 
-			// We had a job, so we must reset the timeout period
-			w.setTime()
+		// Report failure in retiring the job
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+				"id":    job.GetID(),
+				"type":  job.Type,
+				"async": !w.sequential,
+			}).Error("Error in retiring job")
+		}
+
+		// Our repositories are free again, so jobs queued behind us may
+		// be ready for another worker
+		if w.sequential {
+			w.processor.wake(w.processor.syncWake)
 		}
 	}
 }
@@ -162,16 +221,21 @@ func (w *Worker) Start() {
 // setTime will update the timer resetting it to MinWait + some random jitter to help with contention
 func (w *Worker) setTime() {
 	delay := MinWait + (time.Millisecond * time.Duration(rand.Int63n(MaxJitter)))
-	if w.timer == nil {
-		w.timer = time.NewTimer(delay)
-	} else {
-		w.timer.Reset(delay)
-	}
+	w.timer.Reset(delay)
 }
 
 // processJob will actually examine the given job and figure out how
-// to execute it. Each Worker can only execute a single job at a time
-func (w *Worker) processJob(job *JobEntry) {
+// to execute it. Each Worker can only execute a single job at a time.
+// It returns false if the job was interrupted by the Processor closing.
+func (w *Worker) processJob(job *JobEntry) bool {
+	timeout := job.timeout()
+	ctx, cancel := context.WithTimeout(w.processor.ctx, timeout)
+	defer cancel()
+
+	// Capture progress and logging for this job alone
+	ctx = w.processor.startActivity(ctx, job)
+	logger := log.WithContext(ctx)
+
 	handler, err := NewJobHandler(job)
 
 	fields := log.Fields{
@@ -183,8 +247,8 @@ func (w *Worker) processJob(job *JobEntry) {
 	if err != nil {
 		fields["error"] = err
 		job.failure = err
-		log.WithFields(fields).Error("No known job handler, cannot continue with job")
-		return
+		logger.WithFields(fields).Error("No known job handler, cannot continue with job")
+		return true
 	}
 
 	// Safely have a handler now
@@ -192,13 +256,38 @@ func (w *Worker) processJob(job *JobEntry) {
 	fields["description"] = job.description
 
 	// Try to execute it, report the error
-	if err := handler.Execute(w.processor, w.manager); err != nil {
+	if err := handler.Execute(ctx, w.processor, w.manager); err != nil {
+		if w.processor.ctx.Err() != nil {
+			fields["error"] = err
+			logger.WithFields(fields).Warning("Job interrupted by shutdown")
+			return false
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("%w after %v: %v", ErrJobTimeout, timeout, err)
+		}
 		fields["error"] = err
 		job.failure = err
-		log.WithFields(fields).Error("Job failed with error")
-		return
+		if delay, retry := job.retryDelay(); retry {
+			fields["attempt"] = job.Attempt + 1
+			fields["retryIn"] = delay
+			logger.WithFields(fields).Warning("Job failed, will retry")
+			return true
+		}
+		logger.WithFields(fields).Error("Job failed with error")
+		return true
+	}
+
+	// Keep hold of the result for the completion record
+	if rh, ok := handler.(ResultHandler); ok {
+		result, err := json.Marshal(rh.Result())
+		if err != nil {
+			logger.WithFields(fields).WithError(err).Warning("Failed to encode job result")
+		} else {
+			job.result = result
+		}
 	}
 
 	// Succeeded
-	log.WithFields(fields).Info("Job completed successfully")
+	logger.WithFields(fields).Info("Job completed successfully")
+	return true
 }
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
@@ -39,6 +40,18 @@ var (
 
 	// How many jobs we're allowed to use. By default, half of the system cores (xz -T 2)
 	backgroundJobCount = -1
+
+	// How many repositories may have sequential jobs running at once
+	repoJobCount = 4
+
+	// Where recurring jobs are configured
+	schedulePath = filepath.Join(core.FerrydDir, "schedule.toml")
+
+	// How long the history of finished jobs is kept, zero being forever
+	historyAge = 90 * 24 * time.Hour
+
+	// How many finished jobs the history keeps, zero being unlimited
+	historyCount = 50000
 )
 
 const (
@@ -50,6 +63,10 @@ func mainLoop() {
 	pflag.StringVarP(&baseDir, "base", "d", "/var/lib/ferryd", "Set the base directory for ferryd")
 	pflag.StringVarP(&socketPath, "socket", "s", "/run/ferryd.sock", "Set the socket path for ferryd")
 	pflag.IntVarP(&backgroundJobCount, "jobs", "j", -1, "Number of jobs to use (-1 is 50% of cores)")
+	pflag.IntVarP(&repoJobCount, "repo-jobs", "r", 4, "Number of repositories to run sequential jobs for in parallel")
+	pflag.StringVar(&schedulePath, "schedule", schedulePath, "Set the configuration file of recurring jobs")
+	pflag.DurationVar(&historyAge, "history-age", historyAge, "How long to keep finished jobs in the history (0 keeps them forever)")
+	pflag.IntVar(&historyCount, "history-count", historyCount, "How many finished jobs to keep in the history (0 is unlimited)")
 	pflag.Parse()
 
 	// We write to a logfile..
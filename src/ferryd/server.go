@@ -88,38 +88,87 @@ func NewServer() (*Server, error) {
 		return nil, err
 	}
 
-	// Set up the API bits
+	// Set up the API bits. Handlers making changes are wrapped by mutating,
+	// so that they are refused while in maintenance
 	router.GET("/api/v1/status", s.GetStatus)
+	router.GET("/api/v1/job/:id", s.GetJob)
+	router.GET("/api/v1/job/:id/log", s.GetJobLog)
+	router.POST("/api/v1/history", s.History)
 
 	// Repo management
-	router.GET("/api/v1/create/repo/:id", s.CreateRepo)
-	router.GET("/api/v1/remove/repo/:id", s.DeleteRepo)
-	router.GET("/api/v1/delta/repo/:id", s.DeltaRepo)
-	router.GET("/api/v1/index/repo/:id", s.IndexRepo)
+	router.GET("/api/v1/create/repo/:id", s.mutating(s.CreateRepo))
+	router.GET("/api/v1/remove/repo/:id", s.mutating(s.DeleteRepo))
+	router.GET("/api/v1/delta/repo/:id", s.mutating(s.DeltaRepo))
+	router.GET("/api/v1/index/repo/:id", s.mutating(s.IndexRepo))
 
 	// Client sends us data
-	router.POST("/api/v1/import/:id", s.ImportPackages)
-	router.POST("/api/v1/clone/:id", s.CloneRepo)
-	router.POST("/api/v1/copy/source/:id", s.CopySource)
-	router.POST("/api/v1/pull/:id", s.PullRepo)
+	router.POST("/api/v1/import/:id", s.mutating(s.ImportPackages))
+	router.POST("/api/v1/replace/package", s.mutating(s.ReplacePackages))
+	router.POST("/api/v1/clone/:id", s.mutating(s.CloneRepo))
+	router.POST("/api/v1/copy/source/:id", s.mutating(s.CopySource))
+	router.POST("/api/v1/pull/:id", s.mutating(s.PullRepo))
 
 	// Freeze commands
-	router.POST("/api/v1/freeze/:id", s.FreezeRepo)
-	router.POST("/api/v1/unfreeze/:id", s.UnfreezeRepo)
+	router.POST("/api/v1/freeze/:id", s.mutating(s.FreezeRepo))
+	router.POST("/api/v1/unfreeze/:id", s.mutating(s.UnfreezeRepo))
+
+	// Installability gate on indexing
+	router.POST("/api/v1/gate/:id", s.mutating(s.EnableInstallGate))
+	router.POST("/api/v1/ungate/:id", s.mutating(s.DisableInstallGate))
 
 	// Removal
-	router.POST("/api/v1/remove/source/:id", s.RemoveSource)
-	router.POST("/api/v1/trim/packages/:id", s.TrimPackages)
-	router.GET("/api/v1/trim/obsoletes/:id", s.TrimObsolete)
+	router.POST("/api/v1/remove/source/:id", s.mutating(s.RemoveSource))
+	router.POST("/api/v1/trim/packages/:id", s.mutating(s.TrimPackages))
+	router.GET("/api/v1/trim/obsoletes/:id", s.mutating(s.TrimObsolete))
+
+	// Dry-run planning, these never modify anything
+	router.POST("/api/v1/plan/pull/:id", s.PlanPullRepo)
+	router.POST("/api/v1/plan/remove/source/:id", s.PlanRemoveSource)
+	router.POST("/api/v1/plan/copy/source/:id", s.PlanCopySource)
+	router.POST("/api/v1/plan/trim/packages/:id", s.PlanTrimPackages)
+	router.GET("/api/v1/plan/trim/obsoletes/:id", s.PlanTrimObsolete)
+
+	// Snapshots
+	router.GET("/api/v1/create/snapshot/:id/:name", s.mutating(s.CreateSnapshot))
+	router.GET("/api/v1/remove/snapshot/:id/:name", s.mutating(s.DeleteSnapshot))
+	router.GET("/api/v1/restore/snapshot/:id/:name", s.mutating(s.RestoreSnapshot))
 
 	// Reset jobs are special and go straight to the store
 	// We can't queue them as a job because we'd be in catch 22..
-	router.GET("/api/v1/reset/completed", s.ResetCompleted)
-	router.GET("/api/v1/reset/failed", s.ResetFailed)
+	router.GET("/api/v1/reset/completed", s.mutating(s.ResetCompleted))
+	router.GET("/api/v1/reset/failed", s.mutating(s.ResetFailed))
+
+	// Individual job control
+	router.POST("/api/v1/cancel/job/:id", s.CancelJob)
+	router.POST("/api/v1/requeue/job/:id", s.mutating(s.RequeueJob))
+
+	// Queue control is always allowed, so that maintenance can be left
+	router.POST("/api/v1/pause", s.Pause)
+	router.POST("/api/v1/resume", s.Resume)
+	router.POST("/api/v1/maintenance", s.SetMaintenance)
+
+	// Integrity checking
+	router.GET("/api/v1/check/integrity", s.mutating(s.CheckIntegrity))
+	router.GET("/api/v1/report/integrity", s.GetIntegrityReport)
+	router.GET("/api/v1/repair/integrity", s.mutating(s.RepairIntegrity))
+
+	// Installability checking
+	router.GET("/api/v1/check/installable/:id", s.mutating(s.CheckInstallable))
+	router.GET("/api/v1/report/installable/:id", s.GetInstallReport)
+
+	// Comparison
+	router.GET("/api/v1/diff/:source/:target", s.DiffRepos)
+	router.GET("/api/v1/changelog/:source/:target", s.GetChangelog)
+
+	// Queries
+	router.POST("/api/v1/search", s.Search)
+	router.GET("/api/v1/graph/:id", s.GetDependencyGraph)
+	router.GET("/api/v1/list/rdeps/:id/:name", s.GetReverseDeps)
 
 	// List commands
 	router.GET("/api/v1/list/repos", s.GetRepos)
 	router.GET("/api/v1/list/pool", s.GetPoolItems)
+	router.GET("/api/v1/list/snapshots/:id", s.GetSnapshots)
 	return s, nil
 }
 
@@ -181,8 +230,20 @@ func (s *Server) Bind() error {
 		return e
 	}
 	s.store = st
+	if e = s.store.SetRetention(jobs.Retention{MaxAge: historyAge, MaxCount: historyCount}); e != nil {
+		return e
+	}
+
+	s.jproc = jobs.NewProcessor(s.manager, s.store, backgroundJobCount, repoJobCount)
 
-	s.jproc = jobs.NewProcessor(s.manager, s.store, backgroundJobCount)
+	// Recurring maintenance jobs
+	schedule, e := jobs.LoadSchedule(schedulePath)
+	if e != nil {
+		return e
+	}
+	if e = s.jproc.Schedule(schedule); e != nil {
+		return e
+	}
 
 	// Set up watching the manager's incoming directory
 	if err := s.InitWatcher(); err != nil {
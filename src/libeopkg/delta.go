@@ -19,6 +19,7 @@ package libeopkg
 import (
 	"archive/tar"
 	"archive/zip"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -130,7 +131,7 @@ func (d *DeltaProducer) filesToMap(p *Package) (ret map[string][]*File) {
 
 // The bulk of the work will happen here as we attempt to produce the main
 // install.tar.xz tarball which will be used in the final .eopkg file
-func (d *DeltaProducer) produceInstallBall() (string, error) {
+func (d *DeltaProducer) produceInstallBall(ctx context.Context) (string, error) {
 	var (
 		tw       *tar.Writer
 		err      error
@@ -180,14 +181,14 @@ func (d *DeltaProducer) produceInstallBall() (string, error) {
 	}
 	tw = tar.NewWriter(outF)
 
-	if err = d.copyInstallPartial(tw); err != nil {
+	if err = d.copyInstallPartial(ctx, tw); err != nil {
 		return filename, err
 	}
 
 	tw.Flush()
 	tw.Close()
 
-	if err = XzFile(installTar, false); err != nil {
+	if err = XzFile(ctx, installTar, false); err != nil {
 		return "", err
 	}
 	return fmt.Sprintf("%s.xz", installTar), nil
@@ -196,10 +197,10 @@ func (d *DeltaProducer) produceInstallBall() (string, error) {
 // copyInstallPartial will iterate over the contents of the existing install.tar.xz
 // for the new package, and only include the files that aren't hash-matched in the
 // old files.xml
-func (d *DeltaProducer) copyInstallPartial(tw *tar.Writer) error {
+func (d *DeltaProducer) copyInstallPartial(ctx context.Context, tw *tar.Writer) error {
 
 	// Ensure we have tarball ready for use
-	if err := d.new.ExtractTarball(d.baseDir); err != nil {
+	if err := d.new.ExtractTarball(ctx, d.baseDir); err != nil {
 		return err
 	}
 
@@ -311,8 +312,9 @@ func (d *DeltaProducer) pushInstallBall(zipFile *zip.Writer, xzFileName string)
 // Commit will attempt to produce a delta between the 2 eopkg files
 // This will be performed in temporary storage so must then be copied into
 // the final resting location, and unlinked, before it can be used.
-func (d *DeltaProducer) Commit() (string, error) {
-	xzFileName, err := d.produceInstallBall()
+// Compression is abandoned if the context is done first.
+func (d *DeltaProducer) Commit(ctx context.Context) (string, error) {
+	xzFileName, err := d.produceInstallBall(ctx)
 	var zipFileName string
 	defer func() {
 		if xzFileName != "" {
@@ -17,6 +17,7 @@
 package libeopkg
 
 import (
+	"context"
 	"os"
 	"testing"
 )
@@ -33,7 +34,7 @@ func TestBasicDelta(t *testing.T) {
 		t.Fatalf("Failed to create delta producer for existing pkgs: %v", err)
 	}
 	defer producer.Close()
-	path, err := producer.Commit()
+	path, err := producer.Commit(context.Background())
 	if err != nil {
 		t.Fatalf("Failed to produce delta packages: %v", err)
 	}
@@ -18,6 +18,7 @@ package libeopkg
 
 import (
 	"archive/zip"
+	"context"
 	"encoding/xml"
 	"io"
 	"os"
@@ -165,7 +166,7 @@ func (p *Package) ReadAll() error {
 
 // ExtractTarball will fully extract install.tar.xz to the destination
 // direction + install.tar suffix
-func (p *Package) ExtractTarball(directory string) error {
+func (p *Package) ExtractTarball(ctx context.Context, directory string) error {
 	xzName := filepath.Join(directory, "install.tar.xz")
 
 	tarball := p.FindFile("install.tar.xz")
@@ -186,5 +187,5 @@ func (p *Package) ExtractTarball(directory string) error {
 	if _, err = io.Copy(outF, fi); err != nil {
 		return err
 	}
-	return UnxzFile(xzName, false)
+	return UnxzFile(ctx, xzName, false)
 }
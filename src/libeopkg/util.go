@@ -17,6 +17,7 @@
 package libeopkg
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -53,7 +54,8 @@ func IsDeltaPossible(oldPackage, newPackage *MetaPackage) bool {
 // file. This will be performed in place and leave a ".xz" suffixed file in
 // place
 // Keep original determines whether we'll keep the original file
-func XzFile(inputPath string, keepOriginal bool) error {
+// The xz process is killed if the context is done before it completes
+func XzFile(ctx context.Context, inputPath string, keepOriginal bool) error {
 	cmd := []string{
 		"xz",
 		"-6",
@@ -63,14 +65,14 @@ func XzFile(inputPath string, keepOriginal bool) error {
 	if keepOriginal {
 		cmd = append(cmd, "-k")
 	}
-	c := exec.Command(cmd[0], cmd[1:]...)
+	c := exec.CommandContext(ctx, cmd[0], cmd[1:]...)
 	c.Stderr = os.Stderr
 	return c.Run()
 }
 
 // UnxzFile will decompress the input XZ file and leave a new file in place
 // without the .xz suffix
-func UnxzFile(inputPath string, keepOriginal bool) error {
+func UnxzFile(ctx context.Context, inputPath string, keepOriginal bool) error {
 	cmd := []string{
 		"unxz",
 		"-T", "8",
@@ -79,7 +81,7 @@ func UnxzFile(inputPath string, keepOriginal bool) error {
 	if keepOriginal {
 		cmd = append(cmd, "-k")
 	}
-	c := exec.Command(cmd[0], cmd[1:]...)
+	c := exec.CommandContext(ctx, cmd[0], cmd[1:]...)
 	c.Stderr = os.Stderr
 	return c.Run()
 }
@@ -88,7 +90,8 @@ func UnxzFile(inputPath string, keepOriginal bool) error {
 // file. This will be performed in place and leave a ".zst" suffixed file in
 // place
 // Keep original determines whether we'll keep the original file
-func ZstdFile(inputPath string, keepOriginal bool) error {
+// The zstd process is killed if the context is done before it completes
+func ZstdFile(ctx context.Context, inputPath string, keepOriginal bool) error {
 	cmd := []string{
 		"zstd",
 		"-3",
@@ -98,14 +101,14 @@ func ZstdFile(inputPath string, keepOriginal bool) error {
 	if keepOriginal {
 		cmd = append(cmd, "-k")
 	}
-	c := exec.Command(cmd[0], cmd[1:]...)
+	c := exec.CommandContext(ctx, cmd[0], cmd[1:]...)
 	c.Stderr = os.Stderr
 	return c.Run()
 }
 
 // UnzstdFile will decompress the input zstd file and leave a new file in place
 // without the .zst suffix
-func UnzstdFile(inputPath string, keepOriginal bool) error {
+func UnzstdFile(ctx context.Context, inputPath string, keepOriginal bool) error {
 	cmd := []string{
 		"unzstd",
 		"-T8",
@@ -114,7 +117,7 @@ func UnzstdFile(inputPath string, keepOriginal bool) error {
 	if keepOriginal {
 		cmd = append(cmd, "-k")
 	}
-	c := exec.Command(cmd[0], cmd[1:]...)
+	c := exec.CommandContext(ctx, cmd[0], cmd[1:]...)
 	c.Stderr = os.Stderr
 	return c.Run()
 }
@@ -0,0 +1,114 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package libeopkg
+
+import (
+	"strconv"
+)
+
+// splitVersion will break a version string into runs of digits and runs of
+// letters, dropping separators such as '.', '-', '_' and '+'.
+func splitVersion(version string) []string {
+	var parts []string
+	start := -1
+	digits := false
+
+	isDigit := func(c byte) bool { return c >= '0' && c <= '9' }
+	isAlpha := func(c byte) bool { return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+
+	for i := 0; i < len(version); i++ {
+		c := version[i]
+		if !isDigit(c) && !isAlpha(c) {
+			if start >= 0 {
+				parts = append(parts, version[start:i])
+				start = -1
+			}
+			continue
+		}
+		if start >= 0 && isDigit(c) != digits {
+			parts = append(parts, version[start:i])
+			start = -1
+		}
+		if start < 0 {
+			start = i
+			digits = isDigit(c)
+		}
+	}
+	if start >= 0 {
+		parts = append(parts, version[start:])
+	}
+	return parts
+}
+
+// CompareVersions will compare two package version strings, returning -1 if a
+// is older than b, 1 if a is newer than b, and 0 if they are equivalent.
+//
+// Numeric components are compared numerically and anything else lexically,
+// so that "1.10" is newer than "1.9". When one version is a prefix of the
+// other, the longer version is considered newer, i.e. "1.0.1" > "1.0", unless
+// it continues with letters which mark a pre-release, i.e. "1.0rc1" < "1.0".
+func CompareVersions(a, b string) int {
+	partsA := splitVersion(a)
+	partsB := splitVersion(b)
+
+	for i := 0; i < len(partsA) && i < len(partsB); i++ {
+		pa, pb := partsA[i], partsB[i]
+		na, errA := strconv.ParseUint(pa, 10, 64)
+		nb, errB := strconv.ParseUint(pb, 10, 64)
+
+		switch {
+		case errA == nil && errB == nil:
+			if na < nb {
+				return -1
+			} else if na > nb {
+				return 1
+			}
+		case errA == nil:
+			// Numbers are newer than letters, i.e. 1.0 > 1.rc
+			return 1
+		case errB == nil:
+			return -1
+		default:
+			if pa < pb {
+				return -1
+			} else if pa > pb {
+				return 1
+			}
+		}
+	}
+
+	switch {
+	case len(partsA) < len(partsB):
+		if isPreRelease(partsB[len(partsA)]) {
+			return 1
+		}
+		return -1
+	case len(partsA) > len(partsB):
+		if isPreRelease(partsA[len(partsB)]) {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+// isPreRelease reports whether the version part following a shared prefix
+// marks a pre-release, such as "rc" or "beta"
+func isPreRelease(part string) bool {
+	_, err := strconv.ParseUint(part, 10, 64)
+	return err != nil
+}
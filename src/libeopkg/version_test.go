@@ -0,0 +1,49 @@
+//
+// Copyright © 2026 Solus Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package libeopkg
+
+import (
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0", "1.0", 0},
+		{"2.8.5", "2.7.1", 1},
+		{"1.9", "1.10", -1},
+		{"1.0", "1.0.1", -1},
+		{"1.1.1k", "1.1.1j", 1},
+		{"1.0", "1.rc1", 1},
+		{"1.0rc1", "1.0", -1},
+		{"1.0rc1", "1.0.1", -1},
+		{"1.0rc1", "1.0rc2", -1},
+		{"2017.06", "2017_06", 0},
+		{"0.9.8zh", "1.0.0", -1},
+	}
+
+	for _, test := range tests {
+		if got := CompareVersions(test.a, test.b); got != test.want {
+			t.Fatalf("CompareVersions(%q, %q) = %d, expected %d", test.a, test.b, got, test.want)
+		}
+		if got := CompareVersions(test.b, test.a); got != -test.want {
+			t.Fatalf("CompareVersions(%q, %q) = %d, expected %d", test.b, test.a, got, -test.want)
+		}
+	}
+}
@@ -33,7 +33,8 @@ var Version string
 
 // A Client is used to communicate with the system ferryd
 type Client struct {
-	client *http.Client
+	client   *http.Client
+	override bool // Make changes even while the daemon is in maintenance
 }
 
 // NewClient will return a new Client for the local unix socket, suitable
@@ -61,7 +62,16 @@ func (c *Client) Close() {
 	trans.CloseIdleConnections()
 }
 
+// SetOverride will allow the client to make changes while the daemon is in
+// maintenance
+func (c *Client) SetOverride(override bool) {
+	c.override = override
+}
+
 func (c *Client) formURI(part string) string {
+	if c.override {
+		return fmt.Sprintf("http://localhost.localdomain:0/%s?%s=1", part, OverrideMaintenance)
+	}
 	return fmt.Sprintf("http://localhost.localdomain:0/%s", part)
 }
 
@@ -93,6 +103,24 @@ func (c *Client) GetPoolItems() ([]PoolItem, error) {
 	return lq.Item, nil
 }
 
+// getJobResponse will request that a job is scheduled, returning its ID
+func (c *Client) getJobResponse(url string) (uint64, error) {
+	var jr JobResponse
+	if err := c.getBasicResponse(url, &jr); err != nil {
+		return 0, err
+	}
+	return jr.ID, nil
+}
+
+// postJobResponse will request that a job is scheduled, returning its ID
+func (c *Client) postJobResponse(url string, inT interface{}) (uint64, error) {
+	var jr JobResponse
+	if err := c.postBasicResponse(url, inT, &jr); err != nil {
+		return 0, err
+	}
+	return jr.ID, nil
+}
+
 // A helper to wrap the trivial functionality, chaining off
 // the appropriate errors, etc.
 func (c *Client) getBasicResponse(url string, outT interface{}) error {
@@ -106,7 +134,7 @@ func (c *Client) getBasicResponse(url string, outT interface{}) error {
 			return e
 		}
 	}
-	fc := outT.(*Response)
+	fc := outT.(responder).response()
 	if !fc.Error {
 		return nil
 	}
@@ -134,7 +162,7 @@ func (c *Client) postBasicResponse(url string, inT interface{}, outT interface{}
 		}
 	}
 
-	fc := outT.(*Response)
+	fc := outT.(responder).response()
 	if !fc.Error {
 		return nil
 	}
@@ -142,87 +170,180 @@ func (c *Client) postBasicResponse(url string, inT interface{}, outT interface{}
 }
 
 // CreateRepo will attempt to create a repository in the daemon
-func (c *Client) CreateRepo(id string) error {
-	uri := c.formURI("/api/v1/create/repo/" + id)
-	return c.getBasicResponse(uri, &Response{})
+func (c *Client) CreateRepo(id string) (uint64, error) {
+	uri := c.formURI("api/v1/create/repo/" + id)
+	return c.getJobResponse(uri)
 }
 
 // DeleteRepo will attempt to delete a remote repository
-func (c *Client) DeleteRepo(id string) error {
-	uri := c.formURI("/api/v1/remove/repo/" + id)
-	return c.getBasicResponse(uri, &Response{})
+func (c *Client) DeleteRepo(id string) (uint64, error) {
+	uri := c.formURI("api/v1/remove/repo/" + id)
+	return c.getJobResponse(uri)
 }
 
 // DeltaRepo will attempt to reproduce deltas in the given repo
-func (c *Client) DeltaRepo(id string) error {
-	uri := c.formURI("/api/v1/delta/repo/" + id)
-	return c.getBasicResponse(uri, &Response{})
+func (c *Client) DeltaRepo(id string) (uint64, error) {
+	uri := c.formURI("api/v1/delta/repo/" + id)
+	return c.getJobResponse(uri)
 }
 
 // IndexRepo will attempt to index a repository in the daemon
-func (c *Client) IndexRepo(id string) error {
-	uri := c.formURI("/api/v1/index/repo/" + id)
-	return c.getBasicResponse(uri, &Response{})
+func (c *Client) IndexRepo(id string) (uint64, error) {
+	uri := c.formURI("api/v1/index/repo/" + id)
+	return c.getJobResponse(uri)
 }
 
 // ImportPackages will ask ferryd to import the named packages with absolute
 // paths
-func (c *Client) ImportPackages(repoID string, pkgs []string) error {
+func (c *Client) ImportPackages(repoID string, pkgs []string) (uint64, error) {
 	iq := ImportRequest{
 		Path: pkgs,
 	}
-	return c.postBasicResponse(c.formURI("api/v1/import/"+repoID), &iq, &Response{})
+	return c.postJobResponse(c.formURI("api/v1/import/"+repoID), &iq)
+}
+
+// ReplacePackages will ask ferryd to replace existing pool entries with the
+// named packages, using absolute paths
+func (c *Client) ReplacePackages(pkgs []string) (uint64, error) {
+	rq := ReplaceRequest{
+		Path: pkgs,
+	}
+	return c.postJobResponse(c.formURI("api/v1/replace/package"), &rq)
 }
 
 // CloneRepo will ask the backend to clone an existing repository into a new repository
-func (c *Client) CloneRepo(repoID, newClone string, copyAll bool) error {
+func (c *Client) CloneRepo(repoID, newClone string, copyAll bool) (uint64, error) {
 	cq := CloneRepoRequest{
 		CloneName: newClone,
 		CopyAll:   copyAll,
 	}
-	return c.postBasicResponse(c.formURI("api/v1/clone/"+repoID), &cq, &Response{})
+	return c.postJobResponse(c.formURI("api/v1/clone/"+repoID), &cq)
 }
 
 // PullRepo will ask the backend to pull from target into repoID
-func (c *Client) PullRepo(sourceID, targetID string) error {
+func (c *Client) PullRepo(sourceID, targetID string) (uint64, error) {
 	pq := PullRepoRequest{
 		Source: sourceID,
 	}
-	return c.postBasicResponse(c.formURI("api/v1/pull/"+targetID), &pq, &Response{})
+	return c.postJobResponse(c.formURI("api/v1/pull/"+targetID), &pq)
+}
+
+// PullRepoWith will ask the backend to pull only the packages matching the
+// filter, resolving conflicts with the given policy. The changes made are
+// reported in the result of the job.
+func (c *Client) PullRepoWith(sourceID, targetID string, filter *PullFilter, conflict string) (uint64, error) {
+	pq := PullRepoRequest{
+		Source:   sourceID,
+		Filter:   filter,
+		Conflict: conflict,
+	}
+	return c.postJobResponse(c.formURI("api/v1/pull/"+targetID), &pq)
 }
 
 // RemoveSource will ask the backend to remove packages by source name
-func (c *Client) RemoveSource(repoID, sourceID string, relno int) error {
+func (c *Client) RemoveSource(repoID, sourceID string, relno int) (uint64, error) {
 	sq := RemoveSourceRequest{
 		Source:  sourceID,
 		Release: relno,
 	}
-	return c.postBasicResponse(c.formURI("api/v1/remove/source/"+repoID), &sq, &Response{})
+	return c.postJobResponse(c.formURI("api/v1/remove/source/"+repoID), &sq)
 }
 
 // CopySource will ask the backend to copy packages by source name
-func (c *Client) CopySource(fromID, targetID, sourceID string, relno int, skipIndex bool) error {
+func (c *Client) CopySource(fromID, targetID, sourceID string, relno int, skipIndex bool) (uint64, error) {
 	sq := CopySourceRequest{
 		Source:    sourceID,
 		Target:    targetID,
 		Release:   relno,
 		SkipIndex: skipIndex,
 	}
-	return c.postBasicResponse(c.formURI("api/v1/copy/source/"+fromID), &sq, &Response{})
+	return c.postJobResponse(c.formURI("api/v1/copy/source/"+fromID), &sq)
 }
 
 // TrimPackages will request that packages in the repo are trimmed to maxKeep
-func (c *Client) TrimPackages(repoID string, maxKeep int) error {
+func (c *Client) TrimPackages(repoID string, maxKeep int) (uint64, error) {
 	tq := TrimPackagesRequest{
 		MaxKeep: maxKeep,
 	}
-	return c.postBasicResponse(c.formURI("api/v1/trim/packages/"+repoID), &tq, &Response{})
+	return c.postJobResponse(c.formURI("api/v1/trim/packages/"+repoID), &tq)
 }
 
 // TrimObsolete will request that all packages marked obsolete are removed
-func (c *Client) TrimObsolete(repoID string) error {
-	uri := c.formURI("/api/v1/trim/obsoletes/" + repoID)
-	return c.getBasicResponse(uri, &Response{})
+func (c *Client) TrimObsolete(repoID string) (uint64, error) {
+	uri := c.formURI("api/v1/trim/obsoletes/" + repoID)
+	return c.getJobResponse(uri)
+}
+
+// decodePlan will read a ChangePlanRequest from a dry-run response
+func decodePlan(resp *http.Response) (*ChangePlanRequest, error) {
+	defer resp.Body.Close()
+	var pq ChangePlanRequest
+	if err := json.NewDecoder(resp.Body).Decode(&pq); err != nil {
+		return nil, err
+	}
+	if pq.Error {
+		return nil, errors.New(pq.ErrorString)
+	}
+	return &pq, nil
+}
+
+// postPlan will send a dry-run request and return the resulting plan
+func (c *Client) postPlan(url string, inT interface{}) (*ChangePlanRequest, error) {
+	b := &bytes.Buffer{}
+	if err := json.NewEncoder(b).Encode(inT); err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Post(url, "application/json; charset=utf-8", b)
+	if err != nil {
+		return nil, err
+	}
+	return decodePlan(resp)
+}
+
+// PlanPullRepo will return the changes a pull from sourceID into targetID would make
+func (c *Client) PlanPullRepo(sourceID, targetID string, filter *PullFilter, conflict string) (*ChangePlanRequest, error) {
+	pq := PullRepoRequest{
+		Source:   sourceID,
+		Filter:   filter,
+		Conflict: conflict,
+	}
+	return c.postPlan(c.formURI("api/v1/plan/pull/"+targetID), &pq)
+}
+
+// PlanRemoveSource will return the changes a source removal would make
+func (c *Client) PlanRemoveSource(repoID, sourceID string, relno int) (*ChangePlanRequest, error) {
+	sq := RemoveSourceRequest{
+		Source:  sourceID,
+		Release: relno,
+	}
+	return c.postPlan(c.formURI("api/v1/plan/remove/source/"+repoID), &sq)
+}
+
+// PlanCopySource will return the changes a source copy would make
+func (c *Client) PlanCopySource(fromID, targetID, sourceID string, relno int) (*ChangePlanRequest, error) {
+	sq := CopySourceRequest{
+		Source:  sourceID,
+		Target:  targetID,
+		Release: relno,
+	}
+	return c.postPlan(c.formURI("api/v1/plan/copy/source/"+fromID), &sq)
+}
+
+// PlanTrimPackages will return the changes a package trim would make
+func (c *Client) PlanTrimPackages(repoID string, maxKeep int) (*ChangePlanRequest, error) {
+	tq := TrimPackagesRequest{
+		MaxKeep: maxKeep,
+	}
+	return c.postPlan(c.formURI("api/v1/plan/trim/packages/"+repoID), &tq)
+}
+
+// PlanTrimObsolete will return the changes an obsoletes trim would make
+func (c *Client) PlanTrimObsolete(repoID string) (*ChangePlanRequest, error) {
+	resp, err := c.client.Get(c.formURI("api/v1/plan/trim/obsoletes/" + repoID))
+	if err != nil {
+		return nil, err
+	}
+	return decodePlan(resp)
 }
 
 // GetStatus will return status information for the running daemon process
@@ -239,24 +360,312 @@ func (c *Client) GetStatus() (*StatusRequest, error) {
 	return &sq, nil
 }
 
+// GetJob will return the current state of a job in the daemon
+func (c *Client) GetJob(id uint64) (*Job, error) {
+	var jq JobRequest
+	resp, err := c.client.Get(c.formURI(fmt.Sprintf("api/v1/job/%d", id)))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err = json.NewDecoder(resp.Body).Decode(&jq); err != nil {
+		return nil, err
+	}
+	if jq.Error {
+		return nil, errors.New(jq.ErrorString)
+	}
+	return jq.Job, nil
+}
+
+// GetJobLog will return the log messages emitted by the given job so far
+func (c *Client) GetJobLog(id uint64) ([]JobLogEntry, error) {
+	var lq JobLogRequest
+	resp, err := c.client.Get(c.formURI(fmt.Sprintf("api/v1/job/%d/log", id)))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err = json.NewDecoder(resp.Body).Decode(&lq); err != nil {
+		return nil, err
+	}
+	if lq.Error {
+		return nil, errors.New(lq.ErrorString)
+	}
+	return lq.Log, nil
+}
+
+// CancelJob asks the daemon to remove a job from the queue before it starts
+func (c *Client) CancelJob(id uint64) error {
+	return c.postBasicResponse(c.formURI(fmt.Sprintf("api/v1/cancel/job/%d", id)), nil, &Response{})
+}
+
+// RequeueJob asks the daemon to push a failed or cancelled job again with the
+// same parameters, returning the ID of the new job
+func (c *Client) RequeueJob(id uint64) (uint64, error) {
+	return c.postJobResponse(c.formURI(fmt.Sprintf("api/v1/requeue/job/%d", id)), nil)
+}
+
+// WaitJob will poll the daemon until the job has finished, returning its
+// final state. Note that a failed job is not an error here, callers should
+// check the returned job.
+func (c *Client) WaitJob(id uint64, interval time.Duration) (*Job, error) {
+	for {
+		job, err := c.GetJob(id)
+		if err != nil {
+			return nil, err
+		}
+		if job.Finished() {
+			return job, nil
+		}
+		time.Sleep(interval)
+	}
+}
+
+// Pause will stop the daemon starting jobs from the queue, or from both
+// queues when queue is empty. Running jobs are left to finish.
+func (c *Client) Pause(queue string) error {
+	return c.postBasicResponse(c.formURI("api/v1/pause"), &PauseRequest{Queue: queue}, &Response{})
+}
+
+// Resume will allow the daemon to start jobs from the queue again, or from
+// both queues when queue is empty
+func (c *Client) Resume(queue string) error {
+	return c.postBasicResponse(c.formURI("api/v1/resume"), &PauseRequest{Queue: queue}, &Response{})
+}
+
+// SetMaintenance will enter or leave maintenance. While in maintenance both
+// queues are paused and changes are refused unless overridden.
+func (c *Client) SetMaintenance(enabled bool, reason string) error {
+	return c.postBasicResponse(c.formURI("api/v1/maintenance"), &MaintenanceRequest{Enabled: enabled, Reason: reason}, &Response{})
+}
+
 // ResetFailed asks the daemon to reset failed jobs
 func (c *Client) ResetFailed() error {
-	uri := c.formURI("/api/v1/reset/failed")
+	uri := c.formURI("api/v1/reset/failed")
 	return c.getBasicResponse(uri, &Response{})
 }
 
 // ResetCompleted asks the daemon to reset completed jobs
 func (c *Client) ResetCompleted() error {
-	uri := c.formURI("/api/v1/reset/completed")
+	uri := c.formURI("api/v1/reset/completed")
 	return c.getBasicResponse(uri, &Response{})
 }
 
 // FreezeRepo asks the daemon to freeze a repository.
-func (c *Client) FreezeRepo(repoID string) error {
-	return c.postBasicResponse(c.formURI("api/v1/freeze/"+repoID), nil, &Response{})
+func (c *Client) FreezeRepo(repoID string) (uint64, error) {
+	return c.postJobResponse(c.formURI("api/v1/freeze/"+repoID), nil)
 }
 
 // UnfreezeRepo asks the daemon to unfreeze a repository.
-func (c *Client) UnfreezeRepo(repoID string) error {
-	return c.postBasicResponse(c.formURI("api/v1/unfreeze/"+repoID), nil, &Response{})
+func (c *Client) UnfreezeRepo(repoID string) (uint64, error) {
+	return c.postJobResponse(c.formURI("api/v1/unfreeze/"+repoID), nil)
+}
+
+// EnableInstallGate asks the daemon to block indexing of a repository while
+// it has uninstallable packages.
+func (c *Client) EnableInstallGate(repoID string) (uint64, error) {
+	return c.postJobResponse(c.formURI("api/v1/gate/"+repoID), nil)
+}
+
+// DisableInstallGate asks the daemon to stop gating the index of a repository.
+func (c *Client) DisableInstallGate(repoID string) (uint64, error) {
+	return c.postJobResponse(c.formURI("api/v1/ungate/"+repoID), nil)
+}
+
+// CheckIntegrity asks the daemon to verify the pool and all repositories
+func (c *Client) CheckIntegrity() (uint64, error) {
+	uri := c.formURI("api/v1/check/integrity")
+	return c.getJobResponse(uri)
+}
+
+// RepairIntegrity asks the daemon to repair the pool and all repositories
+func (c *Client) RepairIntegrity() (uint64, error) {
+	uri := c.formURI("api/v1/repair/integrity")
+	return c.getJobResponse(uri)
+}
+
+// GetIntegrityReport will return the report from the last integrity check
+func (c *Client) GetIntegrityReport() (*IntegrityReportRequest, error) {
+	var rq IntegrityReportRequest
+	resp, err := c.client.Get(c.formURI("api/v1/report/integrity"))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err = json.NewDecoder(resp.Body).Decode(&rq); err != nil {
+		return nil, err
+	}
+	if rq.Error {
+		return nil, errors.New(rq.ErrorString)
+	}
+	return &rq, nil
+}
+
+// CreateSnapshot asks the daemon to take a named snapshot of a repository
+func (c *Client) CreateSnapshot(repoID, name string) (uint64, error) {
+	uri := c.formURI("api/v1/create/snapshot/" + repoID + "/" + name)
+	return c.getJobResponse(uri)
+}
+
+// DeleteSnapshot asks the daemon to remove a repository snapshot
+func (c *Client) DeleteSnapshot(repoID, name string) (uint64, error) {
+	uri := c.formURI("api/v1/remove/snapshot/" + repoID + "/" + name)
+	return c.getJobResponse(uri)
+}
+
+// RestoreSnapshot asks the daemon to roll a repository back to a snapshot
+func (c *Client) RestoreSnapshot(repoID, name string) (uint64, error) {
+	uri := c.formURI("api/v1/restore/snapshot/" + repoID + "/" + name)
+	return c.getJobResponse(uri)
+}
+
+// GetSnapshots will grab a list of snapshots for the repository from the daemon
+func (c *Client) GetSnapshots(repoID string) ([]Snapshot, error) {
+	var sq SnapshotListingRequest
+	resp, err := c.client.Get(c.formURI("api/v1/list/snapshots/" + repoID))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err = json.NewDecoder(resp.Body).Decode(&sq); err != nil {
+		return nil, err
+	}
+	if sq.Error {
+		return nil, errors.New(sq.ErrorString)
+	}
+	return sq.Snapshots, nil
+}
+
+// DiffRepos will compare the published packages of the source and target repositories
+func (c *Client) DiffRepos(sourceID, targetID string) (*RepoDiffRequest, error) {
+	var dq RepoDiffRequest
+	resp, err := c.client.Get(c.formURI("api/v1/diff/" + sourceID + "/" + targetID))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err = json.NewDecoder(resp.Body).Decode(&dq); err != nil {
+		return nil, err
+	}
+	if dq.Error {
+		return nil, errors.New(dq.ErrorString)
+	}
+	return &dq, nil
+}
+
+// GetChangelog will collect the updates that are new in the source repository
+// when compared to the target repository
+func (c *Client) GetChangelog(sourceID, targetID string) (*ChangelogRequest, error) {
+	var cq ChangelogRequest
+	resp, err := c.client.Get(c.formURI("api/v1/changelog/" + sourceID + "/" + targetID))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err = json.NewDecoder(resp.Body).Decode(&cq); err != nil {
+		return nil, err
+	}
+	if cq.Error {
+		return nil, errors.New(cq.ErrorString)
+	}
+	return &cq, nil
+}
+
+// Search will find all packages matching the query
+func (c *Client) Search(query *SearchRequest) ([]SearchResult, error) {
+	b := &bytes.Buffer{}
+	if err := json.NewEncoder(b).Encode(query); err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Post(c.formURI("api/v1/search"), "application/json; charset=utf-8", b)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var sq SearchResultsRequest
+	if err = json.NewDecoder(resp.Body).Decode(&sq); err != nil {
+		return nil, err
+	}
+	if sq.Error {
+		return nil, errors.New(sq.ErrorString)
+	}
+	return sq.Results, nil
+}
+
+// History will find all finished jobs matching the query
+func (c *Client) History(query *HistoryRequest) ([]*Job, error) {
+	b := &bytes.Buffer{}
+	if err := json.NewEncoder(b).Encode(query); err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Post(c.formURI("api/v1/history"), "application/json; charset=utf-8", b)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var hq HistoryResultsRequest
+	if err = json.NewDecoder(resp.Body).Decode(&hq); err != nil {
+		return nil, err
+	}
+	if hq.Error {
+		return nil, errors.New(hq.ErrorString)
+	}
+	return hq.Jobs, nil
+}
+
+// GetDependencyGraph will return the runtime dependency graph of the repository
+func (c *Client) GetDependencyGraph(repoID string) (*DependencyGraphRequest, error) {
+	var gq DependencyGraphRequest
+	resp, err := c.client.Get(c.formURI("api/v1/graph/" + repoID))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err = json.NewDecoder(resp.Body).Decode(&gq); err != nil {
+		return nil, err
+	}
+	if gq.Error {
+		return nil, errors.New(gq.ErrorString)
+	}
+	return &gq, nil
+}
+
+// GetReverseDeps will return the packages in the repository depending on the named package
+func (c *Client) GetReverseDeps(repoID, name string) (*ReverseDepsRequest, error) {
+	var rq ReverseDepsRequest
+	resp, err := c.client.Get(c.formURI("api/v1/list/rdeps/" + repoID + "/" + name))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err = json.NewDecoder(resp.Body).Decode(&rq); err != nil {
+		return nil, err
+	}
+	if rq.Error {
+		return nil, errors.New(rq.ErrorString)
+	}
+	return &rq, nil
+}
+
+// CheckInstallable asks the daemon to find unresolved dependencies in a repository
+func (c *Client) CheckInstallable(repoID string) (uint64, error) {
+	uri := c.formURI("api/v1/check/installable/" + repoID)
+	return c.getJobResponse(uri)
+}
+
+// GetInstallReport will return the report from the last installability check of a repository
+func (c *Client) GetInstallReport(repoID string) (*InstallReportRequest, error) {
+	var rq InstallReportRequest
+	resp, err := c.client.Get(c.formURI("api/v1/report/installable/" + repoID))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err = json.NewDecoder(resp.Body).Decode(&rq); err != nil {
+		return nil, err
+	}
+	if rq.Error {
+		return nil, errors.New(rq.ErrorString)
+	}
+	return &rq, nil
 }
@@ -17,6 +17,8 @@
 package libferry
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 )
 
@@ -27,6 +29,16 @@ type Response struct {
 	ErrorString string // The associated error message
 }
 
+// responder is satisfied by every type embedding a Response, allowing the
+// client helpers to check for errors on any of them
+type responder interface {
+	response() *Response
+}
+
+func (r *Response) response() *Response {
+	return r
+}
+
 // An ImportRequest is given to ferryd to ask for the given packages to be
 // included into the repository
 type ImportRequest struct {
@@ -34,6 +46,13 @@ type ImportRequest struct {
 	Path []string `json:"path"`
 }
 
+// A ReplaceRequest is given to ferryd to ask for existing pool entries to be
+// replaced by the given packages in every repository
+type ReplaceRequest struct {
+	Response
+	Path []string `json:"path"`
+}
+
 // RepoListingRequest allows us to ask the remote what repositories it
 // currently knows about.
 type RepoListingRequest struct {
@@ -41,6 +60,20 @@ type RepoListingRequest struct {
 	Repository []string `json:"repos"`
 }
 
+// A Snapshot is a named, immutable copy of a repository's state
+type Snapshot struct {
+	Name     string    `json:"name"`
+	Created  time.Time `json:"created"`
+	Packages int       `json:"packages"`
+}
+
+// SnapshotListingRequest allows us to ask the remote which snapshots it has
+// for a repository
+type SnapshotListingRequest struct {
+	Response
+	Snapshots []Snapshot `json:"snapshots"`
+}
+
 // A PoolItem simply has an ID and a refcount, allowing us to examine our
 // local storage efficiency.
 type PoolItem struct {
@@ -61,10 +94,23 @@ type CloneRepoRequest struct {
 	CopyAll   bool   `json:"copyAll"` // Full clone
 }
 
+// PullFilter restricts a pull to a subset of packages. When any allow list
+// is set, packages must match at least one entry. Denied packages are never pulled.
+type PullFilter struct {
+	AllowSources    []string `json:"allowSources,omitempty"`
+	AllowNames      []string `json:"allowNames,omitempty"`
+	AllowComponents []string `json:"allowComponents,omitempty"`
+	DenySources     []string `json:"denySources,omitempty"`
+	DenyNames       []string `json:"denyNames,omitempty"`
+	DenyComponents  []string `json:"denyComponents,omitempty"`
+}
+
 // PullRepoRequest is given to ferryd to ask it to from from one repo into another
 type PullRepoRequest struct {
 	Response
-	Source string `json:"source"`
+	Source   string      `json:"source"`
+	Filter   *PullFilter `json:"filter,omitempty"`
+	Conflict string      `json:"conflict,omitempty"` // fail, skip or downgrade
 }
 
 // RemoveSourceRequest is used to ask ferryd to remove all packages matching the
@@ -91,6 +137,70 @@ type TrimPackagesRequest struct {
 	MaxKeep int `json:"maxPackages"`
 }
 
+// A PublishedChange records the published tip of a package name moving.
+// From is empty for new names, and To is empty for removed names.
+type PublishedChange struct {
+	Name string `json:"name"`
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// ChangePlanRequest is returned by the dry-run endpoints, detailing exactly
+// what an operation would do to the repository.
+type ChangePlanRequest struct {
+	Response
+	Repo      string            `json:"repo"`
+	Ref       []string          `json:"ref"`
+	Unref     []string          `json:"unref"`
+	Published []PublishedChange `json:"published"`
+	Deltas    []string          `json:"deltas"`
+	Skipped   []string          `json:"skipped"`
+}
+
+// A DiffPackage is the published package for a name in one repository
+type DiffPackage struct {
+	Name    string `json:"name"`
+	ID      string `json:"id"`
+	Version string `json:"version"`
+	Release int    `json:"relno"`
+}
+
+// A DiffChange is a package name published with a different ID in each repository
+type DiffChange struct {
+	Name   string      `json:"name"`
+	Source DiffPackage `json:"source"`
+	Target DiffPackage `json:"target"`
+}
+
+// RepoDiffRequest is returned when comparing two repositories
+type RepoDiffRequest struct {
+	Response
+	Source     string        `json:"source"`
+	Target     string        `json:"target"`
+	OnlySource []DiffPackage `json:"onlySource"`
+	OnlyTarget []DiffPackage `json:"onlyTarget"`
+	Changed    []DiffChange  `json:"changed"`
+}
+
+// An IntegrityIssue is a single problem found by the integrity checker
+type IntegrityIssue struct {
+	Kind   string `json:"kind"`
+	Repo   string `json:"repo,omitempty"` // Empty for pool issues
+	ID     string `json:"id"`
+	Detail string `json:"detail"`
+}
+
+// IntegrityReportRequest is used to retrieve the report from the most recent
+// integrity check of the pool and repositories
+type IntegrityReportRequest struct {
+	Response
+	Started     time.Time        `json:"started"`
+	Finished    time.Time        `json:"finished"`
+	PoolEntries int              `json:"poolEntries"`
+	RepoEntries int              `json:"repoEntries"`
+	Issues      []IntegrityIssue `json:"issues"`
+}
+
 // TimingInformation stores relevant timing stats on jobs so we can know what
 // kind of latency we're dealing with, etc.
 //
@@ -142,12 +252,95 @@ func (j JobSet) Swap(a, b int) {
 	j[a], j[b] = j[b], j[a]
 }
 
+// Job states reported by the daemon
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
 // Job is used to represent status items in the backend
 type Job struct {
+	ID          uint64            `json:"id"`
+	Type        string            `json:"type"`
+	State       string            `json:"state"`
+	Args        json.RawMessage   `json:"args,omitempty"`     // Typed arguments of the job
+	Repos       []string          `json:"repos,omitempty"`    // Repositories the job works on
+	Priority    string            `json:"priority,omitempty"` // Set while the job is queued or running
 	Description string            `json:"description"`
 	Timing      TimingInformation `json:"timing"`
-	Failed      bool              `json:"failed"` // Whether it failed or not
-	Error       string            `json:"error"`  // Only set if we have Failed == true
+	Failed      bool              `json:"failed"`             // Whether it failed or not
+	Error       string            `json:"error"`              // Set if Failed, or the last error of a retried job
+	Result      json.RawMessage   `json:"result,omitempty"`   // Set by jobs reporting an outcome
+	Attempt     int               `json:"attempt"`            // How many times the job was retried
+	NotBefore   time.Time         `json:"notBefore"`          // When a retried or debounced job may run
+	Progress    *JobProgress      `json:"progress,omitempty"` // Set while the job is running, if reported
+}
+
+// JobProgress is reported by long running jobs to show how far they've got
+type JobProgress struct {
+	Step  string `json:"step"`
+	Done  int    `json:"done"`
+	Total int    `json:"total"` // Zero if the step can't be measured
+}
+
+// String returns a short human readable form of the progress
+func (p *JobProgress) String() string {
+	if p.Total < 1 {
+		return p.Step
+	}
+	return fmt.Sprintf("%s (%d/%d)", p.Step, p.Done, p.Total)
+}
+
+// JobLogEntry is a single log message emitted while running a job
+type JobLogEntry struct {
+	Time    time.Time         `json:"time"`
+	Level   string            `json:"level"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+// Finished will report whether the job has run to completion, failure or
+// was cancelled
+func (j *Job) Finished() bool {
+	return j.State == JobCompleted || j.State == JobFailed || j.State == JobCancelled
+}
+
+// JobRequest is returned by the daemon when querying a single job
+type JobRequest struct {
+	Response
+	Job *Job `json:"job"`
+}
+
+// JobLogRequest is returned by the daemon when querying the log of a job
+type JobLogRequest struct {
+	Response
+	Log []JobLogEntry `json:"log"`
+}
+
+// HistoryRequest describes the finished jobs to find. Every non-empty field
+// must match.
+type HistoryRequest struct {
+	Type  string    `json:"type"`
+	Repo  string    `json:"repo"`
+	State string    `json:"state"`
+	Since time.Time `json:"since"` // Finished at or after
+	Until time.Time `json:"until"` // Finished before
+	Limit int       `json:"limit"` // Zero returns every match
+}
+
+// HistoryResultsRequest is returned when querying the job history, newest job first
+type HistoryResultsRequest struct {
+	Response
+	Jobs []*Job `json:"jobs"`
+}
+
+// JobResponse is returned by the daemon whenever a request schedules a job
+type JobResponse struct {
+	Response
+	ID uint64 `json:"id"`
 }
 
 // StatusRequest is used to grab information from the daemon, including its
@@ -162,9 +355,174 @@ type StatusRequest struct {
 	FailedJobs    JobSet `json:"failedJobs"`    // Known failed jobs
 	CurrentJobs   JobSet `json:"currentJobs"`   // Currently registered jobs
 	CompletedJobs JobSet `json:"completedJobs"` // Successfully completed jobs
+
+	ScheduledJobs []ScheduledJob `json:"scheduledJobs"` // Recurring jobs, soonest first
+
+	Paused PauseState `json:"paused"`
+}
+
+// Job queues that may be paused
+const (
+	QueueSequential = "sequential"
+	QueueAsync      = "async"
+)
+
+// OverrideMaintenance is the query parameter set by clients to make changes
+// while the daemon is in maintenance
+const OverrideMaintenance = "override-maintenance"
+
+// PauseState describes which queues have stopped starting new jobs, and
+// whether the daemon is in maintenance
+type PauseState struct {
+	Sequential  bool      `json:"sequential"`
+	Async       bool      `json:"async"`
+	Maintenance bool      `json:"maintenance"` // Changes are refused unless overridden
+	Reason      string    `json:"reason,omitempty"`
+	Since       time.Time `json:"since"` // When the queues were first paused
+
+	// The pause flags to restore when leaving maintenance, so that queues
+	// paused by an operator stay paused
+	RestoreSequential bool `json:"restoreSequential,omitempty"`
+	RestoreAsync      bool `json:"restoreAsync,omitempty"`
+}
+
+// Paused reports whether either queue is paused
+func (p *PauseState) Paused() bool {
+	return p.Sequential || p.Async
+}
+
+// PauseRequest is sent to pause or resume a queue, or both queues when no
+// queue is given
+type PauseRequest struct {
+	Queue string `json:"queue"`
+}
+
+// MaintenanceRequest is sent to enter or leave maintenance
+type MaintenanceRequest struct {
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason"`
+}
+
+// ScheduledJob is a recurring job pushed by the daemon on a schedule
+type ScheduledJob struct {
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	Description string    `json:"description"`
+	Schedule    string    `json:"schedule"`
+	LastRun     time.Time `json:"lastRun"` // Zero if it has never run
+	LastJob     uint64    `json:"lastJob"` // The job pushed by the last run
+	NextRun     time.Time `json:"nextRun"` // Zero if it will never run again
 }
 
 // Uptime will determine the uptime of the daemon
 func (s *StatusRequest) Uptime() time.Duration {
 	return time.Now().UTC().Sub(s.TimeStarted)
 }
+
+// A ChangelogUpdate is a single History update for a package
+type ChangelogUpdate struct {
+	Release  int    `json:"relno"`
+	Version  string `json:"version"`
+	Date     string `json:"date"`
+	Type     string `json:"type"`
+	Comment  string `json:"comment"`
+	Packager string `json:"packager"`
+}
+
+// A ChangelogEntry collects the new updates for a single source package
+type ChangelogEntry struct {
+	Source      string            `json:"source"`
+	Packages    []string          `json:"packages"`
+	FromVersion string            `json:"fromVersion"`
+	FromRelease int               `json:"fromRelno"`
+	ToVersion   string            `json:"toVersion"`
+	ToRelease   int               `json:"toRelno"`
+	Updates     []ChangelogUpdate `json:"updates"`
+}
+
+// ChangelogRequest is returned when collecting the changes between two repositories.
+// Markdown holds the same report pre-rendered by the daemon.
+type ChangelogRequest struct {
+	Response
+	Source   string           `json:"source"`
+	Target   string           `json:"target"`
+	Entries  []ChangelogEntry `json:"entries"`
+	Markdown string           `json:"markdown"`
+}
+
+// SearchRequest describes the packages to find. Every non-empty field must match.
+type SearchRequest struct {
+	Repos     []string `json:"repos"`
+	Name      string   `json:"name"`
+	Source    string   `json:"source"`
+	Release   int      `json:"relno"`
+	Packager  string   `json:"packager"`
+	License   string   `json:"license"`
+	Component string   `json:"component"`
+	PkgConfig string   `json:"pkgconfig"`
+	Published bool     `json:"published"`
+}
+
+// A SearchResult is a single package matching a search
+type SearchResult struct {
+	Repo      string `json:"repo"`
+	Name      string `json:"name"`
+	ID        string `json:"id"`
+	Version   string `json:"version"`
+	Release   int    `json:"relno"`
+	Published bool   `json:"published"`
+}
+
+// SearchResultsRequest is returned when searching for packages
+type SearchResultsRequest struct {
+	Response
+	Results []SearchResult `json:"results"`
+}
+
+// A DependencyNode is a published package and the names it depends on
+type DependencyNode struct {
+	Name    string   `json:"name"`
+	ID      string   `json:"id"`
+	Version string   `json:"version"`
+	Release int      `json:"relno"`
+	Depends []string `json:"depends"`
+}
+
+// DependencyGraphRequest is returned when exporting the dependency graph of a
+// repository. DOT holds the same graph rendered for Graphviz.
+type DependencyGraphRequest struct {
+	Response
+	Repo    string           `json:"repo"`
+	Nodes   []DependencyNode `json:"nodes"`
+	Missing []string         `json:"missing"`
+	DOT     string           `json:"dot"`
+}
+
+// ReverseDepsRequest is returned when asking what depends on a package
+type ReverseDepsRequest struct {
+	Response
+	Repo       string   `json:"repo"`
+	Name       string   `json:"name"`
+	Direct     []string `json:"direct"`
+	Transitive []string `json:"transitive"`
+}
+
+// An InstallIssue is a single dependency that cannot be satisfied
+type InstallIssue struct {
+	Kind       string `json:"kind"`
+	Package    string `json:"package"`
+	ID         string `json:"id"`
+	Dependency string `json:"dependency"`
+	Detail     string `json:"detail"`
+}
+
+// InstallReportRequest is used to retrieve the report from the most recent
+// installability check of a repository
+type InstallReportRequest struct {
+	Response
+	Repo     string         `json:"repo"`
+	Started  time.Time      `json:"started"`
+	Finished time.Time      `json:"finished"`
+	Packages int            `json:"packages"`
+	Issues   []InstallIssue `json:"issues"`
+}